@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gooji/internal/config"
+)
+
+// configUsage describes the configuration subcommand
+const configUsage = `usage:
+  gooji [-config file] [-<setting> value ...] config print [-json]
+
+Prints every setting's effective value and where it came from: the default,
+the configuration file, a GOOJI_* environment variable or a command-line flag.
+Secrets are redacted.`
+
+// runConfigCommand inspects the loaded configuration and returns the exit code
+func runConfigCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	if err := cfg.Print(os.Stdout, *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,387 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gooji/internal/logger"
+	"gooji/internal/middleware"
+)
+
+const (
+	// logFileName is the append-only audit file inside the audit directory
+	logFileName = "audit.jsonl"
+	// maxEntrySize bounds a single audit line when reading the log back
+	maxEntrySize = 4 * 1024 * 1024
+	// genesisHash is the previous hash of the first entry
+	genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// ErrChainBroken is returned when an entry does not match the hash chain
+var ErrChainBroken = errors.New("audit log hash chain is broken")
+
+// errMalformedEntry marks a line that does not decode as an entry
+var errMalformedEntry = errors.New("malformed audit entry")
+
+// Actor identifies who performed an audited operation
+type Actor struct {
+	Name       string `json:"name"`
+	Role       string `json:"role,omitempty"`
+	UserID     string `json:"user_id,omitempty"`
+	TokenID    string `json:"token_id,omitempty"`
+	DeviceID   string `json:"device_id,omitempty"`
+	DeviceName string `json:"device_name,omitempty"`
+}
+
+// Entry is one line of the audit log. Each entry's hash covers its content
+// and the previous entry's hash, so editing or removing a line breaks the chain.
+type Entry struct {
+	Seq       int64           `json:"seq"`
+	Time      time.Time       `json:"time"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Actor     Actor           `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash,omitempty"`
+}
+
+// Event describes an operation to record; Before and After are encoded as JSON
+type Event struct {
+	Action string
+	Target string
+	Actor  Actor
+	Before interface{}
+	After  interface{}
+}
+
+// Filter selects entries from the log; zero fields match everything
+type Filter struct {
+	Action string
+	Target string
+	Actor  string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// Verification reports the result of checking the hash chain
+type Verification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Log is an append-only, hash-chained audit log stored as JSON lines
+type Log struct {
+	path     string
+	logger   *logger.Logger
+	mu       sync.Mutex
+	lastSeq  int64
+	lastHash string
+	// size is the file's length after the last entry this process read or
+	// wrote; any other length means another process, such as a command, appended
+	size int64
+}
+
+// New opens the audit log in dir, creating it if needed, and checks its chain
+func New(dir string, log *logger.Logger) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	l := &Log{
+		path:     filepath.Join(dir, logFileName),
+		logger:   log,
+		lastHash: genesisHash,
+	}
+
+	verification, err := l.Verify(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if !verification.Valid {
+		// Keep appending so new operations are still recorded; the break stays visible to Verify
+		log.Error("Audit log chain is broken at entry %d: %s", verification.BrokenAt, verification.Error)
+	}
+	if info, err := os.Stat(l.path); err == nil {
+		l.size = info.Size()
+	}
+	return l, nil
+}
+
+// Record appends an event, stamped with the time and the request ID in ctx
+func (l *Log) Record(ctx context.Context, event *Event) error {
+	before, err := encodeState(event.Before)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit state: %w", err)
+	}
+	after, err := encodeState(event.After)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit state: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // Path built from configuration
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	// The server and the command-line tools share the file, so appends are
+	// serialized across processes and the chain resumes from whoever wrote last
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	if info.Size() != l.size {
+		if err := l.resync(context.WithoutCancel(ctx)); err != nil {
+			return err
+		}
+	}
+
+	entry := &Entry{
+		Seq:       l.lastSeq + 1,
+		Time:      time.Now().UTC(),
+		Action:    event.Action,
+		Target:    event.Target,
+		Actor:     event.Actor,
+		RequestID: middleware.RequestIDFromContext(ctx),
+		Before:    before,
+		After:     after,
+		PrevHash:  l.lastHash,
+	}
+	entry.Hash, err = entryHash(entry)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+
+	l.lastSeq = entry.Seq
+	l.lastHash = entry.Hash
+	l.size = info.Size() + int64(len(line))
+	return nil
+}
+
+// resync reads the last entry another process appended so the chain continues
+// from it; l.mu and the file lock must be held. A malformed tail is skipped,
+// leaving the break for Verify to report.
+func (l *Log) resync(ctx context.Context) error {
+	err := l.scan(ctx, func(entry *Entry) error {
+		l.lastSeq = entry.Seq
+		l.lastHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errMalformedEntry) {
+		return err
+	}
+	return nil
+}
+
+// Query returns matching entries, newest first
+func (l *Log) Query(ctx context.Context, filter *Filter) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	err := l.scan(ctx, func(entry *Entry) error {
+		if filter.matches(entry) {
+			entries = append(entries, *entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// Export writes the raw log to w, oldest first, so it can be verified elsewhere
+func (l *Log) Export(w io.Writer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path) //nolint:gosec // Path built from configuration
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to export audit log: %w", err)
+	}
+	return nil
+}
+
+// Verify walks the whole log and checks every entry against the hash chain.
+// It also resumes the chain from the last entry for later appends.
+func (l *Log) Verify(ctx context.Context) (*Verification, error) {
+	verification := &Verification{Valid: true}
+	prevHash := genesisHash
+	var prevSeq int64
+
+	err := l.scan(ctx, func(entry *Entry) error {
+		verification.Entries++
+		hash, err := entryHash(entry)
+		if err != nil {
+			return err
+		}
+		if verification.Valid {
+			switch {
+			case entry.Seq != prevSeq+1:
+				verification.Valid, verification.BrokenAt = false, entry.Seq
+				verification.Error = fmt.Sprintf("expected entry %d", prevSeq+1)
+			case entry.PrevHash != prevHash:
+				verification.Valid, verification.BrokenAt = false, entry.Seq
+				verification.Error = "previous hash does not match"
+			case entry.Hash != hash:
+				verification.Valid, verification.BrokenAt = false, entry.Seq
+				verification.Error = "entry hash does not match its content"
+			}
+		}
+		prevSeq = entry.Seq
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, errMalformedEntry) {
+			return nil, err
+		}
+		verification.Valid, verification.BrokenAt = false, prevSeq+1
+		verification.Error = err.Error()
+	}
+
+	l.mu.Lock()
+	if prevSeq > l.lastSeq {
+		l.lastSeq = prevSeq
+		l.lastHash = prevHash
+	}
+	l.mu.Unlock()
+	return verification, nil
+}
+
+// scan calls fn for each entry in file order
+func (l *Log) scan(ctx context.Context, fn func(*Entry) error) error {
+	file, err := os.Open(l.path) //nolint:gosec // Path built from configuration
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("%w: %w", errMalformedEntry, err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: %w", errMalformedEntry, err)
+		}
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
+
+// matches reports whether an entry passes the filter
+func (f *Filter) matches(entry *Entry) bool {
+	if f.Action != "" && entry.Action != f.Action && !strings.HasPrefix(entry.Action, f.Action+".") {
+		return false
+	}
+	if f.Target != "" && entry.Target != f.Target {
+		return false
+	}
+	if f.Actor != "" && entry.Actor.UserID != f.Actor && entry.Actor.TokenID != f.Actor &&
+		entry.Actor.DeviceID != f.Actor && !strings.EqualFold(entry.Actor.Name, f.Actor) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// entryHash returns the hex SHA-256 of an entry's JSON encoding without its hash
+func entryHash(entry *Entry) (string, error) {
+	unhashed := *entry
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// encodeState encodes a before or after snapshot; nil stays empty
+func encodeState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return data, nil
+}
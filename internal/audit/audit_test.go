@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	log, err := New(dir, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, target := range []string{"a.mp4", "b.mp4", "c.mp4"} {
+		event := &Event{Action: "video.delete", Target: target, Actor: Actor{Name: "Moderator"}, Before: map[string]string{"title": target}}
+		if err := log.Record(ctx, event); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	original, err := os.ReadFile(filepath.Join(dir, logFileName))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	lines := bytes.SplitAfter(original, []byte("\n"))
+	lines = lines[:len(lines)-1]
+	if len(lines) != 3 {
+		t.Fatalf("log has %d lines, want 3", len(lines))
+	}
+
+	// rehash re-signs an edited entry so only the chain, not its own hash, gives it away
+	rehash := func(t *testing.T, line []byte, edit func(*Entry)) []byte {
+		t.Helper()
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("decode entry: %v", err)
+		}
+		edit(&entry)
+		hash, err := entryHash(&entry)
+		if err != nil {
+			t.Fatalf("hash entry: %v", err)
+		}
+		entry.Hash = hash
+		data, err := json.Marshal(&entry)
+		if err != nil {
+			t.Fatalf("encode entry: %v", err)
+		}
+		return append(data, '\n')
+	}
+
+	tests := []struct {
+		name     string
+		lines    func(t *testing.T) [][]byte
+		valid    bool
+		brokenAt int64
+	}{
+		{
+			name:  "untouched",
+			lines: func(t *testing.T) [][]byte { return [][]byte{lines[0], lines[1], lines[2]} },
+			valid: true,
+		},
+		{
+			name: "edited target",
+			lines: func(t *testing.T) [][]byte {
+				return [][]byte{lines[0], bytes.Replace(lines[1], []byte("b.mp4"), []byte("x.mp4"), 1), lines[2]}
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "edited and rehashed",
+			lines: func(t *testing.T) [][]byte {
+				return [][]byte{lines[0], rehash(t, lines[1], func(e *Entry) { e.Actor.Name = "Someone else" }), lines[2]}
+			},
+			brokenAt: 3,
+		},
+		{
+			name:     "reordered",
+			lines:    func(t *testing.T) [][]byte { return [][]byte{lines[0], lines[2], lines[1]} },
+			brokenAt: 3,
+		},
+		{
+			name:     "removed",
+			lines:    func(t *testing.T) [][]byte { return [][]byte{lines[0], lines[2]} },
+			brokenAt: 3,
+		},
+		{
+			name: "removed and renumbered",
+			lines: func(t *testing.T) [][]byte {
+				return [][]byte{lines[0], rehash(t, lines[2], func(e *Entry) { e.Seq = 2 })}
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "wrong field type",
+			lines: func(t *testing.T) [][]byte {
+				return [][]byte{lines[0], bytes.Replace(lines[1], []byte(`"seq":2`), []byte(`"seq":"2"`), 1), lines[2]}
+			},
+			brokenAt: 2,
+		},
+		{
+			name:     "not an object",
+			lines:    func(t *testing.T) [][]byte { return [][]byte{lines[0], []byte("[1,2]\n"), lines[2]} },
+			brokenAt: 2,
+		},
+		{
+			name: "oversized line",
+			lines: func(t *testing.T) [][]byte {
+				return [][]byte{lines[0], lines[1], append(bytes.Repeat([]byte(" "), maxEntrySize), lines[2]...)}
+			},
+			brokenAt: 3,
+		},
+		{
+			name:     "truncated line",
+			lines:    func(t *testing.T) [][]byte { return [][]byte{lines[0], lines[1], lines[2][:len(lines[2])/2]} },
+			brokenAt: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), logFileName)
+			if err := os.WriteFile(tampered, bytes.Join(tt.lines(t), nil), 0o600); err != nil {
+				t.Fatalf("write log: %v", err)
+			}
+
+			verification, err := (&Log{path: tampered, lastHash: genesisHash}).Verify(ctx)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if verification.Valid != tt.valid || verification.BrokenAt != tt.brokenAt {
+				t.Errorf("Verify = valid %v broken at %d (%s), want valid %v broken at %d",
+					verification.Valid, verification.BrokenAt, verification.Error, tt.valid, tt.brokenAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gooji/internal/logger"
+)
+
+const (
+	// defaultQueryLimit applies when a query does not set a limit
+	defaultQueryLimit = 100
+	// maxQueryLimit bounds how many entries one query returns
+	maxQueryLimit = 1000
+)
+
+// Handler serves the audit log query, export and verification endpoints
+type Handler struct {
+	log    *Log
+	logger *logger.Logger
+}
+
+// NewHandler creates a new audit handler
+func NewHandler(auditLog *Log, log *logger.Logger) *Handler {
+	return &Handler{log: auditLog, logger: log}
+}
+
+// HandleAudit handles GET /api/audit?action=&target=&actor=&since=&until=&limit=
+func (h *Handler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.log.Query(r.Context(), filter)
+	if err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+	h.writeJSONResponse(w, entries)
+}
+
+// HandleExport handles GET /api/audit/export, downloading the whole log as JSON lines
+func (h *Handler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="gooji-audit-%s.jsonl"`, time.Now().UTC().Format("20060102T150405Z")))
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.log.Export(w); err != nil {
+		h.logger.Error("Failed to export audit log: %v", err)
+	}
+}
+
+// HandleVerify handles GET /api/audit/verify, checking the hash chain
+func (h *Handler) HandleVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	verification, err := h.log.Verify(r.Context())
+	if err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+	h.writeJSONResponse(w, verification)
+}
+
+// parseFilter reads a query filter from the URL
+func parseFilter(r *http.Request) (*Filter, error) {
+	query := r.URL.Query()
+	filter := &Filter{
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+		Actor:  query.Get("actor"),
+		Limit:  defaultQueryLimit,
+	}
+
+	var err error
+	if since := query.Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return nil, fmt.Errorf("invalid since time, use RFC 3339: %w", err)
+		}
+	}
+	if until := query.Get("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, fmt.Errorf("invalid until time, use RFC 3339: %w", err)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxQueryLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxQueryLimit)
+		}
+	}
+	return filter, nil
+}
+
+// handleInternalError handles internal server errors
+func (h *Handler) handleInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Internal error: %v (method: %s, path: %s, remote: %s)", err, r.Method, r.URL.Path, r.RemoteAddr)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// writeJSONResponse writes a JSON response
+func (h *Handler) writeJSONResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
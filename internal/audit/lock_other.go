@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd
+
+package audit
+
+import "os"
+
+// lockFile does nothing on this platform. Appends from the server and the
+// command-line tools are not serialized, so run the tools while it is stopped.
+func lockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build linux || darwin || freebsd
+
+package audit
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on file, released when it is closed
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX) //nolint:gosec // File descriptors fit in an int
+}
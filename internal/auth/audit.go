@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+
+	"gooji/internal/audit"
+	"gooji/internal/logger"
+)
+
+// Audit actions recorded by the service
+const (
+	AuditUserCreate    = "user.create"
+	AuditUserRole      = "user.role.update"
+	AuditUserCommunity = "user.community_roles.update"
+	AuditUserResearch  = "user.researcher.update"
+	AuditUserPassword  = "user.password.update"
+	AuditTokenCreate   = "token.create"
+	AuditTokenRevoke   = "token.revoke"
+	AuditPairingCreate = "device.pairing.create"
+	AuditDevicePair    = "device.pair"
+	AuditDeviceRevoke  = "device.revoke"
+)
+
+// commandLineName names the actor of changes made with the gooji user command
+const commandLineName = "Command line"
+
+// AuditRecorder records mutating operations in the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, event *audit.Event) error
+}
+
+// commandLineContextKey marks contexts of the account management command
+type commandLineContextKey struct{}
+
+// CommandLineContext returns a context for changes made from the command line,
+// so the audit log names the console rather than an anonymous caller
+func CommandLineContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, commandLineContextKey{}, true)
+}
+
+// recordAudit records an operation by the caller in ctx. The operation has
+// already happened, so failures are logged rather than returned.
+func (s *Service) recordAudit(ctx context.Context, action, target string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Record(ctx, &audit.Event{
+		Action: action,
+		Target: target,
+		Actor:  auditActor(ctx),
+		Before: before,
+		After:  after,
+	}); err != nil {
+		logger.FromContext(ctx, s.logger).Error("Failed to record audit entry %s for %s: %v", action, target, err)
+	}
+}
+
+// auditActor describes the credentials on ctx for the audit log
+func auditActor(ctx context.Context) audit.Actor {
+	var actor audit.Actor
+	if token := TokenFromContext(ctx); token != nil {
+		actor.TokenID = token.ID
+		actor.Name = token.Name
+		actor.Role = string(token.ServiceRole())
+	}
+	if user := UserFromContext(ctx); user != nil {
+		actor.Name = user.Name()
+		actor.Role = string(user.Role)
+		actor.UserID = user.ID
+	}
+	if device := DeviceFromContext(ctx); device != nil {
+		actor.DeviceID = device.ID
+		actor.DeviceName = device.Name
+		if actor.Name == "" {
+			actor.Name = device.Name
+		}
+	}
+	if actor.Name == "" {
+		if commandLine, _ := ctx.Value(commandLineContextKey{}).(bool); commandLine {
+			actor.Name = commandLineName
+		} else {
+			actor.Name = "Anonymous"
+		}
+	}
+	return actor
+}
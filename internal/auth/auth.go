@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors returned by the auth service
+var (
+	// ErrInvalidCredentials is returned when a username or password is wrong
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUserExists is returned when creating a user whose username is taken
+	ErrUserExists = errors.New("username already exists")
+	// ErrUserNotFound is returned when a user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrSessionNotFound is returned for unknown or expired sessions
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrInvalidInput is returned for malformed requests
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrForbidden is returned when the caller may not perform an operation
+	ErrForbidden = errors.New("forbidden")
+)
+
+// User is a local account
+type User struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	DisplayName  string `json:"display_name"`
+	PasswordHash string `json:"password_hash"`
+	Role         Role   `json:"role"`
+	// CommunityRoles are the community's own roles, such as a society or
+	// clan, that restricted teachings are shared with
+	CommunityRoles []string `json:"community_roles,omitempty"`
+	// Researcher marks an approved researcher from outside the community, who
+	// sees research-scoped recordings but not community-only ones
+	Researcher  bool       `json:"researcher,omitempty"`
+	Disabled    bool       `json:"disabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// Name returns the name used to identify the user to others
+func (u *User) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Username
+}
+
+// UserInfo is the public view of a user, without credentials
+type UserInfo struct {
+	ID             string   `json:"id"`
+	Username       string   `json:"username"`
+	DisplayName    string   `json:"display_name"`
+	Role           Role     `json:"role"`
+	CommunityRoles []string `json:"community_roles,omitempty"`
+	// Researcher marks an approved researcher from outside the community, who
+	// sees research-scoped recordings but not community-only ones
+	Researcher  bool       `json:"researcher,omitempty"`
+	Disabled    bool       `json:"disabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// Info returns the public view of the user
+func (u *User) Info() UserInfo {
+	return UserInfo{
+		ID:             u.ID,
+		Username:       u.Username,
+		DisplayName:    u.DisplayName,
+		Role:           u.Role,
+		CommunityRoles: u.CommunityRoles,
+		Researcher:     u.Researcher,
+		Disabled:       u.Disabled,
+		CreatedAt:      u.CreatedAt,
+		LastLoginAt:    u.LastLoginAt,
+	}
+}
+
+// Session is a logged-in browser session. ID is the hash of the cookie token,
+// so stored sessions cannot be replayed from disk.
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	CSRFToken string    `json:"csrf_token"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	RemoteIP  string    `json:"remote_ip,omitempty"`
+}
+
+// contextKey is the type of auth context keys
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	sessionContextKey
+	tokenContextKey
+	deviceContextKey
+	deviceCookieContextKey
+)
+
+// WithUser returns a context carrying the authenticated user and session
+func WithUser(ctx context.Context, user *User, session *Session) context.Context {
+	ctx = context.WithValue(ctx, userContextKey, user)
+	return context.WithValue(ctx, sessionContextKey, session)
+}
+
+// UserFromContext returns the authenticated user, or nil for anonymous requests
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userContextKey).(*User)
+	return user
+}
+
+// SessionFromContext returns the current session, or nil for anonymous requests
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey).(*Session)
+	return session
+}
+
+// WithToken returns a context carrying the API token used to authenticate
+func WithToken(ctx context.Context, token *Token) context.Context {
+	return context.WithValue(ctx, tokenContextKey, token)
+}
+
+// TokenFromContext returns the API token of the request, or nil for cookie and anonymous requests
+func TokenFromContext(ctx context.Context) *Token {
+	token, _ := ctx.Value(tokenContextKey).(*Token)
+	return token
+}
+
+// WithDevice returns a context carrying the paired kiosk a request came from.
+// viaCookie records whether the browser sent the credential automatically.
+func WithDevice(ctx context.Context, device *Device, viaCookie bool) context.Context {
+	ctx = context.WithValue(ctx, deviceContextKey, device)
+	return context.WithValue(ctx, deviceCookieContextKey, viaCookie)
+}
+
+// DeviceFromContext returns the paired kiosk of the request, or nil
+func DeviceFromContext(ctx context.Context) *Device {
+	device, _ := ctx.Value(deviceContextKey).(*Device)
+	return device
+}
+
+// deviceViaCookie reports whether the request's device credential came from a cookie
+func deviceViaCookie(ctx context.Context) bool {
+	viaCookie, _ := ctx.Value(deviceCookieContextKey).(bool)
+	return viaCookie
+}
+
+// randomToken returns a URL-safe random token with n bytes of entropy
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 of a token, used as its storage key
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
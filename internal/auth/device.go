@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// devicePrefix marks device credentials
+	devicePrefix = "gjd_"
+	// deviceCredentialBytes is the entropy of device credentials
+	deviceCredentialBytes = 32
+	// pairingTTL is how long a pairing code may be redeemed
+	pairingTTL = 15 * time.Minute
+	// pairingCodeLength is the number of characters in a pairing code, excluding the dash
+	pairingCodeLength = 8
+	// pairingAlphabet omits characters that are easy to confuse when read aloud or typed
+	pairingAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// maxDeviceFieldLength bounds device names and locations, counted in characters
+	maxDeviceFieldLength = 100
+)
+
+// ErrDeviceNotFound is returned for unknown or revoked devices and invalid pairing codes
+var ErrDeviceNotFound = errors.New("device not found")
+
+// Device is a paired kiosk. Paired devices act as kiosks wherever they are,
+// and only the hash of their credential is stored.
+type Device struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Location   string     `json:"location"`
+	Hash       string     `json:"hash"`
+	CSRFToken  string     `json:"csrf_token"`
+	PairedBy   string     `json:"paired_by"`
+	PairedAt   time.Time  `json:"paired_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// DeviceStats summarizes a device's uploads
+type DeviceStats struct {
+	Uploads       int        `json:"uploads"`
+	TotalDuration float64    `json:"total_duration"`
+	LastUploadAt  *time.Time `json:"last_upload_at,omitempty"`
+}
+
+// DeviceInfo is the public view of a device with its upload statistics
+type DeviceInfo struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Location   string      `json:"location"`
+	PairedBy   string      `json:"paired_by"`
+	PairedAt   time.Time   `json:"paired_at"`
+	LastSeenAt *time.Time  `json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	Stats      DeviceStats `json:"stats"`
+}
+
+// Info returns the public view of the device
+func (d *Device) Info(stats DeviceStats) DeviceInfo {
+	return DeviceInfo{
+		ID:         d.ID,
+		Name:       d.Name,
+		Location:   d.Location,
+		PairedBy:   d.PairedBy,
+		PairedAt:   d.PairedAt,
+		LastSeenAt: d.LastSeenAt,
+		RevokedAt:  d.RevokedAt,
+		Stats:      stats,
+	}
+}
+
+// Pairing is a pending, single-use code an admin issues for a new kiosk
+type Pairing struct {
+	Hash      string    `json:"hash"`
+	Name      string    `json:"name"`
+	Location  string    `json:"location"`
+	CreatedBy string    `json:"created_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PairingInput is the body of POST /api/devices/pairings
+type PairingInput struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+// CreatePairing issues a pairing code for a kiosk that will be named and placed as given
+func (s *Service) CreatePairing(ctx context.Context, creator string, input *PairingInput) (string, *Pairing, error) {
+	if input == nil {
+		return "", nil, fmt.Errorf("%w: device details are required", ErrInvalidInput)
+	}
+	name := strings.TrimSpace(input.Name)
+	location := strings.TrimSpace(input.Location)
+	if name == "" || len([]rune(name)) > maxDeviceFieldLength {
+		return "", nil, fmt.Errorf("%w: device name must be 1-%d characters", ErrInvalidInput, maxDeviceFieldLength)
+	}
+	if len([]rune(location)) > maxDeviceFieldLength {
+		return "", nil, fmt.Errorf("%w: device location must be at most %d characters", ErrInvalidInput, maxDeviceFieldLength)
+	}
+
+	code, err := pairingCode()
+	if err != nil {
+		return "", nil, err
+	}
+
+	pairing := &Pairing{
+		Hash:      hashToken(normalizePairingCode(code)),
+		Name:      name,
+		Location:  location,
+		CreatedBy: creator,
+		ExpiresAt: s.now().UTC().Add(pairingTTL),
+	}
+	if err := s.repo.SavePairing(ctx, pairing); err != nil {
+		return "", nil, fmt.Errorf("failed to save pairing: %w", err)
+	}
+
+	s.logger.Info("%s issued a pairing code for device %q at %q", creator, name, location)
+	s.recordAudit(ctx, AuditPairingCreate, "pairing:"+name, nil, map[string]interface{}{
+		"name":       pairing.Name,
+		"location":   pairing.Location,
+		"expires_at": pairing.ExpiresAt,
+	})
+	return code, pairing, nil
+}
+
+// Pair redeems a pairing code and registers the device, returning its credential
+func (s *Service) Pair(ctx context.Context, code string) (*Device, string, error) {
+	s.devicesMu.Lock()
+	defer s.devicesMu.Unlock()
+
+	hash := hashToken(normalizePairingCode(code))
+	pairing, err := s.repo.GetPairing(ctx, hash)
+	if err != nil {
+		return nil, "", err
+	}
+	// Pairing codes are single use, whether or not they have expired
+	if err := s.repo.DeletePairing(ctx, hash); err != nil {
+		return nil, "", fmt.Errorf("failed to consume pairing code: %w", err)
+	}
+	if !s.now().Before(pairing.ExpiresAt) {
+		return nil, "", ErrDeviceNotFound
+	}
+
+	credential, err := randomToken(deviceCredentialBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	credential = devicePrefix + credential
+	csrfToken, err := randomToken(csrfTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, "", err
+	}
+
+	device := &Device{
+		ID:        "dev_" + id,
+		Name:      pairing.Name,
+		Location:  pairing.Location,
+		Hash:      hashToken(credential),
+		CSRFToken: csrfToken,
+		PairedBy:  pairing.CreatedBy,
+		PairedAt:  s.now().UTC(),
+	}
+	if err := s.repo.SaveDevice(ctx, device); err != nil {
+		return nil, "", fmt.Errorf("failed to save device: %w", err)
+	}
+
+	s.logger.Info("Paired device %s (%s at %s)", device.ID, device.Name, device.Location)
+	// The kiosk has no credentials until now, so it is recorded as its own actor
+	s.recordAudit(WithDevice(ctx, device, false), AuditDevicePair, device.ID, nil, device.Info(DeviceStats{}))
+	return device, credential, nil
+}
+
+// AuthenticateDevice resolves a device credential to an active device
+func (s *Service) AuthenticateDevice(ctx context.Context, credential string) (*Device, error) {
+	if !strings.HasPrefix(credential, devicePrefix) {
+		return nil, ErrDeviceNotFound
+	}
+
+	device, err := s.repo.GetDevice(ctx, hashToken(credential))
+	if err != nil {
+		return nil, err
+	}
+	if device.RevokedAt != nil {
+		return nil, ErrDeviceNotFound
+	}
+
+	now := s.now()
+	if device.LastSeenAt == nil || now.Sub(*device.LastSeenAt) >= lastUsedResolution {
+		seen := now.UTC()
+		device.LastSeenAt = &seen
+		if err := s.repo.SaveDevice(ctx, device); err != nil {
+			s.logger.Error("Failed to record device activity for %s: %v", device.ID, err)
+		}
+	}
+	return device, nil
+}
+
+// ListDevices returns every paired device, including revoked ones
+func (s *Service) ListDevices(ctx context.Context) ([]*Device, error) {
+	devices, err := s.repo.ListDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return devices, nil
+}
+
+// RevokeDevice stops a lost or retired kiosk from authenticating
+func (s *Service) RevokeDevice(ctx context.Context, actor, id string) (*Device, error) {
+	s.devicesMu.Lock()
+	defer s.devicesMu.Unlock()
+
+	devices, err := s.repo.ListDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	for _, device := range devices {
+		if device.ID != id {
+			continue
+		}
+		if device.RevokedAt == nil {
+			before := device.Info(DeviceStats{})
+			now := s.now().UTC()
+			device.RevokedAt = &now
+			if err := s.repo.SaveDevice(ctx, device); err != nil {
+				return nil, fmt.Errorf("failed to save device: %w", err)
+			}
+			s.logger.Info("%s revoked device %s (%s)", actor, device.ID, device.Name)
+			s.recordAudit(ctx, AuditDeviceRevoke, device.ID, before, device.Info(DeviceStats{}))
+		}
+		return device, nil
+	}
+	return nil, ErrDeviceNotFound
+}
+
+// pairingCode returns a random code formatted as XXXX-XXXX
+func pairingCode() (string, error) {
+	b := make([]byte, pairingCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate pairing code: %w", err)
+	}
+	code := make([]byte, 0, pairingCodeLength+1)
+	for i, v := range b {
+		if i == pairingCodeLength/2 {
+			code = append(code, '-')
+		}
+		// The alphabet has 32 characters, so this is unbiased
+		code = append(code, pairingAlphabet[int(v)%len(pairingAlphabet)])
+	}
+	return string(code), nil
+}
+
+// normalizePairingCode uppercases a code and drops separators people may type
+func normalizePairingCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gooji/internal/middleware"
+)
+
+// maxDeviceBodySize bounds device request bodies
+const maxDeviceBodySize = 4 * 1024
+
+// PairRequest is the body of POST /api/devices/pair
+type PairRequest struct {
+	Code string `json:"code"`
+}
+
+// PairedDevice is the response to a successful pairing; Credential is shown only once
+type PairedDevice struct {
+	DeviceInfo
+	Credential string `json:"credential"`
+}
+
+// IssuedPairing is the response to POST /api/devices/pairings
+type IssuedPairing struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Location  string    `json:"location"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HandleDevices handles GET /api/devices, listing kiosks with their upload statistics
+func (h *Handler) HandleDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+	if !h.requirePermission(w, r, PermManageDevices) {
+		return
+	}
+
+	devices, err := h.service.ListDevices(r.Context())
+	if err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+
+	stats := map[string]DeviceStats{}
+	if h.deviceStats != nil {
+		if stats, err = h.deviceStats.DeviceUploadStats(r.Context()); err != nil {
+			h.handleInternalError(w, r, err)
+			return
+		}
+	}
+
+	infos := make([]DeviceInfo, 0, len(devices))
+	for _, device := range devices {
+		infos = append(infos, device.Info(stats[device.ID]))
+	}
+	h.writeJSONResponse(w, infos)
+}
+
+// HandleDevice handles the device subresources:
+// POST /api/devices/pairings issues a pairing code,
+// POST /api/devices/pair redeems one, and
+// DELETE /api/devices/{id} revokes a device
+func (h *Handler) HandleDevice(w http.ResponseWriter, r *http.Request) {
+	resource := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/devices/"), "/")
+	switch {
+	case resource == "pairings":
+		h.createPairing(w, r)
+	case resource == "pair":
+		h.pairAPI(w, r)
+	case resource != "" && !strings.Contains(resource, "/"):
+		h.revokeDevice(w, r, resource)
+	default:
+		http.Error(w, "Unknown device resource", http.StatusNotFound)
+	}
+}
+
+// createPairing issues a short-lived pairing code for a new kiosk
+func (h *Handler) createPairing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+	if !h.requirePermission(w, r, PermManageDevices) {
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxDeviceBodySize)
+	var input PairingInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		http.Error(w, "Invalid pairing request", http.StatusBadRequest)
+		return
+	}
+
+	code, pairing, err := h.service.CreatePairing(r.Context(), actorName(r.Context()), &input)
+	if err != nil {
+		h.handleDeviceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(IssuedPairing{
+		Code:      code,
+		Name:      pairing.Name,
+		Location:  pairing.Location,
+		ExpiresAt: pairing.ExpiresAt,
+	}); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+// pairAPI redeems a pairing code for kiosks that are not browsers
+func (h *Handler) pairAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxDeviceBodySize)
+	var input PairRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		http.Error(w, "Invalid pairing request", http.StatusBadRequest)
+		return
+	}
+
+	device, credential, err := h.service.Pair(r.Context(), input.Code)
+	if err != nil {
+		h.handleDeviceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(PairedDevice{DeviceInfo: device.Info(DeviceStats{}), Credential: credential}); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+// revokeDevice revokes a lost or retired kiosk
+func (h *Handler) revokeDevice(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodDelete {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+	if !h.requirePermission(w, r, PermManageDevices) {
+		return
+	}
+
+	device, err := h.service.RevokeDevice(r.Context(), actorName(r.Context()), id)
+	if err != nil {
+		h.handleDeviceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, device.Info(DeviceStats{}))
+}
+
+// HandlePair serves the kiosk pairing page on GET and redeems a code on POST,
+// storing the device credential in the kiosk browser's cookie
+func (h *Handler) HandlePair(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.renderPair(w, r, http.StatusOK, "", "")
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxDeviceBodySize)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid pairing form", http.StatusBadRequest)
+			return
+		}
+
+		cookie, err := r.Cookie(CSRFCookieName)
+		if err != nil || !tokensEqual(r.PostForm.Get(CSRFFormField), cookie.Value) {
+			h.logger.Error("Pairing CSRF check failed (remote: %s)", r.RemoteAddr)
+			h.renderPair(w, r, http.StatusForbidden, "Your session expired. Please try again.", "")
+			return
+		}
+
+		device, credential, err := h.service.Pair(r.Context(), r.PostForm.Get("code"))
+		if err != nil {
+			if errors.Is(err, ErrDeviceNotFound) {
+				h.renderPair(w, r, http.StatusBadRequest, "That pairing code is not valid or has expired.", "")
+				return
+			}
+			h.handleInternalError(w, r, err)
+			return
+		}
+
+		setDeviceCookie(w, r, credential, h.secureCookies)
+		setCSRFCookie(w, r, device.CSRFToken, deviceCookieMaxAge, h.secureCookies)
+		h.renderPair(w, r, http.StatusOK, "", device.Name)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// renderPair renders the pairing page; paired names the device that was just paired
+func (h *Handler) renderPair(w http.ResponseWriter, r *http.Request, status int, message, paired string) {
+	data := map[string]interface{}{
+		"Page":         "pair",
+		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
+		"Error":        message,
+		"Paired":       paired,
+	}
+	if device := DeviceFromContext(r.Context()); device != nil && paired == "" {
+		data["Current"] = device.Name
+	}
+	if paired == "" {
+		csrfToken, err := formCSRFToken(w, r, h.secureCookies)
+		if err != nil {
+			h.handleInternalError(w, r, err)
+			return
+		}
+		data["CSRFToken"] = csrfToken
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := h.pairTemplate.ExecuteTemplate(w, "base.html", data); err != nil {
+		h.logger.Error("Failed to render pairing page: %v", err)
+	}
+}
+
+// handleDeviceError maps device service errors to HTTP responses
+func (h *Handler) handleDeviceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrDeviceNotFound):
+		http.Error(w, "Device or pairing code not found", http.StatusNotFound)
+	default:
+		h.handleInternalError(w, r, err)
+	}
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"gooji/internal/config"
+	"gooji/internal/logger"
+	"gooji/internal/middleware"
+)
+
+const (
+	// maxLoginBodySize bounds the login form body
+	maxLoginBodySize = 4 * 1024
+	// loginCSRFMaxAge is how long the pre-login CSRF cookie lasts, in seconds
+	loginCSRFMaxAge = int(time.Hour / time.Second)
+)
+
+// DeviceStatsProvider reports upload statistics for paired devices, keyed by device ID
+type DeviceStatsProvider interface {
+	DeviceUploadStats(ctx context.Context) (map[string]DeviceStats, error)
+}
+
+// Handler serves the login page and session endpoints
+type Handler struct {
+	service       *Service
+	template      *template.Template
+	pairTemplate  *template.Template
+	deviceStats   DeviceStatsProvider
+	logger        *logger.Logger
+	secureCookies bool
+}
+
+// NewHandler creates a new auth handler backed by files under cfg.Storage.Auth
+func NewHandler(cfg *config.Config, deviceStats DeviceStatsProvider, auditLog AuditRecorder, log *logger.Logger) (*Handler, error) {
+	repo, err := NewRepository(cfg.Storage.Auth, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth repository: %w", err)
+	}
+
+	service, err := NewService(repo, cfg.Auth.SessionTTL, auditLog, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth service: %w", err)
+	}
+
+	tmpl, err := template.ParseFiles("web/templates/base.html", "web/templates/login.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse login template: %w", err)
+	}
+
+	pairTemplate, err := template.ParseFiles("web/templates/base.html", "web/templates/pair.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pairing template: %w", err)
+	}
+
+	return &Handler{
+		service:       service,
+		template:      tmpl,
+		pairTemplate:  pairTemplate,
+		deviceStats:   deviceStats,
+		logger:        log,
+		secureCookies: cfg.Auth.SecureCookies,
+	}, nil
+}
+
+// Service returns the auth service used by the handler
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// HandleLogin serves the login page on GET and signs in on POST
+func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if UserFromContext(r.Context()) != nil {
+			http.Redirect(w, r, safeRedirect(r.URL.Query().Get("next")), http.StatusSeeOther)
+			return
+		}
+		h.renderLogin(w, r, http.StatusOK, "", "")
+	case http.MethodPost:
+		h.login(w, r)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// login checks the submitted credentials and starts a session
+func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLoginBodySize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid login form", http.StatusBadRequest)
+		return
+	}
+
+	// Double-submit check: the form must echo the cookie set when the page was served
+	cookie, err := r.Cookie(CSRFCookieName)
+	if err != nil || !tokensEqual(r.PostForm.Get(CSRFFormField), cookie.Value) {
+		h.logger.Error("Login CSRF check failed (remote: %s)", r.RemoteAddr)
+		h.renderLogin(w, r, http.StatusForbidden, r.PostForm.Get("username"), "Your session expired. Please try again.")
+		return
+	}
+
+	username := r.PostForm.Get("username")
+	user, session, token, err := h.service.Login(r.Context(), username, r.PostForm.Get("password"), remoteIP(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			h.logger.Info("Failed login for %q (remote: %s)", username, r.RemoteAddr)
+			h.renderLogin(w, r, http.StatusUnauthorized, username, "Incorrect username or password.")
+			return
+		}
+		h.handleInternalError(w, r, err)
+		return
+	}
+
+	h.logger.Info("User %s signed in (remote: %s)", user.Username, r.RemoteAddr)
+	setSessionCookies(w, r, token, session, h.secureCookies)
+	http.Redirect(w, r, safeRedirect(r.PostForm.Get("next")), http.StatusSeeOther)
+}
+
+// HandleLogout ends the current session; it is POST-only so the CSRF middleware guards it
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		if err := h.service.Logout(r.Context(), cookie.Value); err != nil {
+			h.handleInternalError(w, r, err)
+			return
+		}
+	}
+
+	clearSessionCookies(w, r, h.secureCookies)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// HandleMe handles GET /api/auth/me with the signed-in user, if any
+func (h *Handler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	response := map[string]interface{}{"authenticated": false}
+	if user := UserFromContext(r.Context()); user != nil {
+		response = map[string]interface{}{
+			"authenticated": true,
+			"id":            user.ID,
+			"username":      user.Username,
+			"display_name":  user.Name(),
+			"role":          user.Role,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+// renderLogin renders the login page with a fresh pre-login CSRF token
+func (h *Handler) renderLogin(w http.ResponseWriter, r *http.Request, status int, username, message string) {
+	csrfToken, err := formCSRFToken(w, r, h.secureCookies)
+	if err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+
+	next := r.URL.Query().Get("next")
+	if r.Method == http.MethodPost {
+		next = r.PostForm.Get("next")
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := h.template.ExecuteTemplate(w, "base.html", map[string]interface{}{
+		"Page":         "login",
+		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
+		"CSRFToken":    csrfToken,
+		"Next":         safeRedirect(next),
+		"Username":     username,
+		"Error":        message,
+	}); err != nil {
+		h.logger.Error("Failed to render login page: %v", err)
+	}
+}
+
+// safeRedirect returns target if it is a local path, otherwise "/"
+func safeRedirect(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.Contains(target, `\`) {
+		return "/"
+	}
+	return target
+}
+
+// handleMethodNotAllowed handles method not allowed errors
+func (h *Handler) handleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	h.logger.Error("Method not allowed: %s %s (remote: %s)", r.Method, r.URL.Path, r.RemoteAddr)
+	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+// handleInternalError handles internal server errors
+func (h *Handler) handleInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("Internal server error: %v (method: %s, path: %s, remote: %s)",
+		err, r.Method, r.URL.Path, r.RemoteAddr)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
@@ -0,0 +1,353 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gooji/internal/logger"
+	"gooji/internal/middleware"
+)
+
+const (
+	// SessionCookieName holds the session token; it is HttpOnly
+	SessionCookieName = "gooji_session"
+	// CSRFCookieName holds the CSRF token; scripts read it to send CSRFHeader
+	CSRFCookieName = "gooji_csrf"
+	// CSRFHeader carries the CSRF token on script requests
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFFormField carries the CSRF token on HTML form posts
+	CSRFFormField = "csrf_token"
+	// DeviceCookieName holds a paired kiosk's credential; it is HttpOnly
+	DeviceCookieName = "gooji_device"
+	// DeviceHeader carries a device credential for kiosks that are not browsers
+	DeviceHeader = "X-Gooji-Device"
+	// deviceCookieMaxAge keeps a kiosk paired until it is revoked, in seconds
+	deviceCookieMaxAge = 5 * 365 * 24 * 60 * 60
+)
+
+// Sessions returns middleware that attaches the logged-in user to the request context
+func Sessions(svc *Service, secureCookies bool) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(SessionCookieName)
+			if err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, session, err := svc.Authenticate(r.Context(), cookie.Value)
+			if err != nil {
+				if !errors.Is(err, ErrSessionNotFound) {
+					logger.FromContext(r.Context(), svc.logger).Error("Failed to authenticate session: %v", err)
+				}
+				clearSessionCookies(w, r, secureCookies)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user, session)))
+		})
+	}
+}
+
+// Devices returns middleware that identifies paired kiosks from their device
+// cookie or header. Browser kiosks without a login session get the device's
+// CSRF token in the CSRF cookie, since their cookie is an ambient credential.
+// Revoked devices lose their cookie and continue as anonymous visitors.
+func Devices(svc *Service, secureCookies bool) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := r.Header.Get(DeviceHeader)
+			viaCookie := false
+			if credential == "" {
+				if cookie, err := r.Cookie(DeviceCookieName); err == nil {
+					credential = cookie.Value
+					viaCookie = true
+				}
+			}
+			if credential == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			device, err := svc.AuthenticateDevice(r.Context(), credential)
+			if err != nil {
+				if !errors.Is(err, ErrDeviceNotFound) {
+					logger.FromContext(r.Context(), svc.logger).Error("Failed to authenticate device: %v", err)
+				}
+				if viaCookie {
+					clearDeviceCookie(w, r, secureCookies)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if viaCookie && SessionFromContext(r.Context()) == nil {
+				if cookie, err := r.Cookie(CSRFCookieName); err != nil || cookie.Value != device.CSRFToken {
+					setCSRFCookie(w, r, device.CSRFToken, deviceCookieMaxAge, secureCookies)
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithDevice(r.Context(), device, viaCookie)))
+		})
+	}
+}
+
+// Bearer returns middleware that authenticates "Authorization: Bearer" API tokens.
+// It runs after Sessions and replaces any cookie identity, so token requests are
+// never subject to CSRF checks. Invalid tokens are rejected rather than treated
+// as anonymous, so scripts notice revoked or expired credentials.
+func Bearer(svc *Service) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, secret, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gooji"`)
+				http.Error(w, "Unsupported authorization scheme", http.StatusUnauthorized)
+				return
+			}
+
+			token, user, err := svc.AuthenticateToken(r.Context(), strings.TrimSpace(secret))
+			if err != nil {
+				if !errors.Is(err, ErrTokenNotFound) {
+					logger.FromContext(r.Context(), svc.logger).Error("Failed to authenticate token: %v", err)
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gooji", error="invalid_token"`)
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := WithUser(r.Context(), user, nil)
+			next.ServeHTTP(w, r.WithContext(WithToken(ctx, token)))
+		})
+	}
+}
+
+// CSRF returns middleware that rejects state-changing requests made with a
+// session or device cookie unless they echo that credential's CSRF token. It
+// must run after Sessions, Devices and Bearer. Requests without a cookie
+// credential can still carry authority the browser attaches by itself, such as
+// a kiosk or moderator network address, so they must come from this site:
+// browsers mark them with Sec-Fetch-Site or Origin. Bearer token requests are
+// never forged by a browser and are not checked.
+func CSRF() middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSafeMethod(r.Method) || TokenFromContext(r.Context()) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			expected := expectedCSRFToken(r)
+			if expected == "" {
+				if !sameOrigin(r) {
+					http.Error(w, "Cross-site request rejected", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !tokensEqual(requestCSRFToken(r), expected) {
+				http.Error(w, "Invalid or missing CSRF token", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// sameOrigin reports whether a request comes from a page on this site.
+// Browsers send Sec-Fetch-Site, or at least Origin, on cross-site posts;
+// clients sending neither are not browsers and carry nothing to forge.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	case "":
+	default:
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// expectedCSRFToken returns the CSRF token of the request's cookie credential,
+// or "" when it has none
+func expectedCSRFToken(r *http.Request) string {
+	if session := SessionFromContext(r.Context()); session != nil {
+		return session.CSRFToken
+	}
+	if device := DeviceFromContext(r.Context()); device != nil && deviceViaCookie(r.Context()) && TokenFromContext(r.Context()) == nil {
+		return device.CSRFToken
+	}
+	return ""
+}
+
+// formCSRFToken returns the token for a form on a page served before sign-in or
+// pairing. Requests that already carry a cookie credential reuse its token so
+// CSRF still accepts the post; others get a fresh double-submit token.
+func formCSRFToken(w http.ResponseWriter, r *http.Request, secureCookies bool) (string, error) {
+	if expected := expectedCSRFToken(r); expected != "" {
+		return expected, nil
+	}
+	token, err := randomToken(csrfTokenBytes)
+	if err != nil {
+		return "", err
+	}
+	setCSRFCookie(w, r, token, loginCSRFMaxAge, secureCookies)
+	return token, nil
+}
+
+// isSafeMethod reports whether a method must not change state
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// requestCSRFToken returns the CSRF token sent in the header, or in the body of
+// a URL-encoded form. Multipart bodies are not parsed here so uploads keep
+// their own size limits; scripts send the header instead.
+func requestCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		return r.PostFormValue(CSRFFormField)
+	}
+	return ""
+}
+
+// tokensEqual compares two tokens in constant time; empty tokens never match
+func tokensEqual(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// isSecure reports whether cookies for this request should be marked Secure
+func isSecure(r *http.Request, secureCookies bool) bool {
+	return secureCookies || r.TLS != nil
+}
+
+// setSessionCookies sets the session and CSRF cookies after login
+func setSessionCookies(w http.ResponseWriter, r *http.Request, token string, session *Session, secureCookies bool) {
+	maxAge := int(time.Until(session.ExpiresAt).Seconds())
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   isSecure(r, secureCookies),
+		SameSite: http.SameSiteLaxMode,
+	})
+	setCSRFCookie(w, r, session.CSRFToken, maxAge, secureCookies)
+}
+
+// setCSRFCookie sets the script-readable CSRF cookie
+func setCSRFCookie(w http.ResponseWriter, r *http.Request, token string, maxAge int, secureCookies bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   isSecure(r, secureCookies),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// clearSessionCookies expires the session and CSRF cookies
+func clearSessionCookies(w http.ResponseWriter, r *http.Request, secureCookies bool) {
+	for _, name := range []string{SessionCookieName, CSRFCookieName} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: name == SessionCookieName,
+			Secure:   isSecure(r, secureCookies),
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+}
+
+// setDeviceCookie stores a device credential in the kiosk's browser
+func setDeviceCookie(w http.ResponseWriter, r *http.Request, credential string, secureCookies bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     DeviceCookieName,
+		Value:    credential,
+		Path:     "/",
+		MaxAge:   deviceCookieMaxAge,
+		HttpOnly: true,
+		Secure:   isSecure(r, secureCookies),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearDeviceCookie expires the device cookie
+func clearDeviceCookie(w http.ResponseWriter, r *http.Request, secureCookies bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     DeviceCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecure(r, secureCookies),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// remoteIP returns the client IP of a request without its port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientKey identifies the client a request acts as, for rate limiting: the
+// API token, the signed-in account, or the paired device, in that order.
+// Personal tokens also carry their owner, so the token comes first to give
+// each token its own bucket. It returns "" for anonymous requests so callers
+// fall back to the IP address.
+func ClientKey(r *http.Request) string {
+	ctx := r.Context()
+	if token := TokenFromContext(ctx); token != nil {
+		return "token:" + token.ID
+	}
+	if user := UserFromContext(ctx); user != nil {
+		return "user:" + user.ID
+	}
+	if device := DeviceFromContext(ctx); device != nil {
+		return "device:" + device.ID
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	const csrfToken = "session-csrf-token"
+	session := &Session{CSRFToken: csrfToken}
+	user := &User{Role: RoleEditor}
+	device := &Device{CSRFToken: csrfToken}
+	token := &Token{Scopes: []Scope{ScopeEdit}}
+
+	tests := []struct {
+		name       string
+		method     string
+		credential string
+		header     map[string]string
+		form       string
+		wantStatus int
+	}{
+		{name: "safe method without credential", method: http.MethodGet, header: map[string]string{"Origin": "https://evil.example"}, wantStatus: http.StatusOK},
+		{name: "session with header token", method: http.MethodPost, credential: "session", header: map[string]string{CSRFHeader: csrfToken}, wantStatus: http.StatusOK},
+		{name: "session with form token", method: http.MethodPost, credential: "session", form: CSRFFormField + "=" + csrfToken, wantStatus: http.StatusOK},
+		{name: "session without token", method: http.MethodPost, credential: "session", wantStatus: http.StatusForbidden},
+		{name: "session with wrong token", method: http.MethodPost, credential: "session", header: map[string]string{CSRFHeader: "guess"}, wantStatus: http.StatusForbidden},
+		{name: "session token does not excuse same origin", method: http.MethodPost, credential: "session", header: map[string]string{"Sec-Fetch-Site": "same-origin"}, wantStatus: http.StatusForbidden},
+		{name: "device cookie with token", method: http.MethodPost, credential: "device-cookie", header: map[string]string{CSRFHeader: csrfToken}, wantStatus: http.StatusOK},
+		{name: "device cookie without token", method: http.MethodPost, credential: "device-cookie", wantStatus: http.StatusForbidden},
+		{name: "device header is not a cookie", method: http.MethodPost, credential: "device-header", wantStatus: http.StatusOK},
+		{name: "bearer token cross site", method: http.MethodDelete, credential: "token", header: map[string]string{"Origin": "https://evil.example"}, wantStatus: http.StatusOK},
+		{name: "token with device cookie", method: http.MethodPost, credential: "token+device-cookie", wantStatus: http.StatusOK},
+		{name: "no credential non-browser", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "no credential same origin fetch", method: http.MethodPost, header: map[string]string{"Sec-Fetch-Site": "same-origin"}, wantStatus: http.StatusOK},
+		{name: "no credential cross-site fetch", method: http.MethodPost, header: map[string]string{"Sec-Fetch-Site": "cross-site"}, wantStatus: http.StatusForbidden},
+		{name: "no credential same-site fetch", method: http.MethodPut, header: map[string]string{"Sec-Fetch-Site": "same-site"}, wantStatus: http.StatusForbidden},
+		{name: "no credential matching origin", method: http.MethodPost, header: map[string]string{"Origin": "http://kiosk.local"}, wantStatus: http.StatusOK},
+		{name: "no credential origin in other case", method: http.MethodPost, header: map[string]string{"Origin": "http://KIOSK.local"}, wantStatus: http.StatusOK},
+		{name: "no credential foreign origin", method: http.MethodPost, header: map[string]string{"Origin": "https://evil.example"}, wantStatus: http.StatusForbidden},
+		{name: "no credential opaque origin", method: http.MethodDelete, header: map[string]string{"Origin": "null"}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://kiosk.local/api/videos/1/review", strings.NewReader(tt.form))
+			if tt.form != "" {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			for name, value := range tt.header {
+				r.Header.Set(name, value)
+			}
+
+			ctx := r.Context()
+			switch tt.credential {
+			case "session":
+				ctx = WithUser(ctx, user, session)
+			case "device-cookie":
+				ctx = WithDevice(ctx, device, true)
+			case "device-header":
+				ctx = WithDevice(ctx, device, false)
+			case "token":
+				ctx = WithToken(WithUser(ctx, user, nil), token)
+			case "token+device-cookie":
+				ctx = WithToken(WithDevice(ctx, device, true), token)
+			}
+			r = r.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			CSRF()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	user := &User{ID: "usr_1", Role: RoleEditor}
+	token := &Token{ID: "tok_1", UserID: user.ID, Scopes: []Scope{ScopeRead}}
+	device := &Device{ID: "dev_1"}
+
+	tests := []struct {
+		name string
+		ctx  func(r *http.Request) *http.Request
+		want string
+	}{
+		{name: "session", ctx: func(r *http.Request) *http.Request {
+			return r.WithContext(WithUser(r.Context(), user, &Session{}))
+		}, want: "user:usr_1"},
+		{name: "personal token of the same user", ctx: func(r *http.Request) *http.Request {
+			return r.WithContext(WithToken(WithUser(r.Context(), user, nil), token))
+		}, want: "token:tok_1"},
+		{name: "service token", ctx: func(r *http.Request) *http.Request {
+			return r.WithContext(WithToken(r.Context(), &Token{ID: "tok_2", Scopes: []Scope{ScopeUpload}}))
+		}, want: "token:tok_2"},
+		{name: "device", ctx: func(r *http.Request) *http.Request {
+			return r.WithContext(WithDevice(r.Context(), device, true))
+		}, want: "device:dev_1"},
+		{name: "anonymous", ctx: func(r *http.Request) *http.Request { return r }, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.ctx(httptest.NewRequest(http.MethodGet, "/api/videos", nil))
+			if got := ClientKey(r); got != tt.want {
+				t.Errorf("ClientKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// minPasswordLength is the minimum password length in characters
+	minPasswordLength = 10
+	// maxPasswordBytes is bcrypt's input limit; longer passwords would be silently truncated
+	maxPasswordBytes = 72
+	// bcryptCost is the work factor for password hashes
+	bcryptCost = 12
+)
+
+// dummyHash is compared against when a username does not exist so that
+// response times do not reveal which usernames are registered
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gooji-dummy-password"), bcryptCost)
+
+// HashPassword validates a password and returns its bcrypt hash
+func HashPassword(password string) (string, error) {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return "", fmt.Errorf("password must be at most %d bytes", maxPasswordBytes)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// checkPassword reports whether password matches a bcrypt hash
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gooji/internal/logger"
+)
+
+// Repository persists users and sessions
+type Repository interface {
+	SaveUser(ctx context.Context, user *User) error
+	GetUser(ctx context.Context, id string) (*User, error)
+	ListUsers(ctx context.Context) ([]*User, error)
+	SaveSession(ctx context.Context, session *Session) error
+	GetSession(ctx context.Context, id string) (*Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	ListSessions(ctx context.Context) ([]*Session, error)
+	SaveToken(ctx context.Context, token *Token) error
+	GetToken(ctx context.Context, hash string) (*Token, error)
+	ListTokens(ctx context.Context) ([]*Token, error)
+	SavePairing(ctx context.Context, pairing *Pairing) error
+	GetPairing(ctx context.Context, hash string) (*Pairing, error)
+	DeletePairing(ctx context.Context, hash string) error
+	SaveDevice(ctx context.Context, device *Device) error
+	GetDevice(ctx context.Context, hash string) (*Device, error)
+	ListDevices(ctx context.Context) ([]*Device, error)
+}
+
+// repository implements Repository with one JSON file per record
+type repository struct {
+	dir    string
+	logger *logger.Logger
+}
+
+// NewRepository creates a file-backed auth repository rooted at dir
+func NewRepository(dir string, logger *logger.Logger) (Repository, error) {
+	r := &repository{dir: dir, logger: logger}
+	for _, sub := range []string{r.usersDir(), r.sessionsDir(), r.tokensDir(), r.devicesDir(), r.pairingsDir()} {
+		if err := os.MkdirAll(sub, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create auth directory %s: %w", sub, err)
+		}
+	}
+	return r, nil
+}
+
+// usersDir returns the directory holding user files
+func (r *repository) usersDir() string {
+	return filepath.Join(r.dir, "users")
+}
+
+// sessionsDir returns the directory holding session files
+func (r *repository) sessionsDir() string {
+	return filepath.Join(r.dir, "sessions")
+}
+
+// tokensDir returns the directory holding API token files, named by secret hash
+func (r *repository) tokensDir() string {
+	return filepath.Join(r.dir, "tokens")
+}
+
+// devicesDir returns the directory holding device files, named by credential hash
+func (r *repository) devicesDir() string {
+	return filepath.Join(r.dir, "devices")
+}
+
+// pairingsDir returns the directory holding pending pairing codes, named by code hash
+func (r *repository) pairingsDir() string {
+	return filepath.Join(r.dir, "pairings")
+}
+
+// SaveUser writes a user record
+func (r *repository) SaveUser(ctx context.Context, user *User) error {
+	return r.writeJSON(r.usersDir(), user.ID, user)
+}
+
+// GetUser reads a user record by ID
+func (r *repository) GetUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := r.readJSON(r.usersDir(), id, &user); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Role == "" {
+		// Accounts created before roles existed may only view
+		user.Role = RoleViewer
+	}
+	return &user, nil
+}
+
+// ListUsers returns every user record
+func (r *repository) ListUsers(ctx context.Context) ([]*User, error) {
+	var users []*User
+	err := r.forEach(r.usersDir(), func(id string) error {
+		user, err := r.GetUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	})
+	return users, err
+}
+
+// SaveSession writes a session record
+func (r *repository) SaveSession(ctx context.Context, session *Session) error {
+	return r.writeJSON(r.sessionsDir(), session.ID, session)
+}
+
+// GetSession reads a session record by its token hash
+func (r *repository) GetSession(ctx context.Context, id string) (*Session, error) {
+	var session Session
+	if err := r.readJSON(r.sessionsDir(), id, &session); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// DeleteSession removes a session record; deleting a missing session is not an error
+func (r *repository) DeleteSession(ctx context.Context, id string) error {
+	sessionPath, err := r.recordPath(r.sessionsDir(), id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(sessionPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns every stored session, including expired ones
+func (r *repository) ListSessions(ctx context.Context) ([]*Session, error) {
+	var sessions []*Session
+	err := r.forEach(r.sessionsDir(), func(id string) error {
+		session, err := r.GetSession(ctx, id)
+		if err != nil {
+			return err
+		}
+		sessions = append(sessions, session)
+		return nil
+	})
+	return sessions, err
+}
+
+// SaveToken writes an API token record
+func (r *repository) SaveToken(ctx context.Context, token *Token) error {
+	return r.writeJSON(r.tokensDir(), token.Hash, token)
+}
+
+// GetToken reads an API token record by the hash of its secret
+func (r *repository) GetToken(ctx context.Context, hash string) (*Token, error) {
+	var token Token
+	if err := r.readJSON(r.tokensDir(), hash, &token); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListTokens returns every API token record, including expired and revoked ones
+func (r *repository) ListTokens(ctx context.Context) ([]*Token, error) {
+	var tokens []*Token
+	err := r.forEach(r.tokensDir(), func(hash string) error {
+		token, err := r.GetToken(ctx, hash)
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, token)
+		return nil
+	})
+	return tokens, err
+}
+
+// SavePairing writes a pending pairing code
+func (r *repository) SavePairing(ctx context.Context, pairing *Pairing) error {
+	return r.writeJSON(r.pairingsDir(), pairing.Hash, pairing)
+}
+
+// GetPairing reads a pending pairing code by its hash
+func (r *repository) GetPairing(ctx context.Context, hash string) (*Pairing, error) {
+	var pairing Pairing
+	if err := r.readJSON(r.pairingsDir(), hash, &pairing); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+	return &pairing, nil
+}
+
+// DeletePairing removes a pairing code once it has been redeemed
+func (r *repository) DeletePairing(ctx context.Context, hash string) error {
+	pairingPath, err := r.recordPath(r.pairingsDir(), hash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(pairingPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete pairing: %w", err)
+	}
+	return nil
+}
+
+// SaveDevice writes a device record
+func (r *repository) SaveDevice(ctx context.Context, device *Device) error {
+	return r.writeJSON(r.devicesDir(), device.Hash, device)
+}
+
+// GetDevice reads a device record by the hash of its credential
+func (r *repository) GetDevice(ctx context.Context, hash string) (*Device, error) {
+	var device Device
+	if err := r.readJSON(r.devicesDir(), hash, &device); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// ListDevices returns every device record, including revoked ones
+func (r *repository) ListDevices(ctx context.Context) ([]*Device, error) {
+	var devices []*Device
+	err := r.forEach(r.devicesDir(), func(hash string) error {
+		device, err := r.GetDevice(ctx, hash)
+		if err != nil {
+			return err
+		}
+		devices = append(devices, device)
+		return nil
+	})
+	return devices, err
+}
+
+// recordPath returns the validated file path of a record
+func (r *repository) recordPath(dir, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("invalid record ID: %q", id)
+	}
+
+	recordPath := filepath.Join(dir, id+".json")
+	absPath, err := filepath.Abs(recordPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve allowed directory: %w", err)
+	}
+	if filepath.Dir(absPath) != absDir {
+		return "", fmt.Errorf("file path %s is outside allowed directory %s", absPath, absDir)
+	}
+	return recordPath, nil
+}
+
+// writeJSON atomically writes a record so readers never see a partial file
+func (r *repository) writeJSON(dir, id string, v interface{}) error {
+	recordPath, err := r.recordPath(dir, id)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	tmpPath := recordPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if err := os.Rename(tmpPath, recordPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace record: %w", err)
+	}
+	return nil
+}
+
+// readJSON reads a record, returning an os.IsNotExist error when it is missing
+func (r *repository) readJSON(dir, id string, v interface{}) error {
+	recordPath, err := r.recordPath(dir, id)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(recordPath) //nolint:gosec // Path validated above
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode record %s: %w", recordPath, err)
+	}
+	return nil
+}
+
+// forEach calls fn with the ID of every record in dir
+func (r *repository) forEach(dir string, fn func(id string) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if err := fn(strings.TrimSuffix(name, ".json")); err != nil {
+			r.logger.Error("Failed to load auth record %s: %v", name, err)
+		}
+	}
+	return nil
+}
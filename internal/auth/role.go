@@ -0,0 +1,82 @@
+package auth
+
+import "fmt"
+
+// Role is an account's permission level
+type Role string
+
+// Roles, from least to most privileged
+const (
+	// RoleViewer may watch approved videos
+	RoleViewer Role = "viewer"
+	// RoleRecorder may also record and upload videos
+	RoleRecorder Role = "recorder"
+	// RoleEditor may also edit metadata, captions, transcripts, access and collections
+	RoleEditor Role = "editor"
+	// RoleModerator may also review and delete videos and read the audit log and storage usage
+	RoleModerator Role = "moderator"
+	// RoleAdmin may also manage accounts, kiosk devices and runtime settings such as log levels
+	RoleAdmin Role = "admin"
+)
+
+// Permission is an operation a role may be allowed to perform
+type Permission string
+
+// Permissions checked by route policies and services
+const (
+	PermViewVideos     Permission = "videos:view"
+	PermUploadVideos   Permission = "videos:upload"
+	PermEditVideos     Permission = "videos:edit"
+	PermReviewVideos   Permission = "videos:review"
+	PermDeleteVideos   Permission = "videos:delete"
+	PermManageUsers    Permission = "users:manage"
+	PermManageDevices  Permission = "devices:manage"
+	PermViewAudit      Permission = "audit:view"
+	PermViewStorage    Permission = "storage:view"
+	PermManageSettings Permission = "settings:manage"
+)
+
+// rolePermissions lists what each role may do
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:    {PermViewVideos},
+	RoleRecorder:  {PermViewVideos, PermUploadVideos},
+	RoleEditor:    {PermViewVideos, PermUploadVideos, PermEditVideos},
+	RoleModerator: {PermViewVideos, PermUploadVideos, PermEditVideos, PermReviewVideos, PermDeleteVideos, PermViewAudit, PermViewStorage},
+	RoleAdmin:     {PermViewVideos, PermUploadVideos, PermEditVideos, PermReviewVideos, PermDeleteVideos, PermViewAudit, PermViewStorage, PermManageUsers, PermManageDevices, PermManageSettings},
+}
+
+// roleRank orders roles so the stronger of two can be chosen
+var roleRank = map[Role]int{
+	RoleViewer:    1,
+	RoleRecorder:  2,
+	RoleEditor:    3,
+	RoleModerator: 4,
+	RoleAdmin:     5,
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q: use viewer, recorder, editor, moderator or admin", name)
+	}
+	return role, nil
+}
+
+// Can reports whether the role grants a permission; unknown roles grant nothing
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Max returns the more privileged of two roles
+func (r Role) Max(other Role) Role {
+	if roleRank[other] > roleRank[r] {
+		return other
+	}
+	return r
+}
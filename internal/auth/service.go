@@ -0,0 +1,398 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"gooji/internal/logger"
+)
+
+const (
+	// sessionTokenBytes is the entropy of session cookie tokens
+	sessionTokenBytes = 32
+	// csrfTokenBytes is the entropy of CSRF tokens
+	csrfTokenBytes = 32
+	// maxDisplayNameLength bounds display names, counted in characters
+	maxDisplayNameLength = 100
+	// maxCommunityRoles bounds how many community roles an account holds
+	maxCommunityRoles = 20
+	// maxCommunityRoleLength bounds a community role name, counted in characters
+	maxCommunityRoleLength = 50
+)
+
+// usernamePattern restricts usernames to lowercase letters, digits, dot, dash and underscore
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,31}$`)
+
+// Service manages accounts and login sessions
+type Service struct {
+	repo       Repository
+	sessionTTL time.Duration
+	audit      AuditRecorder
+	logger     *logger.Logger
+	usersMu    sync.Mutex
+	devicesMu  sync.Mutex
+	now        func() time.Time
+}
+
+// NewService creates a new auth service; sessionTTL is a Go duration string.
+// Account, token and device changes are recorded in auditLog, which may be nil.
+func NewService(repo Repository, sessionTTL string, auditLog AuditRecorder, logger *logger.Logger) (*Service, error) {
+	ttl, err := time.ParseDuration(sessionTTL)
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("invalid session TTL %q", sessionTTL)
+	}
+	return &Service{
+		repo:       repo,
+		sessionTTL: ttl,
+		audit:      auditLog,
+		logger:     logger,
+		now:        time.Now,
+	}, nil
+}
+
+// SessionTTL returns how long new sessions last
+func (s *Service) SessionTTL() time.Duration {
+	return s.sessionTTL
+}
+
+// normalizeUsername lowercases and trims a username for storage and lookup
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// normalizeCommunityRoles trims, lowercases and deduplicates community roles,
+// normalized to NFC the way videos store the roles they are restricted to
+func normalizeCommunityRoles(roles []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		role = strings.ToLower(strings.TrimSpace(norm.NFC.String(role)))
+		if role == "" || seen[role] {
+			continue
+		}
+		if len([]rune(role)) > maxCommunityRoleLength || strings.IndexFunc(role, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("community roles must be at most %d characters without control characters", maxCommunityRoleLength)
+		}
+		seen[role] = true
+		normalized = append(normalized, role)
+	}
+	if len(normalized) > maxCommunityRoles {
+		return nil, fmt.Errorf("at most %d community roles are allowed", maxCommunityRoles)
+	}
+	return normalized, nil
+}
+
+// CreateUser registers a new local account
+func (s *Service) CreateUser(ctx context.Context, username, displayName, password string, role Role) (*User, error) {
+	if _, err := ParseRole(string(role)); err != nil {
+		return nil, err
+	}
+	username = normalizeUsername(username)
+	if !usernamePattern.MatchString(username) {
+		return nil, fmt.Errorf("username must be 3-32 characters of lowercase letters, digits, '.', '-' or '_'")
+	}
+	displayName = strings.TrimSpace(displayName)
+	if len([]rune(displayName)) > maxDisplayNameLength {
+		return nil, fmt.Errorf("display name must be at most %d characters", maxDisplayNameLength)
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	if existing, err := s.findUser(ctx, username); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, ErrUserExists
+	}
+
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	user := &User{
+		ID:           "usr_" + id,
+		Username:     username,
+		DisplayName:  displayName,
+		PasswordHash: hash,
+		Role:         role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.SaveUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	s.logger.Info("Created user %s (%s) with role %s", user.Username, user.ID, user.Role)
+	s.recordAudit(ctx, AuditUserCreate, user.ID, nil, user.Info())
+	return user, nil
+}
+
+// SetPassword replaces a user's password and signs out their existing sessions
+func (s *Service) SetPassword(ctx context.Context, username, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.findUser(ctx, normalizeUsername(username))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	user.PasswordHash = hash
+	user.UpdatedAt = s.now().UTC()
+	if err := s.repo.SaveUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+	s.recordAudit(ctx, AuditUserPassword, user.ID, nil, nil)
+	return s.revokeUserSessions(ctx, user.ID)
+}
+
+// SetRole changes a user's role, identified by ID or username
+func (s *Service) SetRole(ctx context.Context, idOrUsername string, role Role) (*User, error) {
+	if _, err := ParseRole(string(role)); err != nil {
+		return nil, err
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.lookupUser(ctx, idOrUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	before := user.Info()
+	user.Role = role
+	user.UpdatedAt = s.now().UTC()
+	if err := s.repo.SaveUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	s.logger.Info("Set role of %s (%s) to %s", user.Username, user.ID, user.Role)
+	s.recordAudit(ctx, AuditUserRole, user.ID, before, user.Info())
+	return user, nil
+}
+
+// SetCommunityRoles replaces the community roles an account holds; an empty list clears them
+func (s *Service) SetCommunityRoles(ctx context.Context, idOrUsername string, roles []string) (*User, error) {
+	normalized, err := normalizeCommunityRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.lookupUser(ctx, idOrUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	before := user.Info()
+	user.CommunityRoles = normalized
+	user.UpdatedAt = s.now().UTC()
+	if err := s.repo.SaveUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	s.logger.Info("Set community roles of %s (%s) to %v", user.Username, user.ID, user.CommunityRoles)
+	s.recordAudit(ctx, AuditUserCommunity, user.ID, before, user.Info())
+	return user, nil
+}
+
+// SetResearcher marks or unmarks an account as an approved researcher
+func (s *Service) SetResearcher(ctx context.Context, idOrUsername string, researcher bool) (*User, error) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.lookupUser(ctx, idOrUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	before := user.Info()
+	user.Researcher = researcher
+	user.UpdatedAt = s.now().UTC()
+	if err := s.repo.SaveUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	s.logger.Info("Set researcher of %s (%s) to %t", user.Username, user.ID, user.Researcher)
+	s.recordAudit(ctx, AuditUserResearch, user.ID, before, user.Info())
+	return user, nil
+}
+
+// ListUsers returns all accounts
+func (s *Service) ListUsers(ctx context.Context) ([]*User, error) {
+	return s.repo.ListUsers(ctx)
+}
+
+// Login checks credentials and starts a session, returning the cookie token
+func (s *Service) Login(ctx context.Context, username, password, remoteIP, userAgent string) (*User, *Session, string, error) {
+	user, err := s.findUser(ctx, normalizeUsername(username))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if user == nil || user.Disabled {
+		// Compare anyway so unknown usernames take as long as wrong passwords
+		checkPassword(string(dummyHash), password)
+		return nil, nil, "", ErrInvalidCredentials
+	}
+	if !checkPassword(user.PasswordHash, password) {
+		return nil, nil, "", ErrInvalidCredentials
+	}
+
+	token, err := randomToken(sessionTokenBytes)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	csrfToken, err := randomToken(csrfTokenBytes)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	now := s.now().UTC()
+	session := &Session{
+		ID:        hashToken(token),
+		UserID:    user.ID,
+		CSRFToken: csrfToken,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionTTL),
+		UserAgent: userAgent,
+		RemoteIP:  remoteIP,
+	}
+	if err := s.repo.SaveSession(ctx, session); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to save session: %w", err)
+	}
+
+	user.LastLoginAt = &now
+	if err := s.repo.SaveUser(ctx, user); err != nil {
+		s.logger.Error("Failed to record last login for %s: %v", user.ID, err)
+	}
+
+	s.pruneExpiredSessions(ctx)
+	return user, session, token, nil
+}
+
+// Authenticate resolves a session cookie token to its user
+func (s *Service) Authenticate(ctx context.Context, token string) (*User, *Session, error) {
+	if token == "" {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	session, err := s.repo.GetSession(ctx, hashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !s.now().Before(session.ExpiresAt) {
+		if err := s.repo.DeleteSession(ctx, session.ID); err != nil {
+			s.logger.Error("Failed to delete expired session: %v", err)
+		}
+		return nil, nil, ErrSessionNotFound
+	}
+
+	user, err := s.repo.GetUser(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, nil, ErrSessionNotFound
+		}
+		return nil, nil, err
+	}
+	if user.Disabled {
+		return nil, nil, ErrSessionNotFound
+	}
+	return user, session, nil
+}
+
+// Logout ends the session identified by a cookie token
+func (s *Service) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.repo.DeleteSession(ctx, hashToken(token))
+}
+
+// lookupUser finds a user by ID, or by username when the value is not a user ID
+func (s *Service) lookupUser(ctx context.Context, idOrUsername string) (*User, error) {
+	if strings.HasPrefix(idOrUsername, "usr_") {
+		return s.repo.GetUser(ctx, idOrUsername)
+	}
+	user, err := s.findUser(ctx, normalizeUsername(idOrUsername))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// findUser looks up a user by normalized username, returning nil if none exists
+func (s *Service) findUser(ctx context.Context, username string) (*User, error) {
+	users, err := s.repo.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, user := range users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+// revokeUserSessions deletes every session belonging to a user
+func (s *Service) revokeUserSessions(ctx context.Context, userID string) error {
+	sessions, err := s.repo.ListSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.UserID != userID {
+			continue
+		}
+		if err := s.repo.DeleteSession(ctx, session.ID); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	return nil
+}
+
+// pruneExpiredSessions removes sessions past their expiry
+func (s *Service) pruneExpiredSessions(ctx context.Context) {
+	sessions, err := s.repo.ListSessions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list sessions for pruning: %v", err)
+		return
+	}
+	now := s.now()
+	for _, session := range sessions {
+		if now.Before(session.ExpiresAt) {
+			continue
+		}
+		if err := s.repo.DeleteSession(ctx, session.ID); err != nil {
+			s.logger.Error("Failed to prune session: %v", err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// tokenPrefix marks Gooji API tokens so leaked ones are easy to recognize
+	tokenPrefix = "gji_"
+	// tokenBytes is the entropy of API tokens
+	tokenBytes = 32
+	// defaultTokenTTL applies when a token is created without an expiry
+	defaultTokenTTL = 90 * 24 * time.Hour
+	// maxTokenTTL bounds how long a token may live
+	maxTokenTTL = 365 * 24 * time.Hour
+	// lastUsedResolution limits how often last-used times are written
+	lastUsedResolution = time.Minute
+	// maxTokenNameLength bounds token names, counted in characters
+	maxTokenNameLength = 100
+)
+
+// ErrTokenNotFound is returned for unknown, expired or revoked tokens
+var ErrTokenNotFound = errors.New("token not found")
+
+// Scope limits what an API token may do
+type Scope string
+
+// Token scopes
+const (
+	ScopeRead   Scope = "read"
+	ScopeUpload Scope = "upload"
+	ScopeEdit   Scope = "edit"
+	ScopeAdmin  Scope = "admin"
+)
+
+// scopePermissions lists what each scope allows; the owner's role must also allow it
+var scopePermissions = map[Scope][]Permission{
+	ScopeRead:   {PermViewVideos},
+	ScopeUpload: {PermViewVideos, PermUploadVideos},
+	ScopeEdit:   {PermViewVideos, PermEditVideos},
+	ScopeAdmin:  {PermViewVideos, PermUploadVideos, PermEditVideos, PermReviewVideos, PermDeleteVideos, PermViewAudit, PermViewStorage, PermManageUsers, PermManageDevices, PermManageSettings},
+}
+
+// scopeRoles is the role a service token holds for each scope
+var scopeRoles = map[Scope]Role{
+	ScopeRead:   RoleViewer,
+	ScopeUpload: RoleRecorder,
+	ScopeEdit:   RoleEditor,
+	ScopeAdmin:  RoleAdmin,
+}
+
+// Token is a long-lived credential for scripts and devices. Personal tokens act
+// as their owner, narrowed by scope; service tokens have no owner and act with
+// the role their scopes imply. Only the hash of the secret is stored.
+type Token struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	Scopes     []Scope    `json:"scopes"`
+	Hash       string     `json:"hash"`
+	Hint       string     `json:"hint"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TokenInfo is the public view of a token, without its hash
+type TokenInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id,omitempty"`
+	Service    bool       `json:"service"`
+	CreatedBy  string     `json:"created_by"`
+	Scopes     []Scope    `json:"scopes"`
+	Hint       string     `json:"hint"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TokenInput is the body of POST /api/tokens
+type TokenInput struct {
+	Name          string  `json:"name"`
+	Scopes        []Scope `json:"scopes"`
+	ExpiresInDays int     `json:"expires_in_days"`
+	// Service creates an ownerless token for a device or sync job; admins only
+	Service bool `json:"service"`
+}
+
+// Info returns the public view of the token
+func (t *Token) Info() TokenInfo {
+	return TokenInfo{
+		ID:         t.ID,
+		Name:       t.Name,
+		UserID:     t.UserID,
+		Service:    t.UserID == "",
+		CreatedBy:  t.CreatedBy,
+		Scopes:     t.Scopes,
+		Hint:       t.Hint,
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		RevokedAt:  t.RevokedAt,
+	}
+}
+
+// Allows reports whether any of the token's scopes allows a permission
+func (t *Token) Allows(permission Permission) bool {
+	for _, scope := range t.Scopes {
+		for _, p := range scopePermissions[scope] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ServiceRole returns the role a service token acts with: the strongest its scopes imply
+func (t *Token) ServiceRole() Role {
+	role := RoleViewer
+	for _, scope := range t.Scopes {
+		role = role.Max(scopeRoles[scope])
+	}
+	return role
+}
+
+// active reports whether the token may be used at now
+func (t *Token) active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// Can reports whether the credentials on ctx allow a permission, ignoring
+// network location. Tokens are limited to their scopes.
+func Can(ctx context.Context, permission Permission) bool {
+	token := TokenFromContext(ctx)
+	if token != nil && !token.Allows(permission) {
+		return false
+	}
+	if user := UserFromContext(ctx); user != nil {
+		return user.Role.Can(permission)
+	}
+	return token != nil && token.ServiceRole().Can(permission)
+}
+
+// CreateToken issues a token for the caller and returns it with its secret, which is shown once
+func (s *Service) CreateToken(ctx context.Context, creator *User, input *TokenInput) (*Token, string, error) {
+	if creator == nil {
+		return nil, "", fmt.Errorf("%w: sign in to create tokens", ErrForbidden)
+	}
+	if input == nil {
+		return nil, "", fmt.Errorf("%w: token details are required", ErrInvalidInput)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > maxTokenNameLength {
+		return nil, "", fmt.Errorf("%w: token name must be 1-%d characters", ErrInvalidInput, maxTokenNameLength)
+	}
+
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if input.Service && !creator.Role.Can(PermManageUsers) {
+		return nil, "", fmt.Errorf("%w: only admins can create service tokens", ErrForbidden)
+	}
+	if !input.Service {
+		// A personal token can never do more than its owner
+		for _, scope := range scopes {
+			for _, p := range scopePermissions[scope] {
+				if !creator.Role.Can(p) {
+					return nil, "", fmt.Errorf("%w: role %q cannot grant scope %q", ErrForbidden, creator.Role, scope)
+				}
+			}
+		}
+	}
+
+	ttl := defaultTokenTTL
+	if input.ExpiresInDays < 0 {
+		return nil, "", fmt.Errorf("%w: expires_in_days must not be negative", ErrInvalidInput)
+	}
+	if input.ExpiresInDays > 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > maxTokenTTL {
+		return nil, "", fmt.Errorf("%w: tokens may last at most %d days", ErrInvalidInput, int(maxTokenTTL/(24*time.Hour)))
+	}
+
+	secret, err := randomToken(tokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	secret = tokenPrefix + secret
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := s.now().UTC()
+	token := &Token{
+		ID:        "tok_" + id,
+		Name:      name,
+		CreatedBy: creator.ID,
+		Scopes:    scopes,
+		Hash:      hashToken(secret),
+		Hint:      secret[len(secret)-4:],
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if !input.Service {
+		token.UserID = creator.ID
+	}
+
+	if err := s.repo.SaveToken(ctx, token); err != nil {
+		return nil, "", fmt.Errorf("failed to save token: %w", err)
+	}
+
+	s.logger.Info("User %s created token %s (%s) with scopes %v", creator.Username, token.ID, token.Name, token.Scopes)
+	s.recordAudit(ctx, AuditTokenCreate, token.ID, nil, token.Info())
+	return token, secret, nil
+}
+
+// ListTokens returns the caller's tokens; admins see every token
+func (s *Service) ListTokens(ctx context.Context, caller *User) ([]*Token, error) {
+	tokens, err := s.repo.ListTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	if caller != nil && caller.Role.Can(PermManageUsers) {
+		return tokens, nil
+	}
+
+	owned := make([]*Token, 0, len(tokens))
+	for _, token := range tokens {
+		if caller != nil && token.UserID == caller.ID {
+			owned = append(owned, token)
+		}
+	}
+	return owned, nil
+}
+
+// RevokeToken revokes a token by ID; users may revoke their own, admins any
+func (s *Service) RevokeToken(ctx context.Context, caller *User, id string) (*Token, error) {
+	tokens, err := s.repo.ListTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	for _, token := range tokens {
+		if token.ID != id {
+			continue
+		}
+		if caller == nil || (token.UserID != caller.ID && !caller.Role.Can(PermManageUsers)) {
+			return nil, ErrTokenNotFound
+		}
+		if token.RevokedAt == nil {
+			before := token.Info()
+			now := s.now().UTC()
+			token.RevokedAt = &now
+			if err := s.repo.SaveToken(ctx, token); err != nil {
+				return nil, fmt.Errorf("failed to save token: %w", err)
+			}
+			s.logger.Info("User %s revoked token %s (%s)", caller.Username, token.ID, token.Name)
+			s.recordAudit(ctx, AuditTokenRevoke, token.ID, before, token.Info())
+		}
+		return token, nil
+	}
+	return nil, ErrTokenNotFound
+}
+
+// AuthenticateToken resolves a bearer token to the token and, for personal tokens, its owner
+func (s *Service) AuthenticateToken(ctx context.Context, secret string) (*Token, *User, error) {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return nil, nil, ErrTokenNotFound
+	}
+
+	token, err := s.repo.GetToken(ctx, hashToken(secret))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := s.now()
+	if !token.active(now) {
+		return nil, nil, ErrTokenNotFound
+	}
+
+	var user *User
+	if token.UserID != "" {
+		user, err = s.repo.GetUser(ctx, token.UserID)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return nil, nil, ErrTokenNotFound
+			}
+			return nil, nil, err
+		}
+		if user.Disabled {
+			return nil, nil, ErrTokenNotFound
+		}
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		used := now.UTC()
+		token.LastUsedAt = &used
+		if err := s.repo.SaveToken(ctx, token); err != nil {
+			s.logger.Error("Failed to record token use for %s: %v", token.ID, err)
+		}
+	}
+
+	return token, user, nil
+}
+
+// normalizeScopes validates and de-duplicates scopes
+func normalizeScopes(scopes []Scope) ([]Scope, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required: read, upload, edit or admin", ErrInvalidInput)
+	}
+	seen := make(map[Scope]bool, len(scopes))
+	normalized := make([]Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		if _, ok := scopePermissions[scope]; !ok {
+			return nil, fmt.Errorf("%w: unknown scope %q: use read, upload, edit or admin", ErrInvalidInput, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
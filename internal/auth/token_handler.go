@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gooji/internal/middleware"
+)
+
+// maxTokenBodySize bounds token creation bodies
+const maxTokenBodySize = 4 * 1024
+
+// CreatedToken is the response to POST /api/tokens; Token is shown only once
+type CreatedToken struct {
+	TokenInfo
+	Token string `json:"token"`
+}
+
+// HandleTokens handles GET /api/tokens and POST /api/tokens
+func (h *Handler) HandleTokens(w http.ResponseWriter, r *http.Request) {
+	if !tokenManagementAllowed(r) {
+		h.handleTokenError(w, r, ErrForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.listTokens(w, r)
+	case http.MethodPost:
+		h.createToken(w, r)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// HandleToken handles DELETE /api/tokens/{id}, revoking a token
+func (h *Handler) HandleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+	if !tokenManagementAllowed(r) {
+		h.handleTokenError(w, r, ErrForbidden)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tokens/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Missing token ID", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.service.RevokeToken(r.Context(), UserFromContext(r.Context()), id)
+	if err != nil {
+		h.handleTokenError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, token.Info())
+}
+
+// tokenManagementAllowed reports whether the caller may list or revoke tokens:
+// signed-in users may, and API tokens only with the admin scope
+func tokenManagementAllowed(r *http.Request) bool {
+	if token := TokenFromContext(r.Context()); token != nil {
+		return token.Allows(PermManageUsers) && UserFromContext(r.Context()) != nil
+	}
+	return UserFromContext(r.Context()) != nil
+}
+
+// listTokens writes the caller's tokens
+func (h *Handler) listTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.service.ListTokens(r.Context(), UserFromContext(r.Context()))
+	if err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+
+	infos := make([]TokenInfo, 0, len(tokens))
+	for _, token := range tokens {
+		infos = append(infos, token.Info())
+	}
+	h.writeJSONResponse(w, infos)
+}
+
+// createToken issues a token; tokens cannot be used to mint further tokens
+func (h *Handler) createToken(w http.ResponseWriter, r *http.Request) {
+	if TokenFromContext(r.Context()) != nil {
+		h.handleTokenError(w, r, ErrForbidden)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxTokenBodySize)
+	var input TokenInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		http.Error(w, "Invalid token request", http.StatusBadRequest)
+		return
+	}
+
+	token, secret, err := h.service.CreateToken(r.Context(), UserFromContext(r.Context()), &input)
+	if err != nil {
+		h.handleTokenError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(CreatedToken{TokenInfo: token.Info(), Token: secret}); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+// handleTokenError maps token service errors to HTTP responses
+func (h *Handler) handleTokenError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrForbidden):
+		h.logger.Error("Forbidden: %v (method: %s, path: %s, remote: %s)", err, r.Method, r.URL.Path, r.RemoteAddr)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, ErrTokenNotFound):
+		http.Error(w, "Token not found", http.StatusNotFound)
+	default:
+		h.handleInternalError(w, r, err)
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTokenAllowsAndCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []Scope
+		owner      Role
+		permission Permission
+		allows     bool
+		can        bool
+	}{
+		{name: "read scope views", scopes: []Scope{ScopeRead}, owner: RoleEditor, permission: PermViewVideos, allows: true, can: true},
+		{name: "read scope cannot edit for an editor", scopes: []Scope{ScopeRead}, owner: RoleEditor, permission: PermEditVideos, allows: false, can: false},
+		{name: "upload scope uploads for a recorder", scopes: []Scope{ScopeUpload}, owner: RoleRecorder, permission: PermUploadVideos, allows: true, can: true},
+		{name: "upload scope cannot exceed a viewer", scopes: []Scope{ScopeUpload}, owner: RoleViewer, permission: PermUploadVideos, allows: true, can: false},
+		{name: "edit scope cannot exceed a recorder", scopes: []Scope{ScopeEdit}, owner: RoleRecorder, permission: PermEditVideos, allows: true, can: false},
+		{name: "edit scope does not upload", scopes: []Scope{ScopeEdit}, owner: RoleAdmin, permission: PermUploadVideos, allows: false, can: false},
+		{name: "admin scope cannot exceed a moderator", scopes: []Scope{ScopeAdmin}, owner: RoleModerator, permission: PermManageUsers, allows: true, can: false},
+		{name: "admin scope reviews for a moderator", scopes: []Scope{ScopeAdmin}, owner: RoleModerator, permission: PermReviewVideos, allows: true, can: true},
+		{name: "admin scope manages settings for an admin", scopes: []Scope{ScopeAdmin}, owner: RoleAdmin, permission: PermManageSettings, allows: true, can: true},
+		{name: "scopes combine", scopes: []Scope{ScopeRead, ScopeUpload}, owner: RoleEditor, permission: PermUploadVideos, allows: true, can: true},
+		{name: "no scopes allow nothing", scopes: nil, owner: RoleAdmin, permission: PermViewVideos, allows: false, can: false},
+		{name: "service read token views", scopes: []Scope{ScopeRead}, permission: PermViewVideos, allows: true, can: true},
+		{name: "service upload token uploads", scopes: []Scope{ScopeUpload}, permission: PermUploadVideos, allows: true, can: true},
+		{name: "service edit token cannot review", scopes: []Scope{ScopeEdit}, permission: PermReviewVideos, allows: false, can: false},
+		{name: "service admin token manages devices", scopes: []Scope{ScopeAdmin}, permission: PermManageDevices, allows: true, can: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &Token{Scopes: tt.scopes}
+			if got := token.Allows(tt.permission); got != tt.allows {
+				t.Errorf("Allows(%s) = %v, want %v", tt.permission, got, tt.allows)
+			}
+
+			ctx := context.Background()
+			if tt.owner != "" {
+				ctx = WithUser(ctx, &User{Role: tt.owner}, nil)
+			}
+			if got := Can(WithToken(ctx, token), tt.permission); got != tt.can {
+				t.Errorf("Can(%s) = %v, want %v", tt.permission, got, tt.can)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gooji/internal/middleware"
+)
+
+// maxUserBodySize bounds user update bodies
+const maxUserBodySize = 4 * 1024
+
+// UserInput is the body of PUT /api/users/{id}; fields left out are unchanged
+type UserInput struct {
+	Role           Role      `json:"role,omitempty"`
+	CommunityRoles *[]string `json:"community_roles,omitempty"`
+	Researcher     *bool     `json:"researcher,omitempty"`
+}
+
+// HandleUsers handles GET /api/users, listing accounts for administrators
+func (h *Handler) HandleUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+	if !h.requirePermission(w, r, PermManageUsers) {
+		return
+	}
+
+	users, err := h.service.ListUsers(r.Context())
+	if err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+
+	infos := make([]UserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, user.Info())
+	}
+	h.writeJSONResponse(w, infos)
+}
+
+// HandleUser handles PUT /api/users/{id}, changing an account's role, community roles or researcher approval
+func (h *Handler) HandleUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+	if !h.requirePermission(w, r, PermManageUsers) {
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/users/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Missing user ID", http.StatusBadRequest)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUserBodySize)
+	var input UserInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		http.Error(w, "Invalid user update", http.StatusBadRequest)
+		return
+	}
+	if input.Role == "" && input.CommunityRoles == nil && input.Researcher == nil {
+		http.Error(w, "Nothing to update: set role, community_roles or researcher", http.StatusBadRequest)
+		return
+	}
+	if input.Role != "" {
+		if _, err := ParseRole(string(input.Role)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if input.CommunityRoles != nil {
+		if _, err := normalizeCommunityRoles(*input.CommunityRoles); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var user *User
+	var err error
+	if input.Role != "" {
+		if user, err = h.service.SetRole(r.Context(), id, input.Role); err == nil {
+			h.logger.Info("%s set role of %s to %s", actorName(r.Context()), user.Username, user.Role)
+		}
+	}
+	if err == nil && input.CommunityRoles != nil {
+		if user, err = h.service.SetCommunityRoles(r.Context(), id, *input.CommunityRoles); err == nil {
+			h.logger.Info("%s set community roles of %s to %v", actorName(r.Context()), user.Username, user.CommunityRoles)
+		}
+	}
+	if err == nil && input.Researcher != nil {
+		if user, err = h.service.SetResearcher(r.Context(), id, *input.Researcher); err == nil {
+			h.logger.Info("%s set researcher of %s to %t", actorName(r.Context()), user.Username, user.Researcher)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		h.handleInternalError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, user.Info())
+}
+
+// requirePermission rejects requests whose login or token lacks a permission.
+// Account and device management are never granted by network location, only by
+// an admin login or an admin-scoped token.
+func (h *Handler) requirePermission(w http.ResponseWriter, r *http.Request, permission Permission) bool {
+	if !Can(r.Context(), permission) {
+		h.logger.Error("Forbidden: %s %s (remote: %s)", r.Method, r.URL.Path, r.RemoteAddr)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// actorName identifies the caller in logs
+func actorName(ctx context.Context) string {
+	if user := UserFromContext(ctx); user != nil {
+		return "User " + user.Username
+	}
+	if token := TokenFromContext(ctx); token != nil {
+		return "Token " + token.ID
+	}
+	return "Anonymous"
+}
+
+// writeJSONResponse writes a JSON response
+func (h *Handler) writeJSONResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -1,13 +1,6 @@
 package config
 
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
-	"strings"
-)
+import "path/filepath"
 
 // Storage holds storage configuration
 type Storage struct {
@@ -17,116 +10,244 @@ type Storage struct {
 	Logs       string `json:"logs"`
 	Thumbnails string `json:"thumbnails"`
 	Metadata   string `json:"metadata"`
+	Captions   string `json:"captions"`
+	Auth       string `json:"auth"`
+	Audit      string `json:"audit"`
 }
 
-// Config holds the application configuration
-type Config struct {
-	Server struct {
-		Port int `json:"port"`
-	} `json:"server"`
-	Storage Storage `json:"storage"`
-	Video   struct {
-		MaxSize      int64    `json:"max_size"`
-		AllowedTypes []string `json:"allowed_types"`
-	} `json:"video"`
-	FFmpeg struct {
-		Path string `json:"path"`
-	} `json:"ffmpeg"`
+// InputLimits holds maximum lengths for user-supplied text fields, counted in characters
+type InputLimits struct {
+	Title       int `json:"title"`
+	Description int `json:"description"`
+	Tag         int `json:"tag"`
+	Keyword     int `json:"keyword"`
 }
 
-// validatePath ensures a file path is secure
-func validatePath(filePath string) error {
-	if filePath == "" {
-		return fmt.Errorf("file path cannot be empty")
-	}
+// Security holds signing and trust configuration
+type Security struct {
+	// SigningKey signs consent records; a key file is generated under the storage base path when empty
+	SigningKey string `json:"signing_key" secret:"true"`
+}
 
-	// Check for path traversal attempts
-	if strings.Contains(filePath, "..") {
-		return fmt.Errorf("path traversal not allowed: %s", filePath)
-	}
+// Auth holds account and session configuration
+type Auth struct {
+	// SessionTTL is how long a login lasts, as a Go duration such as "12h"
+	SessionTTL string `json:"session_ttl"`
+	// SecureCookies marks session cookies Secure even when TLS terminates at a proxy
+	SecureCookies bool `json:"secure_cookies"`
+}
 
-	// Check for dangerous characters
-	dangerousChars := []string{"|", "&", ";", "`", "$", "(", ")", "{", "}", "[", "]", "*", "?", "\\"}
-	for _, char := range dangerousChars {
-		if strings.Contains(filePath, char) {
-			return fmt.Errorf("dangerous character '%s' not allowed in path: %s", char, filePath)
-		}
-	}
+// CORS holds the cross-origin policy for browsers on other sites. With no
+// allowed origins, only same-origin pages may call the API.
+type CORS struct {
+	// AllowedOrigins lists origins such as "https://school.example"; "*" allows any origin without credentials
+	AllowedOrigins []string `json:"allowed_origins"`
+	// AllowedMethods lists methods cross-origin requests may use
+	AllowedMethods []string `json:"allowed_methods"`
+	// AllowedHeaders lists request headers cross-origin requests may send
+	AllowedHeaders []string `json:"allowed_headers"`
+	// AllowCredentials lets cross-origin requests send cookies
+	AllowCredentials bool `json:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response, in seconds; 0 turns caching off
+	MaxAge int `json:"max_age"`
+}
 
-	// For config files, only allow relative paths or paths within current directory
-	if filepath.IsAbs(filePath) {
-		currentDir, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
-		}
+// Headers holds security response header settings
+type Headers struct {
+	// FrameAncestors lists origins allowed to embed pages; empty means none
+	FrameAncestors []string `json:"frame_ancestors"`
+	// ReferrerPolicy is sent as the Referrer-Policy header
+	ReferrerPolicy string `json:"referrer_policy"`
+	// HSTSMaxAge is the Strict-Transport-Security max-age, in seconds, sent on TLS requests;
+	// 0 tells browsers to forget a policy sent earlier
+	HSTSMaxAge int `json:"hsts_max_age"`
+	// StyleSources and FontSources extend the content security policy for stylesheet and font CDNs
+	StyleSources []string `json:"style_sources"`
+	FontSources  []string `json:"font_sources"`
+}
 
-		absFilePath, err := filepath.Abs(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to resolve file path: %w", err)
-		}
+// RateLimit is a token bucket per client: PerMinute requests refill it up to Burst
+type RateLimit struct {
+	PerMinute float64 `json:"per_minute"`
+	Burst     int     `json:"burst"`
+}
 
-		if !strings.HasPrefix(absFilePath, currentDir) {
-			return fmt.Errorf("config file path %s is outside current directory %s", absFilePath, currentDir)
-		}
-	}
+// RateLimits holds request rate limits. Clients are keyed by account, API
+// token or paired device when known, and by IP address otherwise.
+type RateLimits struct {
+	Enabled bool `json:"enabled"`
+	// Upload limits video uploads
+	Upload RateLimit `json:"upload"`
+	// Read limits every other request
+	Read RateLimit `json:"read"`
+	// ExemptPaths lists path prefixes that are never limited, such as static files
+	ExemptPaths []string `json:"exempt_paths"`
+}
 
-	return nil
+// Quotas holds storage limits, in bytes. Zero leaves a limit off.
+type Quotas struct {
+	// MinFreeBytes is the low watermark: uploads that would leave less free
+	// disk space than this are refused. Zero or a negative value turns the check off.
+	MinFreeBytes int64 `json:"min_free_bytes"`
+	// MaxStorageBytes caps the total size of the storage directories
+	MaxStorageBytes int64 `json:"max_storage_bytes"`
+	// PerUserBytes caps the videos each account may upload
+	PerUserBytes int64 `json:"per_user_bytes"`
+	// PerDeviceBytes caps the videos each paired kiosk may upload
+	PerDeviceBytes int64 `json:"per_device_bytes"`
 }
 
-// Load reads the configuration from a JSON file and environment variables
-func Load(path string) (*Config, error) {
-	// Validate config path
-	if err := validatePath(path); err != nil {
-		return nil, fmt.Errorf("invalid config path: %w", err)
-	}
+// RetentionRule expires videos older than MaxAgeDays that match every
+// condition it sets; within one condition, any listed value matches
+type RetentionRule struct {
+	// Name identifies the rule in reports and the audit log
+	Name       string `json:"name"`
+	MaxAgeDays int    `json:"max_age_days"`
+	// Tags, Collections (by ID), AccessLevels, ConsentScopes and ReviewStates narrow the rule
+	Tags          []string `json:"tags"`
+	Collections   []string `json:"collections"`
+	AccessLevels  []string `json:"access_levels"`
+	ConsentScopes []string `json:"consent_scopes"`
+	ReviewStates  []string `json:"review_states"`
+	// MatchAll must be set on a rule without conditions, which expires every video past its age
+	MatchAll bool `json:"match_all"`
+}
 
-	// Convert relative path to absolute path if needed
-	if !filepath.IsAbs(path) {
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve config path: %w", err)
-		}
-		path = absPath
-	}
+// Retention holds the background janitor's schedule and rules
+type Retention struct {
+	Enabled bool `json:"enabled"`
+	// Interval is how often the janitor runs, as a Go duration
+	Interval string `json:"interval"`
+	// StaleAfter is how old temp files and uploads without metadata must be before they are removed, as a Go duration
+	StaleAfter string `json:"stale_after"`
+	// DryRun reports what would be removed without removing anything
+	DryRun bool            `json:"dry_run"`
+	Rules  []RetentionRule `json:"rules"`
+}
 
-	// Additional security check: ensure no path traversal
-	if strings.Contains(path, "..") {
-		return nil, fmt.Errorf("path traversal not allowed in config path: %s", path)
-	}
+// Health holds readiness check settings
+type Health struct {
+	// Timeout bounds each check, as a Go duration
+	Timeout string `json:"timeout"`
+	// FFmpegInterval is how long an FFmpeg probe result is reused, as a Go duration
+	FFmpegInterval string `json:"ffmpeg_interval"`
+	// RequiredEncoders lists FFmpeg encoders needed for thumbnails and caption burn-in
+	RequiredEncoders []string `json:"required_encoders"`
+	// MaxQueue is how many FFmpeg jobs may wait for a slot before the server reports not ready
+	MaxQueue int `json:"max_queue"`
+}
 
-	// Final security check: validate path is safe before opening
-	if err := validatePath(path); err != nil {
-		return nil, fmt.Errorf("config path validation failed: %w", err)
-	}
+// Metrics holds the Prometheus endpoint settings
+type Metrics struct {
+	Enabled bool `json:"enabled"`
+	// Networks lists CIDR ranges that may scrape /metrics without signing in;
+	// others need a moderator or admin account or token
+	Networks []string `json:"networks"`
+}
 
-	file, err := os.Open(path) //nolint:gosec // Path validated above
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
-	}
-	defer file.Close()
+// LogSink holds one log output's settings
+type LogSink struct {
+	// Level is debug, info, warn or error, or off to turn the sink off
+	Level string `json:"level"`
+	// Format is text for people reading a terminal or json for log shippers
+	Format string `json:"format"`
+}
 
-	var config Config
-	if err := json.NewDecoder(file).Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
-	}
+// LogRotation holds how log files are split and pruned
+type LogRotation struct {
+	// MaxSizeMB starts a new file once the current one reaches this size; files also start anew each day
+	MaxSizeMB int `json:"max_size_mb"`
+	// MaxFiles is how many old files are kept; 0 keeps none and a negative value keeps them all
+	MaxFiles int `json:"max_files"`
+	// MaxAgeDays removes old files after this many days; 0 removes them at once and a negative value keeps them
+	MaxAgeDays int `json:"max_age_days"`
+	// Compress gzips files once they are rotated
+	Compress bool `json:"compress"`
+}
+
+// Logging holds the console and file log outputs. Levels can be changed
+// while running through /api/logging.
+type Logging struct {
+	Console  LogSink     `json:"console"`
+	File     LogSink     `json:"file"`
+	Rotation LogRotation `json:"rotation"`
+}
+
+// Tracing holds OpenTelemetry trace export settings
+type Tracing struct {
+	Enabled bool `json:"enabled"`
+	// Exporter is otlp to send spans to a collector, or stdout or file for offline kiosks
+	Exporter string `json:"exporter"`
+	// Endpoint is the OTLP/HTTP collector as host:port or a URL
+	Endpoint string `json:"endpoint"`
+	// Insecure sends spans to the collector over plain HTTP
+	Insecure bool `json:"insecure"`
+	// Headers are sent with every export, such as a collector API key
+	Headers map[string]string `json:"headers" secret:"true"`
+	// File receives spans as JSON lines when Exporter is file
+	File string `json:"file"`
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1
+	SampleRatio float64 `json:"sample_ratio"`
+	// ServiceName identifies this server in the tracing backend
+	ServiceName string `json:"service_name"`
+}
+
+// Kiosk holds configuration for the on-site recording kiosk
+type Kiosk struct {
+	// Networks lists CIDR ranges whose requests are treated as coming from the kiosk
+	Networks []string `json:"networks"`
+	// ModeratorNetworks lists CIDR ranges whose requests may review videos
+	ModeratorNetworks []string `json:"moderator_networks"`
+}
+
+// Config holds the application configuration
+type Config struct {
+	Server struct {
+		Port int `json:"port"`
+	} `json:"server"`
+	Storage Storage `json:"storage"`
+	Video   struct {
+		MaxSize      int64       `json:"max_size"`
+		AllowedTypes []string    `json:"allowed_types"`
+		Limits       InputLimits `json:"limits"`
+	} `json:"video"`
+	FFmpeg struct {
+		Path string `json:"path"`
+		// MaxConcurrent caps FFmpeg processes running at once
+		MaxConcurrent int `json:"max_concurrent"`
+		// QueueTimeout is how long work waits for a free FFmpeg slot, as a Go duration
+		QueueTimeout string `json:"queue_timeout"`
+	} `json:"ffmpeg"`
+	Security   Security   `json:"security"`
+	Kiosk      Kiosk      `json:"kiosk"`
+	Auth       Auth       `json:"auth"`
+	CORS       CORS       `json:"cors"`
+	Headers    Headers    `json:"headers"`
+	RateLimits RateLimits `json:"rate_limits"`
+	Quotas     Quotas     `json:"quotas"`
+	Retention  Retention  `json:"retention"`
+	Health     Health     `json:"health"`
+	Metrics    Metrics    `json:"metrics"`
+	Logging    Logging    `json:"logging"`
+	Tracing    Tracing    `json:"tracing"`
 
-	// Set defaults if not specified
-	if config.Server.Port == 0 {
+	// sources records which layer set each setting, by path
+	sources map[string]string
+	// file is the configuration file read, if any
+	file string
+}
+
+// applyDefaults fills settings that no layer set. Numbers and lists keep a zero
+// or empty value a layer set, which validation rejects where it is not allowed;
+// empty strings always take the default. debug lowers the default log levels,
+// as APP_DEBUG=true always has.
+func applyDefaults(config *Config, debug bool) {
+	if !config.isSet("server.port") {
 		config.Server.Port = 8080
 	}
 	if config.Storage.BasePath == "" {
 		config.Storage.BasePath = "storage"
 	}
-
-	// Override with environment variables if set
-	if port := os.Getenv("GOOJI_PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			config.Server.Port = p
-		}
-	}
-
-	// Note: We can't use the logger here as it's not available yet
-	// Environment variable debug info will be logged by the main logger
 	if config.Storage.Uploads == "" {
 		config.Storage.Uploads = "storage/uploads"
 	}
@@ -142,15 +263,146 @@ func Load(path string) (*Config, error) {
 	if config.Storage.Metadata == "" {
 		config.Storage.Metadata = "storage/metadata"
 	}
-	if config.Video.MaxSize == 0 {
+	if config.Storage.Captions == "" {
+		config.Storage.Captions = "storage/captions"
+	}
+	if config.Storage.Auth == "" {
+		config.Storage.Auth = "storage/auth"
+	}
+	if config.Storage.Audit == "" {
+		config.Storage.Audit = "storage/audit"
+	}
+	if !config.isSet("video.max_size") {
 		config.Video.MaxSize = 100 * 1024 * 1024 // 100MB
 	}
-	if len(config.Video.AllowedTypes) == 0 {
-		config.Video.AllowedTypes = []string{"video/mp4", "video/webm"}
+	if !config.isSet("video.allowed_types") {
+		config.Video.AllowedTypes = []string{"video/mp4", "video/webm", "video/avi", "video/mov"}
+	}
+	if !config.isSet("video.limits.title") {
+		config.Video.Limits.Title = 200
+	}
+	if !config.isSet("video.limits.description") {
+		config.Video.Limits.Description = 5000
+	}
+	if !config.isSet("video.limits.tag") {
+		config.Video.Limits.Tag = 50
+	}
+	if !config.isSet("video.limits.keyword") {
+		config.Video.Limits.Keyword = 100
 	}
 	if config.FFmpeg.Path == "" {
 		config.FFmpeg.Path = "ffmpeg"
 	}
-
-	return &config, nil
+	if !config.isSet("ffmpeg.max_concurrent") {
+		config.FFmpeg.MaxConcurrent = 2
+	}
+	if config.FFmpeg.QueueTimeout == "" {
+		config.FFmpeg.QueueTimeout = "30s"
+	}
+	if !config.isSet("kiosk.networks") {
+		config.Kiosk.Networks = []string{"127.0.0.1/32", "::1/128"}
+	}
+	if config.Auth.SessionTTL == "" {
+		config.Auth.SessionTTL = "12h"
+	}
+	if !config.isSet("cors.allowed_methods") {
+		config.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "DELETE"}
+	}
+	if !config.isSet("cors.allowed_headers") {
+		config.CORS.AllowedHeaders = []string{"Content-Type", "Authorization", "X-CSRF-Token"}
+	}
+	if !config.isSet("cors.max_age") {
+		config.CORS.MaxAge = 600
+	}
+	if config.Headers.ReferrerPolicy == "" {
+		config.Headers.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+	if !config.isSet("headers.hsts_max_age") {
+		config.Headers.HSTSMaxAge = 365 * 24 * 60 * 60
+	}
+	if !config.isSet("headers.style_sources") {
+		config.Headers.StyleSources = []string{"https://cdn.jsdelivr.net", "https://fonts.googleapis.com"}
+	}
+	if !config.isSet("headers.font_sources") {
+		config.Headers.FontSources = []string{"https://fonts.gstatic.com"}
+	}
+	if !config.isSet("rate_limits.upload.per_minute") {
+		config.RateLimits.Upload.PerMinute = 6
+	}
+	if !config.isSet("rate_limits.upload.burst") {
+		config.RateLimits.Upload.Burst = 3
+	}
+	if !config.isSet("rate_limits.read.per_minute") {
+		config.RateLimits.Read.PerMinute = 300
+	}
+	if !config.isSet("rate_limits.read.burst") {
+		config.RateLimits.Read.Burst = 100
+	}
+	if !config.isSet("rate_limits.exempt_paths") {
+		config.RateLimits.ExemptPaths = []string{"/static/", "/health", "/livez", "/readyz", "/metrics"}
+	}
+	if !config.isSet("quotas.min_free_bytes") {
+		config.Quotas.MinFreeBytes = 1 << 30 // 1GB
+	}
+	if config.Retention.Interval == "" {
+		config.Retention.Interval = "6h"
+	}
+	if config.Retention.StaleAfter == "" {
+		config.Retention.StaleAfter = "24h"
+	}
+	if config.Health.Timeout == "" {
+		config.Health.Timeout = "2s"
+	}
+	if config.Health.FFmpegInterval == "" {
+		config.Health.FFmpegInterval = "1m"
+	}
+	if !config.isSet("health.required_encoders") {
+		config.Health.RequiredEncoders = []string{"libx264", "mjpeg"}
+	}
+	if !config.isSet("health.max_queue") {
+		config.Health.MaxQueue = 4
+	}
+	if !config.isSet("metrics.networks") {
+		config.Metrics.Networks = []string{"127.0.0.1/32", "::1/128"}
+	}
+	defaultLevel := "info"
+	if debug {
+		defaultLevel = "debug"
+	}
+	if config.Logging.Console.Level == "" {
+		config.Logging.Console.Level = defaultLevel
+	}
+	if config.Logging.Console.Format == "" {
+		config.Logging.Console.Format = "text"
+	}
+	if config.Logging.File.Level == "" {
+		config.Logging.File.Level = defaultLevel
+	}
+	if config.Logging.File.Format == "" {
+		config.Logging.File.Format = "json"
+	}
+	if !config.isSet("logging.rotation.max_size_mb") {
+		config.Logging.Rotation.MaxSizeMB = 50
+	}
+	if !config.isSet("logging.rotation.max_files") {
+		config.Logging.Rotation.MaxFiles = 30
+	}
+	if !config.isSet("logging.rotation.max_age_days") {
+		config.Logging.Rotation.MaxAgeDays = 30
+	}
+	if config.Tracing.Exporter == "" {
+		config.Tracing.Exporter = "otlp"
+	}
+	if config.Tracing.Endpoint == "" {
+		config.Tracing.Endpoint = "localhost:4318"
+	}
+	if config.Tracing.File == "" {
+		config.Tracing.File = filepath.Join(config.Storage.Logs, "traces.jsonl")
+	}
+	if !config.isSet("tracing.sample_ratio") {
+		config.Tracing.SampleRatio = 1
+	}
+	if config.Tracing.ServiceName == "" {
+		config.Tracing.ServiceName = "gooji"
+	}
 }
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a JSON file setting one dotted path to value
+func writeConfig(t *testing.T, path string, value interface{}) string {
+	t.Helper()
+	tree := map[string]interface{}{}
+	node := tree
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child := map[string]interface{}{}
+		node[part] = child
+		node = child
+	}
+	node[parts[len(parts)-1]] = value
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("encode config: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return file
+}
+
+// effective returns a setting's printed value and source
+func effective(t *testing.T, c *Config, path string) (string, string) {
+	t.Helper()
+	for _, e := range c.Effective() {
+		if e.Path == path {
+			return formatValue(e.Value), e.Source
+		}
+	}
+	t.Fatalf("no setting %s", path)
+	return "", ""
+}
+
+func TestExplicitZeroSurvivesDefaults(t *testing.T) {
+	tests := []struct {
+		path    string
+		zero    interface{}
+		want    string
+		invalid bool
+	}{
+		{path: "server.port", zero: 0, invalid: true},
+		{path: "video.max_size", zero: 0, invalid: true},
+		{path: "video.allowed_types", zero: []string{}, invalid: true},
+		{path: "video.limits.title", zero: 0, invalid: true},
+		{path: "video.limits.description", zero: 0, invalid: true},
+		{path: "video.limits.tag", zero: 0, invalid: true},
+		{path: "video.limits.keyword", zero: 0, invalid: true},
+		{path: "ffmpeg.max_concurrent", zero: 0, invalid: true},
+		{path: "kiosk.networks", zero: []string{}, want: "[]"},
+		{path: "cors.allowed_methods", zero: []string{}, want: "[]"},
+		{path: "cors.allowed_headers", zero: []string{}, want: "[]"},
+		{path: "cors.max_age", zero: 0, want: "0"},
+		{path: "headers.hsts_max_age", zero: 0, want: "0"},
+		{path: "headers.style_sources", zero: []string{}, want: "[]"},
+		{path: "headers.font_sources", zero: []string{}, want: "[]"},
+		{path: "rate_limits.upload.per_minute", zero: 0, invalid: true},
+		{path: "rate_limits.upload.burst", zero: 0, invalid: true},
+		{path: "rate_limits.read.per_minute", zero: 0, invalid: true},
+		{path: "rate_limits.read.burst", zero: 0, invalid: true},
+		{path: "rate_limits.exempt_paths", zero: []string{}, want: "[]"},
+		{path: "quotas.min_free_bytes", zero: 0, want: "0"},
+		{path: "health.required_encoders", zero: []string{}, want: "[]"},
+		{path: "health.max_queue", zero: 0, want: "0"},
+		{path: "metrics.networks", zero: []string{}, want: "[]"},
+		{path: "logging.rotation.max_size_mb", zero: 0, invalid: true},
+		{path: "logging.rotation.max_files", zero: 0, want: "0"},
+		{path: "logging.rotation.max_age_days", zero: 0, want: "0"},
+		{path: "tracing.sample_ratio", zero: 0, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file := writeConfig(t, tt.path, tt.zero)
+			config, err := Load(Options{Path: file})
+			if tt.invalid {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.path+":") {
+					t.Fatalf("Load error = %v, want a validation error for %s", err, tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			value, source := effective(t, config, tt.path)
+			if value != tt.want || source != "file "+file {
+				t.Errorf("%s = %s from %s, want %s from file %s", tt.path, value, source, tt.want, file)
+			}
+
+			defaults, err := Load(Options{Path: writeConfig(t, "server.port", 8080)})
+			if err != nil {
+				t.Fatalf("Load defaults: %v", err)
+			}
+			value, source = effective(t, defaults, tt.path)
+			if value == tt.want || source != SourceDefault {
+				t.Errorf("unset %s = %s from %s, want its default", tt.path, value, source)
+			}
+		})
+	}
+}
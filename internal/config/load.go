@@ -0,0 +1,417 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultPath is read when no file is named, if it exists
+	DefaultPath = "config/config.json"
+	// envPrefix starts the environment variable of every setting, as in GOOJI_SERVER_PORT
+	envPrefix = "GOOJI_"
+	// envConfig names the configuration file when -config is not given
+	envConfig = "GOOJI_CONFIG"
+	// SourceDefault marks settings no layer set
+	SourceDefault = "default"
+)
+
+// envAliases are older variable names still honoured; the full names win
+var envAliases = map[string]string{
+	"GOOJI_PORT":        "server.port",
+	"GOOJI_SIGNING_KEY": "security.signing_key",
+}
+
+// Override is one command-line setting, applied after the file and environment
+type Override struct {
+	Path  string
+	Value string
+}
+
+// Options selects the configuration file and the layers applied over it
+type Options struct {
+	// Path is the configuration file, in JSON, YAML or TOML by extension.
+	// Empty uses GOOJI_CONFIG, then DefaultPath if it exists.
+	Path string
+	// Environ is the environment as from os.Environ
+	Environ []string
+	// Overrides are command-line settings, in the order given
+	Overrides []Override
+}
+
+// RegisterFlags adds -config and a flag for every setting, named by its path
+// as in -server.port or -rate_limits.upload.burst, to fs. The returned
+// options are filled in when fs is parsed.
+func RegisterFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{}
+	fs.StringVar(&opts.Path, "config", "", "configuration file (JSON, YAML or TOML); defaults to $"+envConfig+" or "+DefaultPath)
+	for _, f := range settings(&Config{}) {
+		fs.Var(&overrideFlag{path: f.path, opts: opts}, f.path, "set "+f.path+" ("+f.kind()+")")
+	}
+	return opts
+}
+
+// overrideFlag records a setting given on the command line
+type overrideFlag struct {
+	path string
+	opts *Options
+}
+
+// String returns "" so flag help shows no default
+func (f *overrideFlag) String() string { return "" }
+
+// Set records the override; it is checked when the configuration loads
+func (f *overrideFlag) Set(value string) error {
+	f.opts.Overrides = append(f.opts.Overrides, Override{Path: f.path, Value: value})
+	return nil
+}
+
+// Load builds the configuration in layers: defaults, then the file, then
+// GOOJI_* environment variables, then command-line overrides. It records
+// where each setting came from and rejects invalid configurations with every
+// problem it finds.
+func Load(opts Options) (*Config, error) {
+	env := environ(opts.Environ)
+	config := &Config{sources: make(map[string]string)}
+	fields := settings(config)
+
+	path, required := opts.Path, opts.Path != ""
+	if path == "" {
+		path, required = env[envConfig], env[envConfig] != ""
+	}
+	if path == "" {
+		path = DefaultPath
+	}
+	if err := loadFile(config, fields, path, required); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnv(config, fields, env); err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]setting, len(fields))
+	for _, f := range fields {
+		byPath[f.path] = f
+	}
+	for _, o := range opts.Overrides {
+		f, ok := byPath[o.Path]
+		if !ok {
+			return nil, fmt.Errorf("unknown setting %q", o.Path)
+		}
+		if err := f.set(o.Value); err != nil {
+			return nil, fmt.Errorf("flag -%s: %w", o.Path, err)
+		}
+		config.sources[f.path] = "flag -" + f.path
+	}
+
+	applyDefaults(config, env["APP_DEBUG"] == "true")
+	for _, f := range fields {
+		if _, ok := config.sources[f.path]; !ok {
+			config.sources[f.path] = SourceDefault
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Source returns the layer that set a setting, such as "default",
+// "file config/config.json", "env GOOJI_SERVER_PORT" or "flag -server.port"
+func (c *Config) Source(path string) string {
+	if source, ok := c.sources[path]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// isSet reports whether a layer set a setting, so a zero it set is kept over the default
+func (c *Config) isSet(path string) bool {
+	_, ok := c.sources[path]
+	return ok
+}
+
+// File returns the configuration file that was read, or "" if there was none
+func (c *Config) File() string {
+	return c.file
+}
+
+// loadFile decodes the file at path over config. A missing file is an error
+// only when it was named explicitly.
+func loadFile(config *Config, fields []setting, path string, required bool) error {
+	path = filepath.Clean(path)
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid config path: %w", err)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path validated above
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &tree)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("unsupported config file type %q: use .json, .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	// Every format goes through JSON so the struct's json names apply to all of them
+	normalized, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(normalized))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("%s: %s: expected %s, got %s", path, typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("%s: unknown setting %s", path, name)
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	config.file = path
+	for _, f := range fields {
+		if hasPath(tree, f.path) {
+			config.sources[f.path] = "file " + path
+		}
+	}
+	return nil
+}
+
+// applyEnv sets every setting that has a GOOJI_* variable, aliases first
+func applyEnv(config *Config, fields []setting, env map[string]string) error {
+	aliases := make([]string, 0, len(envAliases))
+	for name := range envAliases {
+		aliases = append(aliases, name)
+	}
+	sort.Strings(aliases)
+
+	byPath := make(map[string]setting, len(fields))
+	for _, f := range fields {
+		byPath[f.path] = f
+	}
+	for _, name := range aliases {
+		value, ok := env[name]
+		if !ok {
+			continue
+		}
+		f := byPath[envAliases[name]]
+		if err := f.set(value); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+		config.sources[f.path] = "env " + name
+	}
+
+	for _, f := range fields {
+		name := f.envName()
+		value, ok := env[name]
+		if !ok {
+			continue
+		}
+		if err := f.set(value); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+		config.sources[f.path] = "env " + name
+	}
+	return nil
+}
+
+// environ turns KEY=value pairs into a map
+func environ(pairs []string) map[string]string {
+	env := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// hasPath reports whether a decoded file sets the dotted path
+func hasPath(tree map[string]interface{}, path string) bool {
+	node := tree
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, ok := node[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if node, ok = value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// validatePath rejects traversal and shell metacharacters in the config file path
+func validatePath(filePath string) error {
+	if filePath == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+
+	// Check for path traversal attempts
+	if strings.Contains(filePath, "..") {
+		return fmt.Errorf("path traversal not allowed: %s", filePath)
+	}
+
+	// Check for dangerous characters
+	dangerousChars := []string{"|", "&", ";", "`", "$", "(", ")", "{", "}", "[", "]", "*", "?", "\\"}
+	for _, char := range dangerousChars {
+		if strings.Contains(filePath, char) {
+			return fmt.Errorf("dangerous character '%s' not allowed in path: %s", char, filePath)
+		}
+	}
+	return nil
+}
+
+// setting is one leaf of the configuration, addressed by its dotted json path
+type setting struct {
+	path   string
+	value  reflect.Value
+	secret bool
+}
+
+// settings lists every leaf setting of config in declaration order
+func settings(config *Config) []setting {
+	var fields []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			path := prefix + name
+			if field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			fields = append(fields, setting{path: path, value: v.Field(i), secret: field.Tag.Get("secret") == "true"})
+		}
+	}
+	walk(reflect.ValueOf(config).Elem(), "")
+	return fields
+}
+
+// envName returns the setting's environment variable, as in GOOJI_RATE_LIMITS_UPLOAD_BURST
+func (s setting) envName() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.path, ".", "_"))
+}
+
+// kind describes the value a setting takes, for flag help
+func (s setting) kind() string {
+	switch s.value.Kind() {
+	case reflect.Slice:
+		if s.value.Type().Elem().Kind() == reflect.String {
+			return "comma-separated list"
+		}
+		return "JSON array"
+	case reflect.Map:
+		return "comma-separated key=value pairs"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	}
+	return s.value.Kind().String()
+}
+
+// set parses a flag or environment value into the setting. Lists are
+// comma-separated, maps are key=value pairs, and other structures are JSON.
+func (s setting) set(raw string) error {
+	v := s.value
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false, got %q", s.path, raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: expected an integer, got %q", s.path, raw)
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s: expected a number, got %q", s.path, raw)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return s.setJSON(raw)
+		}
+		list := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	case reflect.Map:
+		pairs := make(map[string]string)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%s: expected key=value pairs, got %q", s.path, item)
+			}
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		v.Set(reflect.ValueOf(pairs))
+	default:
+		return s.setJSON(raw)
+	}
+	return nil
+}
+
+// setJSON decodes a JSON value into the setting
+func (s setting) setJSON(raw string) error {
+	target := reflect.New(s.value.Type())
+	if err := json.Unmarshal([]byte(raw), target.Interface()); err != nil {
+		return fmt.Errorf("%s: expected JSON: %w", s.path, err)
+	}
+	s.value.Set(target.Elem())
+	return nil
+}
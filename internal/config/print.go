@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+// redacted replaces secret values in printed configurations
+const redacted = "<redacted>"
+
+// Effective is one setting's value and the layer that set it
+type Effective struct {
+	Path   string      `json:"path"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// Effective lists every setting with its value and source. Secrets such as
+// the signing key are redacted when set.
+func (c *Config) Effective() []Effective {
+	fields := settings(c)
+	values := make([]Effective, 0, len(fields))
+	for _, f := range fields {
+		var value interface{} = f.value.Interface()
+		if f.secret && !f.value.IsZero() {
+			value = redacted
+		}
+		values = append(values, Effective{Path: f.path, Value: value, Source: c.Source(f.path)})
+	}
+	return values
+}
+
+// Print writes the effective configuration as a table, or as JSON when asJSON is set
+func (c *Config) Print(w io.Writer, asJSON bool) error {
+	values := c.Effective()
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, v := range values {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Path, formatValue(v.Value), v.Source)
+	}
+	return tw.Flush()
+}
+
+// formatValue renders a value compactly as JSON, leaving nil lists and maps as []/{}
+func formatValue(value interface{}) string {
+	if value == redacted {
+		return redacted
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return "[]"
+	}
+	if rv.Kind() == reflect.Map && rv.IsNil() {
+		return "{}"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
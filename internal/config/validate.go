@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error reports the problems one per line
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// validator collects problems, naming the setting and the layer that set it
+type validator struct {
+	config   *Config
+	problems []string
+}
+
+// fail records a problem with a setting
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf("%s: %s (from %s)", path, fmt.Sprintf(format, args...), v.config.Source(path)))
+}
+
+// duration checks a positive Go duration such as "30s"
+func (v *validator) duration(path, value string) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.fail(path, "%q is not a duration; use a value such as \"30s\", \"15m\" or \"12h\"", value)
+		return
+	}
+	if d <= 0 {
+		v.fail(path, "must be positive, got %s", value)
+	}
+}
+
+// atLeast checks a lower bound on an integer setting
+func (v *validator) atLeast(path string, value, min int64) {
+	if value < min {
+		v.fail(path, "must be at least %d, got %d", min, value)
+	}
+}
+
+// networks checks a list of CIDR ranges
+func (v *validator) networks(path string, cidrs []string) {
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			v.fail(path, "%q is not a CIDR range such as \"192.168.1.0/24\"", cidr)
+		}
+	}
+}
+
+// oneOf checks a setting against its allowed values
+func (v *validator) oneOf(path, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.fail(path, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+// Validate checks every setting and returns a ValidationError listing each problem
+func (c *Config) Validate() error {
+	v := &validator{config: c}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		v.fail("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+
+	v.atLeast("video.max_size", c.Video.MaxSize, 1)
+	if len(c.Video.AllowedTypes) == 0 {
+		v.fail("video.allowed_types", "must list at least one type, such as video/mp4")
+	}
+	for _, t := range c.Video.AllowedTypes {
+		if !strings.HasPrefix(t, "video/") {
+			v.fail("video.allowed_types", "%q is not a video MIME type such as video/mp4", t)
+		}
+	}
+	v.atLeast("video.limits.title", int64(c.Video.Limits.Title), 1)
+	v.atLeast("video.limits.description", int64(c.Video.Limits.Description), 1)
+	v.atLeast("video.limits.tag", int64(c.Video.Limits.Tag), 1)
+	v.atLeast("video.limits.keyword", int64(c.Video.Limits.Keyword), 1)
+
+	v.atLeast("ffmpeg.max_concurrent", int64(c.FFmpeg.MaxConcurrent), 1)
+	v.duration("ffmpeg.queue_timeout", c.FFmpeg.QueueTimeout)
+
+	v.networks("kiosk.networks", c.Kiosk.Networks)
+	v.networks("kiosk.moderator_networks", c.Kiosk.ModeratorNetworks)
+	v.duration("auth.session_ttl", c.Auth.SessionTTL)
+	v.atLeast("cors.max_age", int64(c.CORS.MaxAge), 0)
+	v.atLeast("headers.hsts_max_age", int64(c.Headers.HSTSMaxAge), 0)
+
+	if c.RateLimits.Upload.PerMinute <= 0 {
+		v.fail("rate_limits.upload.per_minute", "must be positive, got %g", c.RateLimits.Upload.PerMinute)
+	}
+	v.atLeast("rate_limits.upload.burst", int64(c.RateLimits.Upload.Burst), 1)
+	if c.RateLimits.Read.PerMinute <= 0 {
+		v.fail("rate_limits.read.per_minute", "must be positive, got %g", c.RateLimits.Read.PerMinute)
+	}
+	v.atLeast("rate_limits.read.burst", int64(c.RateLimits.Read.Burst), 1)
+
+	v.atLeast("quotas.max_storage_bytes", c.Quotas.MaxStorageBytes, 0)
+	v.atLeast("quotas.per_user_bytes", c.Quotas.PerUserBytes, 0)
+	v.atLeast("quotas.per_device_bytes", c.Quotas.PerDeviceBytes, 0)
+
+	v.duration("retention.interval", c.Retention.Interval)
+	v.duration("retention.stale_after", c.Retention.StaleAfter)
+
+	v.duration("health.timeout", c.Health.Timeout)
+	v.duration("health.ffmpeg_interval", c.Health.FFmpegInterval)
+	v.atLeast("health.max_queue", int64(c.Health.MaxQueue), 0)
+	v.networks("metrics.networks", c.Metrics.Networks)
+
+	levels := []string{"debug", "info", "warn", "error", "off"}
+	formats := []string{"text", "json"}
+	v.oneOf("logging.console.level", c.Logging.Console.Level, levels...)
+	v.oneOf("logging.console.format", c.Logging.Console.Format, formats...)
+	v.oneOf("logging.file.level", c.Logging.File.Level, levels...)
+	v.oneOf("logging.file.format", c.Logging.File.Format, formats...)
+	v.atLeast("logging.rotation.max_size_mb", int64(c.Logging.Rotation.MaxSizeMB), 1)
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "otlp", "stdout", "file")
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.fail("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
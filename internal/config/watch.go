@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// settleTime lets an editor finish writing the file before it is reloaded
+const settleTime = 500 * time.Millisecond
+
+// reloadable lists the settings applied while running, by path or path
+// prefix; changes to any other setting take effect on restart
+var reloadable = []string{
+	"video.max_size",
+	"video.allowed_types",
+	"ffmpeg.max_concurrent",
+	"ffmpeg.queue_timeout",
+	"rate_limits.",
+	"logging.console.level",
+	"logging.file.level",
+}
+
+// Reloadable reports whether a setting is applied without a restart
+func Reloadable(path string) bool {
+	for _, r := range reloadable {
+		if path == r || (strings.HasSuffix(r, ".") && strings.HasPrefix(path, r)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Changed lists the settings whose values differ between two configurations
+func Changed(prev, next *Config) []string {
+	before := settings(prev)
+	after := settings(next)
+	var changed []string
+	for i, f := range after {
+		if !reflect.DeepEqual(jsonValue(before[i].value), jsonValue(f.value)) {
+			changed = append(changed, f.path)
+		}
+	}
+	return changed
+}
+
+// jsonValue compares settings as they are written, so nil and empty lists are equal
+func jsonValue(v reflect.Value) string {
+	data, err := json.Marshal(v.Interface())
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	if s := string(data); s == "[]" || s == "{}" {
+		return ""
+	}
+	return string(data)
+}
+
+// ApplyFunc swaps the reloadable settings of next into the running services
+type ApplyFunc func(prev, next *Config) error
+
+// ReportFunc is told the outcome of each reload: the settings that changed
+// or the reason the new configuration was rejected
+type ReportFunc func(trigger string, changed []string, err error)
+
+// Watcher reloads the configuration on SIGHUP and when its file changes.
+// Invalid configurations are rejected and the current one kept.
+type Watcher struct {
+	opts    Options
+	apply   ApplyFunc
+	mu      sync.Mutex
+	current *Config
+}
+
+// NewWatcher creates a watcher that reloads with the options current was
+// loaded with, so environment and command-line overrides still apply
+func NewWatcher(opts Options, current *Config, apply ApplyFunc) *Watcher {
+	return &Watcher{opts: opts, apply: apply, current: current}
+}
+
+// Current returns the configuration last applied
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Reload loads and validates the configuration again and applies it,
+// returning the settings that changed
+func (w *Watcher) Reload() ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := Load(w.opts)
+	if err != nil {
+		return nil, err
+	}
+	changed := Changed(w.current, next)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	if err := w.apply(w.current, next); err != nil {
+		return nil, fmt.Errorf("failed to apply configuration: %w", err)
+	}
+	w.current = next
+	return changed, nil
+}
+
+// Run reloads on SIGHUP and on changes to the configuration file until ctx
+// is done. The file's directory is watched so editors that replace the file
+// are noticed.
+func (w *Watcher) Run(ctx context.Context, report ReportFunc) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	path := w.Current().File()
+	if path != "" {
+		files, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to watch config file: %w", err)
+		}
+		defer files.Close()
+		if err := files.Add(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+		}
+		events, errs = files.Events, files.Errors
+	}
+
+	settle := time.NewTimer(settleTime)
+	settle.Stop()
+	defer settle.Stop()
+
+	reload := func(trigger string) {
+		changed, err := w.Reload()
+		report(trigger, changed, err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			reload("SIGHUP")
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) == path && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				settle.Reset(settleTime)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			report("file watch", nil, err)
+		case <-settle.C:
+			reload("file change")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gooji/internal/storage"
+	"gooji/pkg/ffmpeg"
+)
+
+// Cached reuses a check's outcome for ttl, for checks too costly to run on
+// every probe. Only one caller runs the check at a time.
+func Cached(check Check, ttl time.Duration) Check {
+	var mu sync.Mutex
+	var last error
+	var checkedAt time.Time
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checkedAt.IsZero() && time.Since(checkedAt) < ttl {
+			return last
+		}
+		last = check(ctx)
+		checkedAt = time.Now()
+		return last
+	}
+}
+
+// FFmpeg checks that the configured FFmpeg runs and has the required encoders
+func FFmpeg(processor *ffmpeg.Processor, encoders []string) Check {
+	return func(ctx context.Context) error {
+		caps, err := processor.Probe(ctx)
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, encoder := range encoders {
+			if !caps.Encoders[encoder] {
+				missing = append(missing, encoder)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%s lacks encoders: %s", caps.Version, strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// Writable checks that a file can be created in dir
+func Writable(dir string) Check {
+	return func(ctx context.Context) error {
+		file, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return fmt.Errorf("cannot write to %s: %w", dir, err)
+		}
+		name := file.Name()
+		_, writeErr := file.Write([]byte("ok"))
+		closeErr := file.Close()
+		removeErr := os.Remove(name)
+		if err := errors.Join(writeErr, closeErr, removeErr); err != nil {
+			return fmt.Errorf("cannot write to %s: %w", dir, err)
+		}
+		return nil
+	}
+}
+
+// DiskSpace checks that free space is above the low watermark
+func DiskSpace(meter *storage.Meter) Check {
+	return func(ctx context.Context) error {
+		disk, err := meter.Disk()
+		if err != nil {
+			return err
+		}
+		if meter.Low(disk) {
+			return fmt.Errorf("%s free, below the %s watermark",
+				storage.FormatBytes(disk.AvailableBytes), storage.FormatBytes(meter.Quotas().MinFreeBytes))
+		}
+		return nil
+	}
+}
+
+// Queue checks that no more than max FFmpeg jobs are waiting for a slot
+func Queue(limiter *ffmpeg.Limiter, max int) Check {
+	return func(ctx context.Context) error {
+		if waiting := limiter.Waiting(); waiting > max {
+			return fmt.Errorf("%d FFmpeg jobs waiting, %d running of %d", waiting, limiter.Running(), limiter.Capacity())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+// Package health runs liveness and readiness checks. Liveness only says the
+// process is serving requests; readiness runs every registered check and
+// reports 503 when any of them fails, so load balancers and kiosk monitors
+// stop sending work to a server that cannot do it.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"gooji/internal/logger"
+)
+
+// Check returns nil when what it checks is working
+type Check func(ctx context.Context) error
+
+// Checker is a named readiness check
+type Checker struct {
+	Name  string
+	Check Check
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Report is the body of /readyz
+type Report struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Check and report statuses
+const (
+	StatusOK       = "ok"
+	StatusFailed   = "failed"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// Registry holds the readiness checks
+type Registry struct {
+	timeout time.Duration
+	started time.Time
+	logger  *logger.Logger
+
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+// New creates a registry that gives each check up to timeout
+func New(timeout time.Duration, log *logger.Logger) *Registry {
+	return &Registry{
+		timeout: timeout,
+		started: time.Now(),
+		logger:  log,
+	}
+}
+
+// Register adds readiness checks
+func (r *Registry) Register(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checkers...)
+}
+
+// Run runs every check concurrently and reports each one's outcome and latency
+func (r *Registry) Run(ctx context.Context) *Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	report := &Report{
+		Status:    StatusReady,
+		Timestamp: time.Now().UTC(),
+		Checks:    make([]Result, len(checkers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusNotReady
+			break
+		}
+	}
+	return report
+}
+
+// run runs one check, giving up when it outlasts the timeout
+func (r *Registry) run(ctx context.Context, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", r.timeout)
+		}
+	}
+
+	result := Result{
+		Name:      checker.Name,
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// HandleLive serves /livez. It checks nothing beyond the server answering,
+// so a slow disk or missing FFmpeg never gets the process restarted.
+func (r *Registry) HandleLive(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":         "alive",
+		"uptime_seconds": int(time.Since(r.started).Seconds()),
+	})
+}
+
+// HandleReady serves /readyz: 200 when every check passes, 503 otherwise
+func (r *Registry) HandleReady(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := r.Run(req.Context())
+	status := http.StatusOK
+	if report.Status != StatusReady {
+		status = http.StatusServiceUnavailable
+		for _, result := range report.Checks {
+			if result.Status != StatusOK {
+				r.logger.Error("Readiness check %s failed: %s", result.Name, result.Error)
+			}
+		}
+	}
+	r.writeJSON(w, status, report)
+}
+
+// writeJSON writes an uncached JSON response
+func (r *Registry) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		r.logger.Error("Failed to encode health response: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package logger
+
+import "context"
+
+// Field names shared by every component so entries can be filtered and joined
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+	FieldUser      = "user"
+	FieldToken     = "token"
+	FieldDevice    = "device"
+	FieldVideoID   = "video_id"
+	FieldOperation = "operation"
+)
+
+// contextKey is the context key for the request-scoped logger
+type contextKey struct{}
+
+// WithContext returns a context carrying log
+func WithContext(ctx context.Context, log *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx, or fallback when there is none
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if log, ok := ctx.Value(contextKey{}).(*Logger); ok && log != nil {
+		return log
+	}
+	return fallback
+}
+
+// WithFields adds key-value pairs to the logger carried by ctx. Contexts
+// without a logger are returned unchanged.
+func WithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	log := FromContext(ctx, nil)
+	if log == nil {
+		return ctx
+	}
+	return WithContext(ctx, log.With(keysAndValues...))
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+
+	"gooji/internal/config"
+)
+
+// LevelOff turns a sink off in configuration
+const LevelOff = "off"
+
+// Sink formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// maxLevelBody bounds the body of a level change
+const maxLevelBody = 4 << 10
+
+// Levels returns the current level of each enabled sink
+func (l *Logger) Levels() map[string]string {
+	levels := make(map[string]string, len(l.base.levels))
+	for name, level := range l.base.levels {
+		if level.Level() > zapcore.FatalLevel {
+			levels[name] = LevelOff
+			continue
+		}
+		levels[name] = level.Level().String()
+	}
+	return levels
+}
+
+// parseLevel parses a sink level, where off silences the sink
+func parseLevel(level string) (zapcore.Level, error) {
+	if level == LevelOff {
+		return zapcore.InvalidLevel, nil
+	}
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return parsed, fmt.Errorf("invalid log level %q: use debug, info, warn, error or off", level)
+	}
+	return parsed, nil
+}
+
+// SetLevel changes a sink's level while running; an empty sink changes every sink
+func (l *Logger) SetLevel(sink, level string) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	if sink == "" {
+		for _, atomic := range l.base.levels {
+			atomic.SetLevel(parsed)
+		}
+		return nil
+	}
+	atomic, ok := l.base.levels[sink]
+	if !ok {
+		return fmt.Errorf("unknown or disabled log sink %q: use %s", sink, l.sinkNames())
+	}
+	atomic.SetLevel(parsed)
+	return nil
+}
+
+// Reload applies the sink levels in next that differ from prev, so levels set
+// through HandleLevels survive reloads that leave logging alone. A sink that
+// was off at startup cannot be turned on without a restart.
+func (l *Logger) Reload(prev, next *config.Logging) error {
+	changes := make(map[string]string)
+	for sink, levels := range map[string][2]string{
+		SinkConsole: {prev.Console.Level, next.Console.Level},
+		SinkFile:    {prev.File.Level, next.File.Level},
+	} {
+		if levels[0] == levels[1] {
+			continue
+		}
+		if _, ok := l.base.levels[sink]; !ok {
+			return fmt.Errorf("log sink %q was off at startup; restart to turn it on", sink)
+		}
+		if _, err := parseLevel(levels[1]); err != nil {
+			return err
+		}
+		changes[sink] = levels[1]
+	}
+	// Everything was checked above, so no sink is changed unless all can be
+	for sink, level := range changes {
+		if err := l.SetLevel(sink, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sinkNames lists the enabled sinks for error messages
+func (l *Logger) sinkNames() string {
+	names := make([]string, 0, len(l.base.levels))
+	for name := range l.base.levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprint(names)
+}
+
+// LevelInput is the body of PUT /api/logging: Level applies to every sink,
+// Sinks to the named ones, and Sinks wins where both are given
+type LevelInput struct {
+	Level string            `json:"level"`
+	Sinks map[string]string `json:"sinks"`
+}
+
+// HandleLevels reports sink levels on GET and changes them on PUT. The change
+// lasts until restart, or until a reload changes the sink's configured level;
+// the configuration file is not rewritten.
+func (l *Logger) HandleLevels(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		// JSON only, so other sites cannot post a level change as a form
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		var input LevelInput
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLevelBody)).Decode(&input); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if input.Level == "" && len(input.Sinks) == 0 {
+			http.Error(w, "level or sinks is required", http.StatusBadRequest)
+			return
+		}
+		// Validate everything before changing anything
+		for sink, level := range input.Sinks {
+			if _, ok := l.base.levels[sink]; !ok {
+				http.Error(w, fmt.Sprintf("unknown or disabled log sink %q", sink), http.StatusBadRequest)
+				return
+			}
+			if _, err := parseLevel(level); err != nil {
+				http.Error(w, fmt.Sprintf("invalid log level %q", level), http.StatusBadRequest)
+				return
+			}
+		}
+		if input.Level != "" {
+			if err := l.SetLevel("", input.Level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		for sink, level := range input.Sinks {
+			if err := l.SetLevel(sink, level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		FromContext(r.Context(), l).Infow("Log levels changed", "levels", l.Levels())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(l.Levels()); err != nil {
+		l.Error("Failed to encode log levels: %v", err)
+	}
+}
@@ -3,45 +3,36 @@ package logger
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"gooji/internal/config"
+)
+
+// Sink names, used to change levels at runtime
+const (
+	SinkConsole = "console"
+	SinkFile    = "file"
 )
 
-// Logger handles application logging
+// Logger handles application logging. Loggers derived with With share the
+// base logger's outputs, levels and file, and add structured fields to every entry.
 type Logger struct {
-	zap *zap.Logger
+	zap   *zap.Logger
+	sugar *zap.SugaredLogger
+	base  *outputs
 }
 
-// New creates a new logger
-func New(logDir string) (*Logger, error) {
-	if err := os.MkdirAll(logDir, 0o750); err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %w", err)
-	}
-
-	// Create log file
-	logFile := filepath.Join(logDir, fmt.Sprintf("gooji_%s.log", time.Now().Format("2006-01-02")))
-
-	// Security check: prevent path traversal in log file path
-	if strings.Contains(logFile, "..") {
-		return nil, fmt.Errorf("path traversal not allowed in log file path: %s", logFile)
-	}
-
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // Path validated above
-	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
-	}
-
-	// Determine log level from environment
-	logLevel := zapcore.InfoLevel
-	if os.Getenv("APP_DEBUG") == "true" {
-		logLevel = zapcore.DebugLevel
-	}
+// outputs are the sinks shared by a logger and everything derived from it
+type outputs struct {
+	levels map[string]zap.AtomicLevel
+	file   *rotatingFile
+}
 
-	// Configure Zap encoder
+// New creates a logger writing to the console and to rotating files in logDir
+func New(logDir string, cfg *config.Logging) (*Logger, error) {
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
@@ -56,49 +47,114 @@ func New(logDir string) (*Logger, error) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	// Create core with both file and console output
-	core := zapcore.NewTee(
-		zapcore.NewCore(
-			zapcore.NewJSONEncoder(encoderConfig),
-			zapcore.AddSync(file),
-			logLevel,
-		),
-		zapcore.NewCore(
-			zapcore.NewConsoleEncoder(encoderConfig),
-			zapcore.AddSync(os.Stdout),
-			logLevel,
-		),
-	)
-
-	// Create logger
-	zapLogger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	base := &outputs{levels: make(map[string]zap.AtomicLevel)}
+	var cores []zapcore.Core
+
+	if cfg.Console.Level != LevelOff {
+		core, err := newCore(SinkConsole, &cfg.Console, encoderConfig, zapcore.AddSync(os.Stdout), base)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+
+	if cfg.File.Level != LevelOff {
+		rotation := cfg.Rotation
+		file, err := newRotatingFile(logDir, int64(rotation.MaxSizeMB)<<20, rotation.MaxFiles,
+			time.Duration(rotation.MaxAgeDays)*24*time.Hour, rotation.Compress)
+		if err != nil {
+			return nil, err
+		}
+		core, err := newCore(SinkFile, &cfg.File, encoderConfig, file, base)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		base.file = file
+		cores = append(cores, core)
+	}
+
+	zapLogger := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.ErrorLevel))
 
 	return &Logger{
-		zap: zapLogger,
+		zap:   zapLogger,
+		sugar: zapLogger.Sugar(),
+		base:  base,
 	}, nil
 }
 
-// Close closes the logger
+// newCore builds one sink's core with its own adjustable level and encoding
+func newCore(name string, sink *config.LogSink, encoderConfig zapcore.EncoderConfig, out zapcore.WriteSyncer, base *outputs) (zapcore.Core, error) {
+	level, err := zap.ParseAtomicLevel(sink.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s log level %q: %w", name, sink.Level, err)
+	}
+
+	var encoder zapcore.Encoder
+	switch sink.Format {
+	case FormatText:
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("invalid %s log format %q: use %s or %s", name, sink.Format, FormatText, FormatJSON)
+	}
+
+	base.levels[name] = level
+	return zapcore.NewCore(encoder, out, level), nil
+}
+
+// Close flushes the logger and closes its file
 func (l *Logger) Close() error {
-	return l.zap.Sync()
+	err := l.zap.Sync()
+	if l.base.file != nil {
+		if closeErr := l.base.file.Close(); closeErr != nil {
+			return closeErr
+		}
+	}
+	return err
+}
+
+// With returns a logger that adds the given key-value pairs to every entry
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	if len(keysAndValues) == 0 {
+		return l
+	}
+	sugar := l.sugar.With(keysAndValues...)
+	return &Logger{zap: sugar.Desugar(), sugar: sugar, base: l.base}
+}
+
+// Debugw logs a debug message with structured key-value pairs
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.sugar.Debugw(msg, keysAndValues...)
+}
+
+// Infow logs an info message with structured key-value pairs
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.sugar.Infow(msg, keysAndValues...)
+}
+
+// Errorw logs an error message with structured key-value pairs
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.sugar.Errorw(msg, keysAndValues...)
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(format string, args ...interface{}) {
-	l.zap.Sugar().Debugf(format, args...)
+	l.sugar.Debugf(format, args...)
 }
 
 // Info logs an info message
 func (l *Logger) Info(format string, args ...interface{}) {
-	l.zap.Sugar().Infof(format, args...)
+	l.sugar.Infof(format, args...)
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, args ...interface{}) {
-	l.zap.Sugar().Errorf(format, args...)
+	l.sugar.Errorf(format, args...)
 }
 
 // Fatal logs a fatal message and exits
 func (l *Logger) Fatal(format string, args ...interface{}) {
-	l.zap.Sugar().Fatalf(format, args...)
+	l.sugar.Fatalf(format, args...)
 }
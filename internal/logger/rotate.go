@@ -0,0 +1,272 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// filePrefix and fileSuffix frame log file names: gooji_2006-01-02.log, then
+// gooji_2006-01-02.1.log and so on when a day's file fills up
+const (
+	filePrefix = "gooji_"
+	fileSuffix = ".log"
+)
+
+// rotatingFile writes log entries to a file that is replaced when it reaches
+// its size limit or the date changes. Replaced files are optionally gzipped
+// and pruned by count and age in the background.
+type rotatingFile struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+	maxAge   time.Duration
+	compress bool
+
+	mu   sync.Mutex
+	file *os.File
+	path string
+	day  string
+	seq  int
+	size int64
+
+	// pending tracks background compression and pruning so Close can wait for it
+	pending sync.WaitGroup
+}
+
+// newRotatingFile opens today's log file in dir, continuing the latest one if it has room
+func newRotatingFile(dir string, maxSize int64, maxFiles int, maxAge time.Duration, compress bool) (*rotatingFile, error) {
+	if strings.Contains(dir, "..") {
+		return nil, fmt.Errorf("path traversal not allowed in log directory: %s", dir)
+	}
+	f := &rotatingFile{
+		dir:      dir,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		maxAge:   maxAge,
+		compress: compress,
+	}
+	now := time.Now()
+	if err := f.open(now, f.latestSeq(now.Format("2006-01-02"))); err != nil {
+		return nil, err
+	}
+	f.cleanup("")
+	return f, nil
+}
+
+// Write appends p, rotating first when the day has changed or p would overflow the file
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if f.file == nil || now.Format("2006-01-02") != f.day || (f.size > 0 && f.size+int64(len(p)) > f.maxSize) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the current file to disk
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the current file and waits for background compression and pruning
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.pending.Wait()
+	return err
+}
+
+// rotate closes the current file, opens the next one and hands the old one to cleanup
+func (f *rotatingFile) rotate(now time.Time) error {
+	previous := f.path
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		f.file = nil
+	}
+	from := f.seq + 1
+	if day := now.Format("2006-01-02"); day != f.day {
+		from = f.latestSeq(day)
+	}
+	if err := f.open(now, from); err != nil {
+		return err
+	}
+	f.cleanup(previous)
+	return nil
+}
+
+// open opens the log file for now's date, continuing from sequence number
+// from and skipping files that are full or already compressed
+func (f *rotatingFile) open(now time.Time, from int) error {
+	if err := os.MkdirAll(f.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	day := now.Format("2006-01-02")
+	for seq := from; ; seq++ {
+		path := f.filePath(day, seq)
+		if _, err := os.Stat(path + ".gz"); err == nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err == nil && info.Size() >= f.maxSize {
+			continue
+		}
+
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // Directory validated in newRotatingFile
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		size := int64(0)
+		if info != nil {
+			size = info.Size()
+		}
+		f.file, f.path, f.day, f.seq, f.size = file, path, day, seq, size
+		return nil
+	}
+}
+
+// latestSeq returns the highest sequence number among a day's files, so a
+// restart continues after files that were already pruned
+func (f *rotatingFile) latestSeq(day string) int {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return 0
+	}
+	latest := 0
+	prefix := filePrefix + day + "."
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".gz")
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), fileSuffix))
+		if err == nil && seq > latest {
+			latest = seq
+		}
+	}
+	return latest
+}
+
+// filePath returns the name of a day's seq-th log file
+func (f *rotatingFile) filePath(day string, seq int) string {
+	name := filePrefix + day + fileSuffix
+	if seq > 0 {
+		name = fmt.Sprintf("%s%s.%d%s", filePrefix, day, seq, fileSuffix)
+	}
+	return filepath.Join(f.dir, name)
+}
+
+// cleanup compresses the rotated file, if any and if enabled, then prunes old
+// files. It runs in the background; errors go to stderr since the logger
+// cannot log its own failures.
+func (f *rotatingFile) cleanup(rotated string) {
+	current := f.path
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		if rotated != "" && f.compress {
+			if err := compressFile(rotated); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to compress log file %s: %v\n", rotated, err)
+			}
+		}
+		if err := f.prune(current); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to prune log files: %v\n", err)
+		}
+	}()
+}
+
+// prune removes old log files beyond the count and age limits, newest kept first
+func (f *rotatingFile) prune(current string) error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	type oldFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []oldFile
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(f.dir, name)
+		if entry.IsDir() || path == current || !strings.HasPrefix(name, filePrefix) ||
+			!(strings.HasSuffix(name, fileSuffix) || strings.HasSuffix(name, fileSuffix+".gz")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, oldFile{path: path, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	cutoff := time.Now().Add(-f.maxAge)
+	for i, file := range files {
+		tooMany := f.maxFiles >= 0 && i >= f.maxFiles
+		tooOld := f.maxAge >= 0 && file.modTime.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file.path, err)
+		}
+	}
+	return nil
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path) //nolint:gosec // Path built by rotatingFile
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // Path built by rotatingFile
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
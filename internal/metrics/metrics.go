@@ -0,0 +1,172 @@
+// Package metrics collects Prometheus metrics for the HTTP server, uploads,
+// FFmpeg and storage, and serves them on /metrics. A nil *Metrics records
+// nothing, so commands that run without the server need not create one.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "gooji"
+
+// Upload outcomes
+const (
+	UploadSucceeded = "success"
+	UploadRejected  = "rejected"
+	UploadOverQuota = "quota"
+	UploadBusy      = "busy"
+	UploadFailed    = "error"
+)
+
+// Metrics holds the collectors and the registry they are exposed from
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	httpInFlight    prometheus.Gauge
+	uploads         *prometheus.CounterVec
+	uploadBytes     prometheus.Counter
+	ffmpegDuration  *prometheus.HistogramVec
+	ffmpegFailures  *prometheus.CounterVec
+	retentionVideos prometheus.Counter
+	retentionFiles  prometheus.Counter
+}
+
+// New creates the metrics with Go runtime and process collectors registered
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route pattern and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served.",
+		}),
+		uploads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "uploads_total",
+			Help:      "Video uploads by outcome: success, rejected, quota, busy or error.",
+		}, []string{"outcome"}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upload_bytes_total",
+			Help:      "Bytes of successfully uploaded video.",
+		}),
+		ffmpegDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "ffmpeg_duration_seconds",
+			Help:      "FFmpeg run time by operation, including failed runs.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}, []string{"operation"}),
+		ffmpegFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ffmpeg_failures_total",
+			Help:      "Failed FFmpeg runs by operation.",
+		}, []string{"operation"}),
+		retentionVideos: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retention_expired_videos_total",
+			Help:      "Videos deleted by retention rules.",
+		}),
+		retentionFiles: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retention_removed_files_total",
+			Help:      "Stale files removed by the retention janitor.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.uploads,
+		m.uploadBytes,
+		m.ffmpegDuration,
+		m.ffmpegFailures,
+		m.retentionVideos,
+		m.retentionFiles,
+	)
+	return m
+}
+
+// Register adds collectors owned elsewhere, such as gauges read on each scrape
+func (m *Metrics) Register(cs ...prometheus.Collector) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(cs...)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RequestStarted counts a request in flight and returns the function that ends it
+func (m *Metrics) RequestStarted() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.httpInFlight.Inc()
+	return m.httpInFlight.Dec
+}
+
+// ObserveRequest records a finished HTTP request
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveUpload records an upload's outcome and, for successful ones, its size
+func (m *Metrics) ObserveUpload(outcome string, bytes int64) {
+	if m == nil {
+		return
+	}
+	m.uploads.WithLabelValues(outcome).Inc()
+	if outcome == UploadSucceeded && bytes > 0 {
+		m.uploadBytes.Add(float64(bytes))
+	}
+}
+
+// ObserveFFmpeg records one FFmpeg run
+func (m *Metrics) ObserveFFmpeg(operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.ffmpegDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		m.ffmpegFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+// ObserveRetention records what a janitor run removed
+func (m *Metrics) ObserveRetention(videos, files int) {
+	if m == nil {
+		return
+	}
+	m.retentionVideos.Add(float64(videos))
+	m.retentionFiles.Add(float64(files))
+}
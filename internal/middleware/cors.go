@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gooji/internal/config"
+)
+
+// CORS handles Cross-Origin Resource Sharing from the configured policy.
+// Responses always vary by Origin so caches never serve one origin's grant to
+// another. Preflights from disallowed origins, or asking for disallowed methods
+// or headers, are refused with 403.
+func CORS(cfg *config.CORS) (Middleware, error) {
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return nil, fmt.Errorf("invalid CORS origin %q: use scheme://host[:port]", origin)
+		}
+		origins[strings.ToLower(u.Scheme+"://"+u.Host)] = true
+	}
+	if anyOrigin && cfg.AllowCredentials {
+		return nil, errors.New("CORS origin \"*\" cannot be combined with allow_credentials")
+	}
+
+	methods := make(map[string]bool, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		methods[strings.ToUpper(method)] = true
+	}
+	headers := make(map[string]bool, len(cfg.AllowedHeaders))
+	for _, header := range cfg.AllowedHeaders {
+		headers[http.CanonicalHeaderKey(header)] = true
+	}
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(cfg.MaxAge)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed := anyOrigin || origins[strings.ToLower(origin)]
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				if !allowed || !methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))] || !headersAllowed(r, headers) {
+					http.Error(w, "CORS request not allowed", http.StatusForbidden)
+					return
+				}
+				setAllowOrigin(w, origin, anyOrigin, cfg.AllowCredentials)
+				w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if allowed {
+				setAllowOrigin(w, origin, anyOrigin, cfg.AllowCredentials)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// setAllowOrigin grants an origin access, echoing it unless any origin is allowed
+func setAllowOrigin(w http.ResponseWriter, origin string, anyOrigin, credentials bool) {
+	if anyOrigin {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// headersAllowed reports whether every header a preflight asks for is allowed
+func headersAllowed(r *http.Request, allowed map[string]bool) bool {
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !allowed[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
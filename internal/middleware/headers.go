@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gooji/internal/config"
+)
+
+// nonceContextKey is the context key for the request's CSP nonce
+type nonceContextKey struct{}
+
+// mediaCapturePaths are the pages allowed to use the camera and microphone
+var mediaCapturePaths = map[string]bool{"/record": true}
+
+// CSPNonce returns the request's content security policy nonce, which inline
+// template scripts must carry as nonce="...". It is empty outside SecurityHeaders.
+func CSPNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(nonceContextKey{}).(string)
+	return nonce
+}
+
+// SecurityHeaders sets the content security policy, with a fresh script nonce
+// per request, and the framing, referrer, permissions and transport headers
+func SecurityHeaders(cfg *config.Headers) Middleware {
+	frameAncestors := "'none'"
+	if len(cfg.FrameAncestors) > 0 {
+		frameAncestors = strings.Join(cfg.FrameAncestors, " ")
+	}
+	styleSources := strings.TrimSpace("'self' 'unsafe-inline' " + strings.Join(cfg.StyleSources, " "))
+	fontSources := strings.TrimSpace("'self' " + strings.Join(cfg.FontSources, " "))
+	hsts := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			nonce := base64.StdEncoding.EncodeToString(b)
+
+			header := w.Header()
+			header.Set("Content-Security-Policy", strings.Join([]string{
+				"default-src 'self'",
+				"script-src 'self' 'nonce-" + nonce + "'",
+				"style-src " + styleSources,
+				"font-src " + fontSources,
+				"img-src 'self' data: blob:",
+				"media-src 'self' blob:",
+				"connect-src 'self'",
+				"object-src 'none'",
+				"base-uri 'self'",
+				"form-action 'self'",
+				"frame-ancestors " + frameAncestors,
+			}, "; "))
+			header.Set("X-Content-Type-Options", "nosniff")
+			header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			if len(cfg.FrameAncestors) == 0 {
+				header.Set("X-Frame-Options", "DENY")
+			}
+			if mediaCapturePaths[r.URL.Path] {
+				header.Set("Permissions-Policy", "camera=(self), microphone=(self), geolocation=(), display-capture=()")
+			} else {
+				header.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=(), display-capture=()")
+			}
+			if r.TLS != nil {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), nonceContextKey{}, nonce)))
+		})
+	}
+}
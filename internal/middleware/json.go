@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+)
+
+// RequireJSON reports whether the request body is declared as JSON, answering
+// 415 when it is not. Other sites can post forms and text/plain bodies without
+// a preflight but not JSON, so JSON endpoints check this before decoding.
+func RequireJSON(w http.ResponseWriter, r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"gooji/internal/metrics"
+)
+
+// RouteFunc names the route pattern that serves a request, such as
+// "/api/videos/", so metrics are labelled by route rather than by raw path
+type RouteFunc func(r *http.Request) string
+
+// Metrics returns middleware that counts requests and their latency by route,
+// method and status code. It should run before Recovery so panics count as 500s.
+func Metrics(m *metrics.Metrics, route RouteFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done := m.RequestStarted()
+			defer done()
+
+			start := time.Now()
+			recorder := newResponseRecorder(w)
+			next.ServeHTTP(recorder, r)
+
+			pattern := route(r)
+			if pattern == "" {
+				pattern = "unmatched"
+			}
+			m.ObserveRequest(pattern, metricMethod(r.Method), recorder.status, time.Since(start))
+		})
+	}
+}
+
+// metricMethod keeps arbitrary method names out of metric labels
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"gooji/internal/logger"
 )
 
@@ -18,14 +20,27 @@ func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
 	return h
 }
 
-// Logging logs HTTP requests
+// Logging attaches a request-scoped logger carrying the request and trace IDs
+// to the context and logs each request with its status code and response
+// size. It must run after RequestID and Tracing.
 func Logging(log *logger.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			next.ServeHTTP(w, r)
-			duration := time.Since(start)
-			log.Info("%s %s %s %v", r.Method, r.URL.Path, r.RemoteAddr, duration)
+			reqLog := log.With(logger.FieldRequestID, RequestIDFromContext(r.Context()))
+			if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+				reqLog = reqLog.With(logger.FieldTraceID, span.TraceID().String())
+			}
+			recorder := newResponseRecorder(w)
+			next.ServeHTTP(recorder, r.WithContext(logger.WithContext(r.Context(), reqLog)))
+			reqLog.Infow("HTTP request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote", r.RemoteAddr,
+				"status", recorder.status,
+				"bytes", recorder.bytes,
+				"duration", time.Since(start),
+			)
 		})
 	}
 }
@@ -36,7 +51,7 @@ func Recovery(log *logger.Logger) Middleware {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					log.Error("Panic recovered: %v", err)
+					logger.FromContext(r.Context(), log).Error("Panic recovered: %v", err)
 					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 				}
 			}()
@@ -45,24 +60,6 @@ func Recovery(log *logger.Logger) Middleware {
 	}
 }
 
-// CORS handles Cross-Origin Resource Sharing
-func CORS() Middleware {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-
-			next.ServeHTTP(w, r)
-		})
-	}
-}
-
 // ContentType sets the content type for responses
 func ContentType(contentType string) Middleware {
 	return func(next http.Handler) http.Handler {
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gooji/internal/config"
+)
+
+const (
+	// bucketIdleTTL is how long an unused bucket is kept; a full bucket carries no state worth keeping
+	bucketIdleTTL = 10 * time.Minute
+	// sweepInterval is how often idle buckets are dropped
+	sweepInterval = time.Minute
+)
+
+// ClientKey identifies who a request is rate limited as; an empty key falls back to the IP address
+type ClientKey func(r *http.Request) string
+
+// bucket is one client's token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter is a set of token buckets sharing one rate and burst
+type limiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// newLimiter creates a limiter from a per-minute rate and burst
+func newLimiter(cfg config.RateLimit) *limiter {
+	l := &limiter{buckets: make(map[string]*bucket)}
+	l.set(cfg)
+	return l
+}
+
+// set changes the rate and burst; buckets keep their tokens, capped at the new burst
+func (l *limiter) set(cfg config.RateLimit) {
+	burst := float64(cfg.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = cfg.PerMinute / 60
+	l.burst = burst
+}
+
+// allow takes a token for key, or reports how long until one is available
+func (l *limiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) >= bucketIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, time.Hour
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// rateSettings are the parts of the rate limits swapped whole on reload
+type rateSettings struct {
+	enabled bool
+	exempt  []string
+}
+
+// RateLimiter enforces token-bucket limits per client, with uploads limited
+// separately from everything else. Its limits can change while running.
+type RateLimiter struct {
+	key      ClientKey
+	settings atomic.Pointer[rateSettings]
+	uploads  *limiter
+	reads    *limiter
+}
+
+// NewRateLimiter creates a rate limiter keyed by key, falling back to the IP address
+func NewRateLimiter(cfg config.RateLimits, key ClientKey) *RateLimiter {
+	rl := &RateLimiter{
+		key:     key,
+		uploads: newLimiter(cfg.Upload),
+		reads:   newLimiter(cfg.Read),
+	}
+	rl.Update(cfg)
+	return rl
+}
+
+// Update applies new limits; requests already counted keep their buckets
+func (rl *RateLimiter) Update(cfg config.RateLimits) {
+	rl.uploads.set(cfg.Upload)
+	rl.reads.set(cfg.Read)
+	rl.settings.Store(&rateSettings{
+		enabled: cfg.Enabled,
+		exempt:  append([]string(nil), cfg.ExemptPaths...),
+	})
+}
+
+// Middleware returns middleware enforcing the limits. Limited requests get
+// 429 with Retry-After in whole seconds. It must run after the middleware
+// that identifies the client for the key.
+func (rl *RateLimiter) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			settings := rl.settings.Load()
+			if !settings.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			for _, prefix := range settings.exempt {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			client := rl.key(r)
+			if client == "" {
+				client = "ip:" + clientIP(r)
+			}
+
+			limits := rl.reads
+			if isUpload(r) {
+				limits = rl.uploads
+			}
+			ok, wait := limits.allow(client, time.Now())
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isUpload reports whether a request uploads a video
+func isUpload(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.TrimSuffix(r.URL.Path, "/") == "/api/videos"
+}
+
+// clientIP returns the request's remote IP without its port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a server span for each request
+var tracer = otel.Tracer("gooji/internal/middleware")
+
+// Tracing returns middleware that starts a span for each request, joining a
+// trace propagated by the caller, named by method and route pattern. It must
+// run after RequestID so the span carries the request's ID.
+func Tracing(route RouteFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			pattern := route(r)
+			if pattern == "" {
+				pattern = "unmatched"
+			}
+			ctx, span := tracer.Start(ctx, metricMethod(r.Method)+" "+pattern,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", pattern),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", r.RemoteAddr),
+					attribute.String("gooji.request_id", RequestIDFromContext(r.Context())),
+				),
+			)
+			defer span.End()
+
+			recorder := newResponseRecorder(w)
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttributes(
+				attribute.Int("http.response.status_code", recorder.status),
+				attribute.Int64("http.response.body.size", recorder.bytes),
+			)
+			if recorder.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(recorder.status))
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Algorithm is the name of the signature algorithm used by Signer
+const Algorithm = "HMAC-SHA256"
+
+// minKeySize is the minimum accepted signing key length in bytes
+const minKeySize = 32
+
+// Signer creates and verifies HMAC-SHA256 signatures
+type Signer struct {
+	key   []byte
+	keyID string
+}
+
+// New creates a signer from a secret key
+func New(key []byte) (*Signer, error) {
+	if len(key) < minKeySize {
+		return nil, fmt.Errorf("signing key must be at least %d bytes", minKeySize)
+	}
+
+	sum := sha256.Sum256(key)
+	return &Signer{
+		key:   append([]byte(nil), key...),
+		keyID: hex.EncodeToString(sum[:8]),
+	}, nil
+}
+
+// Load creates a signer from a configured key, or from a key file that is
+// generated on first use when no key is configured
+func Load(configuredKey, keyPath string) (*Signer, error) {
+	if configuredKey != "" {
+		return New([]byte(configuredKey))
+	}
+
+	data, err := os.ReadFile(keyPath) //nolint:gosec // Path comes from configuration
+	if err == nil {
+		key, decodeErr := hex.DecodeString(strings.TrimSpace(string(data)))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("failed to decode signing key file: %w", decodeErr)
+		}
+		return New(key)
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read signing key file: %w", err)
+	}
+
+	key := make([]byte, minKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create signing key directory: %w", err)
+	}
+	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key file: %w", err)
+	}
+	return New(key)
+}
+
+// KeyID returns a short, non-secret identifier for the signing key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign returns the base64url-encoded signature of data
+func (s *Signer) Sign(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(s.mac(data))
+}
+
+// Verify reports whether signature is a valid signature of data
+func (s *Signer) Verify(data []byte, signature string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(decoded, s.mac(data))
+}
+
+// mac computes the raw HMAC of data
+func (s *Signer) mac(data []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd
+
+package storage
+
+import "errors"
+
+// DiskUsage reports the size and free space of the filesystem holding path.
+// Free space cannot be measured on this platform, so it always fails and the
+// low watermark is not enforced.
+func DiskUsage(path string) (*Disk, error) {
+	return nil, errors.New("disk usage is not supported on this platform")
+}
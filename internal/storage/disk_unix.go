@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd
+
+package storage
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// DiskUsage reports the size and free space of the filesystem holding path
+func DiskUsage(path string) (*Disk, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, fmt.Errorf("failed to stat filesystem of %s: %w", path, err)
+	}
+	blockSize := uint64(stat.Bsize) //nolint:gosec // Block sizes are small and positive
+	return &Disk{
+		Path:           path,
+		TotalBytes:     clampInt64(uint64(stat.Blocks) * blockSize),
+		FreeBytes:      clampInt64(uint64(stat.Bfree) * blockSize),
+		AvailableBytes: clampInt64(uint64(stat.Bavail) * blockSize),
+	}, nil
+}
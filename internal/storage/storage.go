@@ -0,0 +1,179 @@
+// Package storage measures disk space and how much of it Gooji's storage
+// directories use, and checks both against the configured quotas.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gooji/internal/config"
+)
+
+// Disk describes the filesystem holding a directory
+type Disk struct {
+	Path           string `json:"path"`
+	TotalBytes     int64  `json:"total_bytes"`
+	FreeBytes      int64  `json:"free_bytes"`
+	AvailableBytes int64  `json:"available_bytes"`
+}
+
+// Directory is the space used by one configured storage directory
+type Directory struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Files int    `json:"files"`
+}
+
+// Meter measures the configured storage directories
+type Meter struct {
+	storage *config.Storage
+	quotas  config.Quotas
+}
+
+// NewMeter creates a meter for the storage directories and quotas
+func NewMeter(storage *config.Storage, quotas config.Quotas) *Meter {
+	return &Meter{storage: storage, quotas: quotas}
+}
+
+// Quotas returns the configured limits
+func (m *Meter) Quotas() config.Quotas {
+	return m.quotas
+}
+
+// Disk reports the filesystem holding the uploads directory, where videos are written
+func (m *Meter) Disk() (*Disk, error) {
+	return DiskUsage(m.storage.Uploads)
+}
+
+// Low reports whether the disk is below the low watermark
+func (m *Meter) Low(disk *Disk) bool {
+	return m.quotas.MinFreeBytes > 0 && disk.AvailableBytes < m.quotas.MinFreeBytes
+}
+
+// Directories measures each configured storage directory. Directories nested
+// inside another configured directory are reported on their own and left out
+// of the one that contains them, so the sizes add up to the total.
+func (m *Meter) Directories() ([]Directory, error) {
+	dirs := Directories(m.storage)
+
+	skip := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		skip[filepath.Clean(dir.Path)] = true
+	}
+
+	measured := make([]Directory, 0, len(dirs))
+	seen := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		root := filepath.Clean(dir.Path)
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		bytes, files, err := measure(root, skip)
+		if err != nil {
+			return nil, err
+		}
+		dir.Bytes = bytes
+		dir.Files = files
+		measured = append(measured, dir)
+	}
+	return measured, nil
+}
+
+// Directories lists the configured storage directories by name, unmeasured
+func Directories(storage *config.Storage) []Directory {
+	return []Directory{
+		{Name: "uploads", Path: storage.Uploads},
+		{Name: "temp", Path: storage.Temp},
+		{Name: "logs", Path: storage.Logs},
+		{Name: "thumbnails", Path: storage.Thumbnails},
+		{Name: "metadata", Path: storage.Metadata},
+		{Name: "captions", Path: storage.Captions},
+		{Name: "auth", Path: storage.Auth},
+		{Name: "audit", Path: storage.Audit},
+	}
+}
+
+// Total returns the combined size of the storage directories
+func Total(dirs []Directory) int64 {
+	var total int64
+	for _, dir := range dirs {
+		total += dir.Bytes
+	}
+	return total
+}
+
+// measure sums the regular files under root, skipping other configured roots
+func measure(root string, skip map[string]bool) (int64, int, error) {
+	var bytes int64
+	files := 0
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && skip[path] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		bytes += info.Size()
+		files++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure %s: %w", root, err)
+	}
+	return bytes, files, nil
+}
+
+// FileSize returns the size of a file, or 0 when it does not exist
+func FileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// FormatBytes renders a byte count for people, such as "1.5 GB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %s", float64(n)/float64(div), strings.Split("KB MB GB TB PB EB", " ")[exp])
+}
+
+// clampInt64 converts a byte count, saturating at the largest int64
+func clampInt64(n uint64) int64 {
+	if n > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(n)
+}
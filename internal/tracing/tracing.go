@@ -0,0 +1,119 @@
+// Package tracing sets up OpenTelemetry trace export. HTTP requests, video
+// service methods, repository operations and FFmpeg runs record spans through
+// the global tracer provider, which stays a no-op while tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"gooji/internal/config"
+)
+
+// Exporters
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Shutdown flushes buffered spans and stops export
+type Shutdown func(ctx context.Context) error
+
+// Setup installs the tracer provider and the W3C trace context propagator
+// described by cfg. The returned Shutdown must be called before exiting so
+// buffered spans are not lost; it does nothing when tracing is disabled.
+func Setup(ctx context.Context, cfg *config.Tracing) (Shutdown, error) {
+	// Propagate incoming trace context even when not recording, so proxies' traces stay joined
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample_ratio must be between 0 and 1, got %g", cfg.SampleRatio)
+	}
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to shut down tracing: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// newExporter creates the configured span exporter and, for files, the file to close after it
+func newExporter(ctx context.Context, cfg *config.Tracing) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if strings.Contains(cfg.Endpoint, "://") {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil, nil
+
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil, nil
+
+	case ExporterFile:
+		if strings.Contains(cfg.File, "..") {
+			return nil, nil, fmt.Errorf("path traversal not allowed in trace file path: %s", cfg.File)
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.File), 0o750); err != nil {
+			return nil, nil, fmt.Errorf("failed to create trace directory: %w", err)
+		}
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // Path validated above
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		return exporter, file, nil
+	}
+	return nil, nil, fmt.Errorf("unknown tracing exporter %q: use %s, %s or %s", cfg.Exporter, ExporterOTLP, ExporterStdout, ExporterFile)
+}
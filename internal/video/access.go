@@ -0,0 +1,211 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gooji/internal/auth"
+)
+
+// maxAccessRoles limits the number of roles a restricted video may list
+const maxAccessRoles = 20
+
+// maxSeasonalWindows limits the number of seasonal windows on a video
+const maxSeasonalWindows = 4
+
+// AccessLevel describes who may view a video under cultural protocol
+type AccessLevel string
+
+const (
+	// AccessPublic places no cultural restriction on a video
+	AccessPublic AccessLevel = "public"
+	// AccessCommunity limits a video to community members and the kiosk
+	AccessCommunity AccessLevel = "community"
+	// AccessRestricted limits a video to viewers holding one of its roles
+	AccessRestricted AccessLevel = "restricted"
+)
+
+// ParseAccessLevel validates an access level name; empty means public
+func ParseAccessLevel(name string) (AccessLevel, error) {
+	switch level := AccessLevel(strings.ToLower(strings.TrimSpace(name))); level {
+	case "":
+		return AccessPublic, nil
+	case AccessPublic, AccessCommunity, AccessRestricted:
+		return level, nil
+	default:
+		return "", fmt.Errorf("unknown access level %q", name)
+	}
+}
+
+// SeasonalWindow is a recurring yearly period, such as winter for winter-only stories.
+// Start and End are "MM-DD" and inclusive; a window may wrap past the end of the year.
+type SeasonalWindow struct {
+	Label string `json:"label,omitempty"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Access holds the cultural protocol for a video.
+// A video with seasonal windows is only shown while the current date is inside one of them.
+type Access struct {
+	Level   AccessLevel      `json:"level"`
+	Roles   []string         `json:"roles,omitempty"`
+	Seasons []SeasonalWindow `json:"seasons,omitempty"`
+}
+
+// AccessInput represents a request to set a video's cultural protocol
+type AccessInput struct {
+	Level   string           `json:"level"`
+	Roles   []string         `json:"roles"`
+	Seasons []SeasonalWindow `json:"seasons"`
+}
+
+// permits reports why a viewer may not see a video with this access, or nil if they may.
+// Videos without access settings are public.
+func (a *Access) permits(viewer *Viewer, now time.Time) error {
+	if a == nil {
+		return nil
+	}
+
+	switch a.Level {
+	case AccessCommunity:
+		if !viewer.Kiosk && !viewer.Member {
+			return errors.New("video is shared with community members only")
+		}
+	case AccessRestricted:
+		if !viewer.hasAnyRole(a.Roles) && !viewer.Can(auth.PermReviewVideos) {
+			return errors.New("video is restricted to viewers with a permitted role")
+		}
+	}
+
+	if len(a.Seasons) > 0 && !a.inSeason(now) {
+		return errors.New("video may only be viewed during its season")
+	}
+	return nil
+}
+
+// inSeason reports whether now falls inside any of the seasonal windows
+func (a *Access) inSeason(now time.Time) bool {
+	day := int(now.Month())*100 + now.Day()
+	for _, window := range a.Seasons {
+		start, errStart := parseMonthDay(window.Start)
+		end, errEnd := parseMonthDay(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		if start <= end && day >= start && day <= end {
+			return true
+		}
+		if start > end && (day >= start || day <= end) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMonthDay parses "MM-DD" into MMDD as an integer for comparison
+func parseMonthDay(value string) (int, error) {
+	// 2000 is a leap year, so 02-29 is accepted
+	t, err := time.Parse("2006-01-02", "2000-"+value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid month-day %q: use MM-DD", value)
+	}
+	return int(t.Month())*100 + t.Day(), nil
+}
+
+// hasAnyRole reports whether the viewer holds at least one of the roles
+func (v *Viewer) hasAnyRole(roles []string) bool {
+	for _, role := range roles {
+		for _, held := range v.Roles {
+			if strings.EqualFold(role, held) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newAccess validates and sanitizes access input
+func (s *service) newAccess(input *AccessInput) (*Access, error) {
+	if input == nil {
+		return nil, nil
+	}
+	level, err := ParseAccessLevel(input.Level)
+	if err != nil {
+		return nil, NewValidationError("invalid access level", err)
+	}
+
+	access := &Access{Level: level}
+	if level == AccessRestricted {
+		seen := make(map[string]bool)
+		for _, role := range input.Roles {
+			role = strings.ToLower(s.sanitizer.Tag(role))
+			if role != "" && !seen[role] {
+				seen[role] = true
+				access.Roles = append(access.Roles, role)
+			}
+		}
+		if len(access.Roles) == 0 {
+			return nil, NewValidationError("restricted videos require at least one role", nil)
+		}
+		if len(access.Roles) > maxAccessRoles {
+			return nil, NewValidationError(fmt.Sprintf("at most %d roles are allowed", maxAccessRoles), nil)
+		}
+	}
+
+	if len(input.Seasons) > maxSeasonalWindows {
+		return nil, NewValidationError(fmt.Sprintf("at most %d seasonal windows are allowed", maxSeasonalWindows), nil)
+	}
+	for _, window := range input.Seasons {
+		if _, err := parseMonthDay(window.Start); err != nil {
+			return nil, NewValidationError("invalid season start", err)
+		}
+		if _, err := parseMonthDay(window.End); err != nil {
+			return nil, NewValidationError("invalid season end", err)
+		}
+		access.Seasons = append(access.Seasons, SeasonalWindow{
+			Label: s.sanitizer.Title(window.Label),
+			Start: window.Start,
+			End:   window.End,
+		})
+	}
+
+	if access.Level == AccessPublic && len(access.Seasons) == 0 {
+		return nil, nil
+	}
+	return access, nil
+}
+
+// UpdateAccess replaces a video's cultural protocol
+func (s *service) UpdateAccess(ctx context.Context, id string, input *AccessInput) (*VideoMetadata, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return nil, NewValidationError("access settings are required", nil)
+	}
+	defer s.videoLocks.lock(id)()
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+
+	access, err := s.newAccess(input)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(metadata.Access)
+	metadata.Access = access
+
+	if err := s.repo.SaveMetadata(ctx, metadata); err != nil {
+		return nil, NewInternalError("failed to save metadata", err)
+	}
+
+	s.recordAudit(ctx, AuditVideoAccess, id, before, metadata.Access)
+
+	s.log(ctx, "update_access", id).Info("Updated access for video %s", id)
+	return metadata, nil
+}
@@ -0,0 +1,83 @@
+package video
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gooji/internal/middleware"
+)
+
+// accessFormInput reads cultural protocol fields from an upload form.
+// It returns nil when no access fields were submitted, leaving the video public.
+func accessFormInput(r *http.Request) (*AccessInput, error) {
+	level := strings.TrimSpace(r.FormValue("access_level"))
+	start := strings.TrimSpace(r.FormValue("access_season_start"))
+	end := strings.TrimSpace(r.FormValue("access_season_end"))
+	if level == "" && start == "" && end == "" {
+		return nil, nil
+	}
+
+	input := &AccessInput{
+		Level: level,
+		Roles: strings.Split(r.FormValue("access_roles"), ","),
+	}
+	if start != "" || end != "" {
+		input.Seasons = []SeasonalWindow{{
+			Label: r.FormValue("access_season_label"),
+			Start: start,
+			End:   end,
+		}}
+	}
+	return input, nil
+}
+
+// HandleAccess handles /api/videos/{id}/access
+func (h *Handler) HandleAccess(w http.ResponseWriter, r *http.Request) {
+	id, _ := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		metadata, err := h.service.GetVideo(r.Context(), id)
+		if err != nil {
+			h.handleServiceError(w, r, err)
+			return
+		}
+		access := metadata.Access
+		if access == nil {
+			access = &Access{Level: AccessPublic}
+		}
+		h.writeJSONResponse(w, access)
+	case http.MethodPut:
+		h.UpdateAccess(w, r, id)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// UpdateAccess replaces a video's cultural protocol from a JSON body
+func (h *Handler) UpdateAccess(w http.ResponseWriter, r *http.Request, id string) {
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetadataBodySize)
+	var input AccessInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		h.handleValidationError(w, r, "Invalid access settings", err)
+		return
+	}
+
+	metadata, err := h.service.UpdateAccess(r.Context(), id, &input)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, metadata)
+}
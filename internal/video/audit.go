@@ -0,0 +1,85 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+
+	"gooji/internal/audit"
+)
+
+// Audit actions recorded by the service
+const (
+	AuditVideoUpload      = "video.upload"
+	AuditVideoDelete      = "video.delete"
+	AuditVideoMetadata    = "video.metadata.update"
+	AuditVideoAccess      = "video.access.update"
+	AuditVideoReview      = "video.review"
+	AuditVideoLink        = "video.link.create"
+	AuditVideoExpire      = "video.retention.expire"
+	AuditCaptionsSave     = "video.captions.save"
+	AuditCaptionsDelete   = "video.captions.delete"
+	AuditCaptionsBurnIn   = "video.captions.burn_in"
+	AuditSegmentCreate    = "video.transcript.segment.create"
+	AuditSegmentUpdate    = "video.transcript.segment.update"
+	AuditSegmentDelete    = "video.transcript.segment.delete"
+	AuditCollectionCreate = "collection.create"
+	AuditCollectionUpdate = "collection.update"
+	AuditCollectionDelete = "collection.delete"
+)
+
+// AuditRecorder records mutating operations in the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, event *audit.Event) error
+}
+
+// recordAudit records an operation by the viewer in ctx. The operation has
+// already happened, so failures are logged rather than returned.
+func (s *service) recordAudit(ctx context.Context, action, target string, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Record(ctx, &audit.Event{
+		Action: action,
+		Target: target,
+		Actor:  auditActor(ViewerFromContext(ctx)),
+		Before: before,
+		After:  after,
+	}); err != nil {
+		s.log(ctx, "", "").Error("Failed to record audit entry %s for %s: %v", action, target, err)
+	}
+}
+
+// auditActor describes a viewer for the audit log
+func auditActor(viewer *Viewer) audit.Actor {
+	actor := audit.Actor{
+		Name:    viewer.Name,
+		Role:    string(viewer.Role),
+		UserID:  viewer.UserID,
+		TokenID: viewer.TokenID,
+	}
+	if viewer.Device != nil {
+		actor.DeviceID = viewer.Device.ID
+		actor.DeviceName = viewer.Device.Name
+	}
+	if actor.Name == "" {
+		switch {
+		case actor.DeviceName != "":
+			actor.Name = actor.DeviceName
+		case viewer.Kiosk:
+			actor.Name = "Kiosk"
+		default:
+			actor.Name = "Anonymous"
+		}
+	}
+	return actor
+}
+
+// snapshot captures a value's current state for a before or after record,
+// so later changes to the value do not alter what is recorded
+func snapshot(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
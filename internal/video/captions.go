@@ -0,0 +1,283 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gooji/internal/auth"
+	"gooji/internal/storage"
+	"gooji/pkg/captions"
+	"gooji/pkg/ffmpeg"
+)
+
+// maxCaptionSize limits the size of an uploaded caption file
+const maxCaptionSize = 2 << 20
+
+// CaptionTrack describes a caption or transcript track attached to a video
+type CaptionTrack struct {
+	Language  string    `json:"language"`
+	Label     string    `json:"label,omitempty"`
+	CueCount  int       `json:"cue_count"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CaptionBurner defines the interface for rendering captions into video frames
+type CaptionBurner interface {
+	BurnSubtitles(ctx context.Context, inputPath, subtitlePath, outputPath string) error
+}
+
+// SaveCaptions validates a WebVTT or SRT track and stores it as WebVTT for a video and language.
+// An empty format is detected from the content.
+func (s *service) SaveCaptions(ctx context.Context, id, lang string, data []byte, format captions.Format, label string) (*CaptionTrack, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, NewValidationError("video ID is required", nil)
+	}
+	tag, err := canonicalLanguage(lang)
+	if err != nil {
+		return nil, NewValidationError("invalid caption language", err)
+	}
+	if len(data) == 0 {
+		return nil, NewValidationError("caption file is empty", nil)
+	}
+	if len(data) > maxCaptionSize {
+		return nil, NewValidationError(fmt.Sprintf("caption file exceeds maximum size of %d bytes", maxCaptionSize), nil)
+	}
+
+	defer s.videoLocks.lock(id)()
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+
+	if format == "" {
+		format = captions.DetectFormat(data)
+	}
+	track, err := captions.Parse(data, format)
+	if err != nil {
+		return nil, NewValidationError("invalid caption file", err)
+	}
+	for i := range track.Cues {
+		track.Cues[i].Text = s.sanitizer.Clean(track.Cues[i].Text, 0, true)
+	}
+
+	// Tracks are always stored as WebVTT, which browsers can play directly
+	vtt, err := track.Encode(captions.FormatWebVTT)
+	if err != nil {
+		return nil, NewInternalError("failed to encode captions", err)
+	}
+	if err := s.repo.SaveCaption(ctx, id, tag, vtt); err != nil {
+		return nil, NewInternalError("failed to save captions", err)
+	}
+
+	before := snapshot(metadata.Captions)
+	captionTrack := CaptionTrack{
+		Language:  tag,
+		Label:     s.sanitizer.Title(label),
+		CueCount:  len(track.Cues),
+		UpdatedAt: time.Now(),
+	}
+	metadata.setCaptionTrack(captionTrack)
+	if err := s.repo.SaveMetadata(ctx, metadata); err != nil {
+		return nil, NewInternalError("failed to save metadata", err)
+	}
+
+	s.recordAudit(ctx, AuditCaptionsSave, id, before, metadata.Captions)
+
+	s.log(ctx, "save_captions", id).Info("Successfully saved %s captions for video: %s", tag, id)
+	return &captionTrack, nil
+}
+
+// GetCaptions returns a video's caption track in the requested format
+func (s *service) GetCaptions(ctx context.Context, id, lang string, format captions.Format) ([]byte, error) {
+	tag, err := canonicalLanguage(lang)
+	if err != nil {
+		return nil, NewValidationError("invalid caption language", err)
+	}
+	if _, err := s.GetVideo(ctx, id); err != nil {
+		return nil, err
+	}
+
+	vtt, err := s.repo.GetCaption(ctx, id, tag)
+	if err != nil {
+		return nil, NewNotFoundError("captions not found", err)
+	}
+	if format == "" || format == captions.FormatWebVTT {
+		return vtt, nil
+	}
+
+	track, err := captions.Parse(vtt, captions.FormatWebVTT)
+	if err != nil {
+		return nil, NewInternalError("stored captions are invalid", err)
+	}
+	converted, err := track.Encode(format)
+	if err != nil {
+		return nil, NewValidationError("unsupported caption format", err)
+	}
+	return converted, nil
+}
+
+// ListCaptions returns the caption tracks attached to a video
+func (s *service) ListCaptions(ctx context.Context, id string) ([]CaptionTrack, error) {
+	metadata, err := s.GetVideo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Captions == nil {
+		return []CaptionTrack{}, nil
+	}
+	return metadata.Captions, nil
+}
+
+// DeleteCaptions removes a video's caption track for a language
+func (s *service) DeleteCaptions(ctx context.Context, id, lang string) error {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return err
+	}
+	tag, err := canonicalLanguage(lang)
+	if err != nil {
+		return NewValidationError("invalid caption language", err)
+	}
+
+	defer s.videoLocks.lock(id)()
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return NewNotFoundError("video not found", err)
+	}
+	before := snapshot(metadata.Captions)
+	if !metadata.removeCaptionTrack(tag) {
+		return NewNotFoundError("captions not found", nil)
+	}
+
+	if err := s.repo.DeleteCaption(ctx, id, tag); err != nil {
+		return NewInternalError("failed to delete captions", err)
+	}
+	if err := s.repo.SaveMetadata(ctx, metadata); err != nil {
+		return NewInternalError("failed to save metadata", err)
+	}
+
+	s.recordAudit(ctx, AuditCaptionsDelete, id, before, metadata.Captions)
+
+	s.log(ctx, "delete_captions", id).Info("Successfully deleted %s captions for video: %s", tag, id)
+	return nil
+}
+
+// BurnInCaptions renders a caption track into a new copy of the video.
+// The copy is stored as a separate video that records its source; a language
+// is burned in once, so an existing copy must be deleted before burning again.
+func (s *service) BurnInCaptions(ctx context.Context, id, lang string) (*VideoMetadata, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if s.captionBurner == nil {
+		return nil, NewInternalError("caption burn-in is not available", nil)
+	}
+	tag, err := canonicalLanguage(lang)
+	if err != nil {
+		return nil, NewValidationError("invalid caption language", err)
+	}
+
+	// Holding the source keeps its captions from changing or disappearing mid-render
+	defer s.videoLocks.lock(id)()
+	source, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+	if err := s.authorizeView(ctx, source); err != nil {
+		return nil, err
+	}
+	if !source.hasCaptionTrack(tag) {
+		return nil, NewNotFoundError("captions not found", nil)
+	}
+
+	// An existing copy may already be approved; replacing it would discard its review
+	filename := fmt.Sprintf("%s_%s_captioned.mp4", strings.TrimSuffix(source.Filename, filepath.Ext(source.Filename)), tag)
+	defer s.videoLocks.lock(filename)()
+	if _, err := s.repo.GetMetadata(ctx, filename); err == nil || s.repo.VideoExists(ctx, filename) {
+		return nil, NewConflictError(fmt.Sprintf("a captioned copy %s already exists; delete it before burning in again", filename), nil)
+	}
+
+	// The copy is about as large as its source
+	if err := s.checkStorageSpace(s.videoSize(source)); err != nil {
+		return nil, err
+	}
+
+	outputPath := s.repo.VideoPath(filename)
+	if err := s.captionBurner.BurnSubtitles(ctx, s.repo.VideoPath(source.Filename), s.repo.CaptionPath(id, tag), outputPath); err != nil {
+		if errors.Is(err, ffmpeg.ErrBusy) {
+			return nil, NewBusyError("video processing is busy, try again shortly", err)
+		}
+		return nil, NewInternalError("failed to burn in captions", err)
+	}
+
+	burned := &VideoMetadata{
+		ID:           filename,
+		Filename:     filename,
+		Title:        source.Title,
+		Description:  source.Description,
+		Language:     source.Language,
+		Titles:       source.Titles,
+		Descriptions: source.Descriptions,
+		Keywords:     source.Keywords,
+		Duration:     source.Duration,
+		Size:         storage.FileSize(outputPath),
+		CreatedAt:    time.Now(),
+		Tags:         source.Tags,
+		SourceID:     source.ID,
+		Consent:      source.Consent,
+		Access:       source.Access,
+	}
+	burned.transition(ReviewPending, systemReviewer, "captioned copy of "+source.ID)
+	if err := s.repo.SaveMetadata(ctx, burned); err != nil {
+		if cleanupErr := s.repo.DeleteVideo(ctx, filename); cleanupErr != nil {
+			s.log(ctx, "burn_captions", id).Error("Failed to cleanup captioned video after metadata save error: %v", cleanupErr)
+		}
+		return nil, NewInternalError("failed to save metadata", err)
+	}
+
+	if err := s.GenerateThumbnail(ctx, outputPath); err != nil {
+		s.log(ctx, "burn_captions", id).Error("Failed to generate thumbnail for %s: %v", outputPath, err)
+	}
+
+	s.recordAudit(ctx, AuditCaptionsBurnIn, burned.ID, nil, burned)
+	s.log(ctx, "burn_captions", id).Info("Successfully burned %s captions into video %s as %s", tag, id, filename)
+	return burned, nil
+}
+
+// setCaptionTrack adds or replaces the track for the track's language
+func (m *VideoMetadata) setCaptionTrack(track CaptionTrack) {
+	for i := range m.Captions {
+		if m.Captions[i].Language == track.Language {
+			m.Captions[i] = track
+			return
+		}
+	}
+	m.Captions = append(m.Captions, track)
+}
+
+// removeCaptionTrack removes the track for a language, reporting whether it existed
+func (m *VideoMetadata) removeCaptionTrack(lang string) bool {
+	for i := range m.Captions {
+		if m.Captions[i].Language == lang {
+			m.Captions = append(m.Captions[:i], m.Captions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// hasCaptionTrack reports whether the video has a track for a language
+func (m *VideoMetadata) hasCaptionTrack(lang string) bool {
+	for i := range m.Captions {
+		if m.Captions[i].Language == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package video
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"gooji/pkg/captions"
+)
+
+// HandleCaptionList handles /api/videos/{id}/captions
+func (h *Handler) HandleCaptionList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	id, _ := videoPathParts(r.URL.Path)
+	tracks, err := h.service.ListCaptions(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, tracks)
+}
+
+// HandleCaptions handles /api/videos/{id}/captions/{lang}[/burn]
+func (h *Handler) HandleCaptions(w http.ResponseWriter, r *http.Request) {
+	id, resource := videoPathParts(r.URL.Path)
+	parts := strings.Split(strings.TrimPrefix(resource, "captions/"), "/")
+	lang := parts[0]
+	if id == "" || lang == "" {
+		h.handleValidationError(w, r, "Missing video ID or caption language", nil)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "burn" {
+		if r.Method != http.MethodPost {
+			h.handleMethodNotAllowed(w, r)
+			return
+		}
+		h.BurnInCaptions(w, r, id, lang)
+		return
+	}
+	if len(parts) != 1 {
+		h.handleNotFoundError(w, r, "Unknown caption resource", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetCaptions(w, r, id, lang)
+	case http.MethodPut, http.MethodPost:
+		h.UploadCaptions(w, r, id, lang)
+	case http.MethodDelete:
+		h.DeleteCaptions(w, r, id, lang)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// GetCaptions downloads a caption track as WebVTT (default) or SRT via ?format=srt
+func (h *Handler) GetCaptions(w http.ResponseWriter, r *http.Request, id, lang string) {
+	format := captions.FormatWebVTT
+	if name := r.URL.Query().Get("format"); name != "" {
+		parsed, err := captions.ParseFormat(name)
+		if err != nil {
+			h.handleValidationError(w, r, "Unsupported caption format", err)
+			return
+		}
+		format = parsed
+	}
+
+	data, err := h.service.GetCaptions(r.Context(), id, lang, format)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if r.URL.Query().Get("download") != "" {
+		filename := strings.TrimSuffix(id, filepath.Ext(id)) + "." + lang + "." + string(format)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	if _, err := w.Write(data); err != nil {
+		h.log(r).Error("Failed to write captions response: %v", err)
+	}
+}
+
+// UploadCaptions stores a WebVTT or SRT track sent either as a multipart "captions"
+// file or as the raw request body
+func (h *Handler) UploadCaptions(w http.ResponseWriter, r *http.Request, id, lang string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCaptionSize+64*1024)
+
+	var (
+		data     []byte
+		format   captions.Format
+		label    = r.URL.Query().Get("label")
+		readErr  error
+		filename string
+	)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("captions")
+		if err != nil {
+			h.handleValidationError(w, r, "Failed to get caption file", err)
+			return
+		}
+		defer file.Close()
+		data, readErr = io.ReadAll(file)
+		filename = header.Filename
+		if formLabel := r.FormValue("label"); formLabel != "" {
+			label = formLabel
+		}
+	} else {
+		data, readErr = io.ReadAll(r.Body)
+	}
+	if readErr != nil {
+		h.handleValidationError(w, r, "Failed to read caption file", readErr)
+		return
+	}
+
+	// Prefer an explicit format, then the file extension, then content detection
+	if name := r.URL.Query().Get("format"); name != "" {
+		parsed, err := captions.ParseFormat(name)
+		if err != nil {
+			h.handleValidationError(w, r, "Unsupported caption format", err)
+			return
+		}
+		format = parsed
+	} else if parsed, err := captions.ParseFormat(filepath.Ext(filename)); err == nil {
+		format = parsed
+	}
+
+	track, err := h.service.SaveCaptions(r.Context(), id, lang, data, format, label)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, track)
+}
+
+// DeleteCaptions removes a caption track
+func (h *Handler) DeleteCaptions(w http.ResponseWriter, r *http.Request, id, lang string) {
+	if err := h.service.DeleteCaptions(r.Context(), id, lang); err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	response := map[string]string{
+		"message":  "Captions deleted successfully",
+		"id":       id,
+		"language": lang,
+	}
+	h.writeJSONResponse(w, response)
+}
+
+// BurnInCaptions renders a caption track into a new captioned copy of the video
+func (h *Handler) BurnInCaptions(w http.ResponseWriter, r *http.Request, id, lang string) {
+	metadata, err := h.service.BurnInCaptions(r.Context(), id, lang)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONStatus(w, http.StatusCreated, metadata)
+}
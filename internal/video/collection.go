@@ -0,0 +1,275 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gooji/internal/auth"
+)
+
+// Collection groups videos into an ordered lesson or playlist
+type Collection struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CoverImage  string    `json:"cover_image"`
+	VideoIDs    []string  `json:"video_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionInput represents the editable fields of a collection
+type CollectionInput struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	CoverImage  string   `json:"cover_image"`
+	VideoIDs    []string `json:"video_ids"`
+}
+
+// CollectionDetail is a collection with its videos resolved in playback order
+type CollectionDetail struct {
+	Collection
+	Videos []VideoMetadata `json:"videos"`
+}
+
+// maxCollectionVideos limits the number of videos in a single collection
+const maxCollectionVideos = 500
+
+// CreateCollection creates a new collection from the given input
+func (s *service) CreateCollection(ctx context.Context, input *CollectionInput) (*Collection, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return nil, NewValidationError("collection is required", nil)
+	}
+
+	now := time.Now()
+	collection := &Collection{
+		ID:        fmt.Sprintf("col_%d_%s", now.Unix(), generateUUID()),
+		CreatedAt: now,
+	}
+	if err := s.applyCollectionInput(ctx, collection, input); err != nil {
+		return nil, err
+	}
+	collection.UpdatedAt = now
+
+	if err := s.repo.SaveCollection(ctx, collection); err != nil {
+		return nil, NewInternalError("failed to save collection", err)
+	}
+
+	s.recordAudit(ctx, AuditCollectionCreate, collection.ID, nil, collection)
+	s.log(ctx, "create_collection", "").Info("Successfully created collection: %s", collection.ID)
+	return collection, nil
+}
+
+// GetCollection retrieves a collection and resolves its videos in order
+func (s *service) GetCollection(ctx context.Context, id string) (*CollectionDetail, error) {
+	if id == "" {
+		return nil, NewValidationError("collection ID is required", nil)
+	}
+
+	collection, err := s.repo.GetCollection(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("collection not found", err)
+	}
+
+	detail := &CollectionDetail{
+		Collection: *collection,
+		Videos:     make([]VideoMetadata, 0, len(collection.VideoIDs)),
+	}
+	for _, videoID := range collection.VideoIDs {
+		metadata, err := s.repo.GetMetadata(ctx, videoID)
+		if err != nil {
+			// Videos deleted after being added are skipped rather than failing the whole collection
+			s.log(ctx, "get_collection", videoID).Debug("Skipping missing video %s in collection %s: %v", videoID, id, err)
+			continue
+		}
+		if s.authorizeView(ctx, metadata) != nil {
+			continue
+		}
+		detail.Videos = append(detail.Videos, *metadata)
+	}
+
+	viewable := make(map[string]bool, len(detail.Videos))
+	for i := range detail.Videos {
+		viewable[detail.Videos[i].ID] = true
+	}
+	detail.Collection = collection.forViewer(viewable)
+	return detail, nil
+}
+
+// ListCollections retrieves all collections, listing only the videos the caller may see
+func (s *service) ListCollections(ctx context.Context) ([]Collection, error) {
+	collections, err := s.repo.ListCollections(ctx)
+	if err != nil {
+		return nil, NewInternalError("failed to list collections", err)
+	}
+
+	videos, err := s.repo.ListMetadata(ctx)
+	if err != nil {
+		return nil, NewInternalError("failed to list videos", err)
+	}
+	viewable := make(map[string]bool, len(videos))
+	for i := range videos {
+		if s.authorizeView(ctx, &videos[i]) == nil {
+			viewable[videos[i].ID] = true
+		}
+	}
+
+	for i := range collections {
+		collections[i] = collections[i].forViewer(viewable)
+	}
+	return collections, nil
+}
+
+// UpdateCollection replaces the editable fields of an existing collection
+func (s *service) UpdateCollection(ctx context.Context, id string, input *CollectionInput) (*Collection, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, NewValidationError("collection ID is required", nil)
+	}
+	if input == nil {
+		return nil, NewValidationError("collection is required", nil)
+	}
+
+	defer s.collectionLocks.lock(id)()
+	collection, err := s.repo.GetCollection(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("collection not found", err)
+	}
+
+	// Videos the caller cannot see were never sent to them, so they stay in the collection
+	var hidden []string
+	viewable := make(map[string]bool, len(collection.VideoIDs))
+	for _, videoID := range collection.VideoIDs {
+		metadata, err := s.repo.GetMetadata(ctx, videoID)
+		if err != nil {
+			continue
+		}
+		if s.authorizeView(ctx, metadata) != nil {
+			hidden = append(hidden, videoID)
+			continue
+		}
+		viewable[videoID] = true
+	}
+
+	before := snapshot(collection)
+	if err := s.applyCollectionInput(ctx, collection, input); err != nil {
+		return nil, err
+	}
+	for _, videoID := range collection.VideoIDs {
+		viewable[videoID] = true
+	}
+	collection.VideoIDs = append(collection.VideoIDs, hidden...)
+	if len(collection.VideoIDs) > maxCollectionVideos {
+		return nil, NewValidationError(fmt.Sprintf("a collection may contain at most %d videos", maxCollectionVideos), nil)
+	}
+	collection.UpdatedAt = time.Now()
+
+	if err := s.repo.SaveCollection(ctx, collection); err != nil {
+		return nil, NewInternalError("failed to save collection", err)
+	}
+
+	s.recordAudit(ctx, AuditCollectionUpdate, collection.ID, before, collection)
+	s.log(ctx, "update_collection", "").Info("Successfully updated collection: %s", collection.ID)
+	updated := collection.forViewer(viewable)
+	return &updated, nil
+}
+
+// DeleteCollection removes a collection without touching its videos
+func (s *service) DeleteCollection(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return err
+	}
+	if id == "" {
+		return NewValidationError("collection ID is required", nil)
+	}
+
+	defer s.collectionLocks.lock(id)()
+	var before *Collection
+	if collection, err := s.repo.GetCollection(ctx, id); err == nil {
+		before = collection
+	}
+
+	if err := s.repo.DeleteCollection(ctx, id); err != nil {
+		return NewNotFoundError("collection not found", err)
+	}
+
+	s.recordAudit(ctx, AuditCollectionDelete, id, before, nil)
+
+	s.log(ctx, "delete_collection", "").Info("Successfully deleted collection: %s", id)
+	return nil
+}
+
+// applyCollectionInput validates input and copies it onto a collection
+func (s *service) applyCollectionInput(ctx context.Context, collection *Collection, input *CollectionInput) error {
+	title := s.sanitizer.Title(input.Title)
+	if title == "" {
+		return NewValidationError("collection title is required", nil)
+	}
+	if len(input.VideoIDs) > maxCollectionVideos {
+		return NewValidationError(fmt.Sprintf("a collection may contain at most %d videos", maxCollectionVideos), nil)
+	}
+
+	videoIDs := make([]string, 0, len(input.VideoIDs))
+	seen := make(map[string]bool, len(input.VideoIDs))
+	for _, videoID := range input.VideoIDs {
+		if !validVideoID(videoID) {
+			return NewValidationError(fmt.Sprintf("invalid video ID %q", videoID), nil)
+		}
+		if seen[videoID] {
+			return NewValidationError(fmt.Sprintf("video %s appears more than once", videoID), nil)
+		}
+		// Videos the caller may not see are reported as missing, so IDs cannot be probed
+		metadata, err := s.repo.GetMetadata(ctx, videoID)
+		if err != nil || s.authorizeView(ctx, metadata) != nil {
+			return NewValidationError(fmt.Sprintf("video %s does not exist", videoID), err)
+		}
+		seen[videoID] = true
+		videoIDs = append(videoIDs, videoID)
+	}
+
+	// The cover image is the thumbnail of one of the collection's videos
+	coverImage := input.CoverImage
+	if coverImage == "" && len(videoIDs) > 0 {
+		coverImage = videoIDs[0]
+	}
+	if coverImage != "" && !seen[coverImage] {
+		return NewValidationError("cover image must reference a video in the collection", nil)
+	}
+
+	collection.Title = title
+	collection.Description = s.sanitizer.Description(input.Description)
+	collection.CoverImage = coverImage
+	collection.VideoIDs = videoIDs
+	return nil
+}
+
+// forViewer returns a copy of the collection listing only viewable videos.
+// A cover the viewer may not see falls back to the first video they may.
+func (c Collection) forViewer(viewable map[string]bool) Collection {
+	videoIDs := make([]string, 0, len(c.VideoIDs))
+	for _, videoID := range c.VideoIDs {
+		if viewable[videoID] {
+			videoIDs = append(videoIDs, videoID)
+		}
+	}
+	c.VideoIDs = videoIDs
+	if !viewable[c.CoverImage] {
+		c.CoverImage = ""
+		if len(videoIDs) > 0 {
+			c.CoverImage = videoIDs[0]
+		}
+	}
+	return c
+}
+
+// validVideoID reports whether id names a video file rather than a path
+func validVideoID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`) && !strings.Contains(id, "..")
+}
@@ -0,0 +1,184 @@
+package video
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gooji/internal/middleware"
+)
+
+// maxCollectionBodySize limits the size of collection request bodies
+const maxCollectionBodySize = 1 << 20
+
+// HandleCollections handles collection list and create endpoints
+func (h *Handler) HandleCollections(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListCollections(w, r)
+	case http.MethodPost:
+		h.CreateCollection(w, r)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// HandleCollection handles individual collection endpoints
+func (h *Handler) HandleCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetCollection(w, r)
+	case http.MethodPut:
+		h.UpdateCollection(w, r)
+	case http.MethodDelete:
+		h.DeleteCollection(w, r)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// ListCollections returns all collections
+func (h *Handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := h.service.ListCollections(r.Context())
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, collections)
+}
+
+// CreateCollection creates a collection from a JSON body
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	input, err := h.decodeCollectionInput(w, r)
+	if err != nil {
+		h.handleValidationError(w, r, "Invalid collection", err)
+		return
+	}
+
+	collection, err := h.service.CreateCollection(r.Context(), input)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONStatus(w, http.StatusCreated, collection)
+}
+
+// GetCollection returns a collection with its videos in playback order
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := idFromPath(r.URL.Path, "/api/collections/")
+	if !ok {
+		h.handleValidationError(w, r, "Missing collection ID", nil)
+		return
+	}
+
+	collection, err := h.service.GetCollection(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, collection)
+}
+
+// UpdateCollection replaces a collection's fields from a JSON body
+func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := idFromPath(r.URL.Path, "/api/collections/")
+	if !ok {
+		h.handleValidationError(w, r, "Missing collection ID", nil)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	input, err := h.decodeCollectionInput(w, r)
+	if err != nil {
+		h.handleValidationError(w, r, "Invalid collection", err)
+		return
+	}
+
+	collection, err := h.service.UpdateCollection(r.Context(), id, input)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, collection)
+}
+
+// DeleteCollection deletes a collection
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := idFromPath(r.URL.Path, "/api/collections/")
+	if !ok {
+		h.handleValidationError(w, r, "Missing collection ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteCollection(r.Context(), id); err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	response := map[string]string{
+		"message": "Collection deleted successfully",
+		"id":      id,
+	}
+	h.writeJSONResponse(w, response)
+}
+
+// HandleCollectionPage serves the sequential playback page for a collection
+func (h *Handler) HandleCollectionPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	id, ok := idFromPath(r.URL.Path, "/gallery/collections/")
+	if !ok {
+		h.handleValidationError(w, r, "Missing collection ID", nil)
+		return
+	}
+
+	collection, err := h.service.GetCollection(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	if err := h.templates["collection"].ExecuteTemplate(w, "base.html", map[string]interface{}{
+		"Page":         "gallery",
+		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
+		"Collection":   collection,
+	}); err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+}
+
+// decodeCollectionInput decodes a size-limited collection JSON body
+func (h *Handler) decodeCollectionInput(w http.ResponseWriter, r *http.Request) (*CollectionInput, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCollectionBodySize)
+
+	var input CollectionInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return nil, err
+	}
+	return &input, nil
+}
+
+// idFromPath extracts a single path segment following prefix
+func idFromPath(path, prefix string) (string, bool) {
+	id := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	if id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,221 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gooji/internal/signing"
+)
+
+// ConsentStatementVersion identifies the consent wording shown to participants.
+// Bump it whenever the statement on the record and upload pages changes.
+const ConsentStatementVersion = "2026-1"
+
+// ConsentScope describes how widely a participant agreed to share a recording
+type ConsentScope string
+
+const (
+	// ConsentScopeKiosk limits playback to the on-site kiosk
+	ConsentScopeKiosk ConsentScope = "kiosk-only"
+	// ConsentScopeCommunity allows playback at the kiosk and to community members
+	ConsentScopeCommunity ConsentScope = "community"
+	// ConsentScopeResearch additionally allows use by approved researchers
+	ConsentScopeResearch ConsentScope = "research"
+	// ConsentScopePublic allows playback to anyone
+	ConsentScopePublic ConsentScope = "public"
+)
+
+// ParseConsentScope validates a consent scope name
+func ParseConsentScope(name string) (ConsentScope, error) {
+	switch scope := ConsentScope(name); scope {
+	case ConsentScopeKiosk, ConsentScopeCommunity, ConsentScopeResearch, ConsentScopePublic:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown consent scope %q", name)
+	}
+}
+
+// Consent is the consent summary stored with a video and used to gate reads.
+// Guardian details live only in the signed ConsentRecord.
+type Consent struct {
+	Scope      ConsentScope `json:"scope"`
+	Speaker    string       `json:"speaker,omitempty"`
+	Anonymous  bool         `json:"anonymous"`
+	Minor      bool         `json:"minor,omitempty"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	RecordedAt time.Time    `json:"recorded_at"`
+}
+
+// ConsentInput represents the consent captured alongside an upload
+type ConsentInput struct {
+	SpeakerName     string       `json:"speaker_name"`
+	Anonymous       bool         `json:"anonymous"`
+	Scope           ConsentScope `json:"scope"`
+	Minor           bool         `json:"minor"`
+	GuardianName    string       `json:"guardian_name"`
+	GuardianConsent bool         `json:"guardian_consent"`
+	ExpiresAt       *time.Time   `json:"expires_at"`
+}
+
+// ConsentRecord is the full consent given for a recording
+type ConsentRecord struct {
+	VideoID          string       `json:"video_id"`
+	StatementVersion string       `json:"statement_version"`
+	SpeakerName      string       `json:"speaker_name,omitempty"`
+	Anonymous        bool         `json:"anonymous"`
+	Scope            ConsentScope `json:"scope"`
+	Minor            bool         `json:"minor"`
+	GuardianName     string       `json:"guardian_name,omitempty"`
+	GuardianConsent  bool         `json:"guardian_consent"`
+	ExpiresAt        *time.Time   `json:"expires_at,omitempty"`
+	RecordedAt       time.Time    `json:"recorded_at"`
+	RecordedAtKiosk  bool         `json:"recorded_at_kiosk"`
+}
+
+// SignedConsentRecord is a consent record with a signature over its JSON encoding,
+// created when consent is captured so later changes to the record can be detected
+type SignedConsentRecord struct {
+	Record    ConsentRecord `json:"record"`
+	Algorithm string        `json:"algorithm"`
+	KeyID     string        `json:"key_id"`
+	Signature string        `json:"signature"`
+}
+
+// permits reports why a viewer may not see a video with this consent, or nil if they may.
+// Videos recorded before consent capture have no consent and are treated as kiosk-only.
+func (c *Consent) permits(viewer *Viewer, now time.Time) error {
+	if c == nil {
+		if viewer.Kiosk {
+			return nil
+		}
+		return errors.New("video has no recorded consent and is only shown at the kiosk")
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return errors.New("consent for this video has expired")
+	}
+
+	allowed := false
+	switch c.Scope {
+	case ConsentScopePublic:
+		allowed = true
+	case ConsentScopeResearch:
+		allowed = viewer.Kiosk || viewer.Member || viewer.Researcher
+	case ConsentScopeCommunity:
+		allowed = viewer.Kiosk || viewer.Member
+	case ConsentScopeKiosk:
+		allowed = viewer.Kiosk
+	}
+	if !allowed {
+		return fmt.Errorf("consent scope %q does not permit this viewer", c.Scope)
+	}
+	return nil
+}
+
+// newConsentRecord validates and sanitizes consent input for a new recording
+func (s *service) newConsentRecord(ctx context.Context, input *ConsentInput) (*ConsentRecord, error) {
+	if input == nil {
+		return nil, NewValidationError("participant consent is required", nil)
+	}
+	scope, err := ParseConsentScope(string(input.Scope))
+	if err != nil {
+		return nil, NewValidationError("invalid consent scope", err)
+	}
+
+	record := &ConsentRecord{
+		StatementVersion: ConsentStatementVersion,
+		Anonymous:        input.Anonymous,
+		Scope:            scope,
+		Minor:            input.Minor,
+		RecordedAt:       time.Now().UTC(),
+		RecordedAtKiosk:  ViewerFromContext(ctx).Kiosk,
+	}
+
+	if !input.Anonymous {
+		record.SpeakerName = s.sanitizer.Title(input.SpeakerName)
+		if record.SpeakerName == "" {
+			return nil, NewValidationError("speaker name is required unless the participant is anonymous", nil)
+		}
+	}
+	if input.Minor {
+		record.GuardianName = s.sanitizer.Title(input.GuardianName)
+		record.GuardianConsent = input.GuardianConsent
+		if record.GuardianName == "" || !record.GuardianConsent {
+			return nil, NewValidationError("recordings of minors require a guardian's name and consent", nil)
+		}
+	}
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(record.RecordedAt) {
+			return nil, NewValidationError("consent expiry must be in the future", nil)
+		}
+		expiresAt := input.ExpiresAt.UTC()
+		record.ExpiresAt = &expiresAt
+	}
+
+	return record, nil
+}
+
+// summary returns the consent summary stored on a video's metadata
+func (r *ConsentRecord) summary() *Consent {
+	return &Consent{
+		Scope:      r.Scope,
+		Speaker:    r.SpeakerName,
+		Anonymous:  r.Anonymous,
+		Minor:      r.Minor,
+		ExpiresAt:  r.ExpiresAt,
+		RecordedAt: r.RecordedAt,
+	}
+}
+
+// signConsentRecord signs a consent record with the service's signer
+func (s *service) signConsentRecord(record *ConsentRecord) (*SignedConsentRecord, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode consent record: %w", err)
+	}
+	return &SignedConsentRecord{
+		Record:    *record,
+		Algorithm: signing.Algorithm,
+		KeyID:     s.signer.KeyID(),
+		Signature: s.signer.Sign(payload),
+	}, nil
+}
+
+// ExportConsent returns the signed consent record for a video.
+// Records contain guardian details, so they are only exported at the kiosk.
+func (s *service) ExportConsent(ctx context.Context, id string) (*SignedConsentRecord, error) {
+	if !ViewerFromContext(ctx).Kiosk {
+		return nil, NewSecurityError("consent records can only be exported at the kiosk", nil)
+	}
+
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+
+	// Burned-in copies share the consent given for their source recording
+	recordID := metadata.ID
+	if metadata.SourceID != "" {
+		recordID = metadata.SourceID
+	}
+	signed, err := s.repo.GetConsent(ctx, recordID)
+	if err != nil {
+		return nil, NewNotFoundError("consent record not found", err)
+	}
+
+	return signed, nil
+}
+
+// VerifyConsent reports whether a signed consent record was signed with the service's key
+func (s *service) VerifyConsent(signed *SignedConsentRecord) bool {
+	if signed == nil || signed.Algorithm != signing.Algorithm || signed.KeyID != s.signer.KeyID() {
+		return false
+	}
+	payload, err := json.Marshal(&signed.Record)
+	if err != nil {
+		return false
+	}
+	return s.signer.Verify(payload, signed.Signature)
+}
@@ -0,0 +1,123 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gooji/internal/middleware"
+)
+
+// consentFormInput reads consent fields from an upload form.
+// It returns nil when no consent scope was submitted so the service can reject the upload.
+func consentFormInput(r *http.Request) (*ConsentInput, error) {
+	scope := strings.TrimSpace(r.FormValue("consent_scope"))
+	if scope == "" {
+		return nil, nil
+	}
+
+	input := &ConsentInput{
+		SpeakerName:  r.FormValue("consent_speaker"),
+		Scope:        ConsentScope(scope),
+		GuardianName: r.FormValue("consent_guardian_name"),
+	}
+
+	flags := map[string]*bool{
+		"consent_anonymous":        &input.Anonymous,
+		"consent_minor":            &input.Minor,
+		"consent_guardian_consent": &input.GuardianConsent,
+	}
+	for field, target := range flags {
+		value, err := formBool(r.FormValue(field))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		*target = value
+	}
+
+	if value := strings.TrimSpace(r.FormValue("consent_expires")); value != "" {
+		expiresAt, err := parseConsentExpiry(value)
+		if err != nil {
+			return nil, err
+		}
+		input.ExpiresAt = &expiresAt
+	}
+
+	return input, nil
+}
+
+// formBool parses a checkbox or boolean form value; empty means false
+func formBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "off":
+		return false, nil
+	case "on", "yes":
+		return true, nil
+	default:
+		return strconv.ParseBool(value)
+	}
+}
+
+// parseConsentExpiry accepts an RFC 3339 timestamp or a date, which expires at the end of that day (UTC)
+func parseConsentExpiry(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid consent expiry %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// HandleConsent handles /api/videos/{id}/consent, exporting the signed consent record
+func (h *Handler) HandleConsent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	id, _ := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	signed, err := h.service.ExportConsent(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="consent-record.json"`)
+	}
+	h.writeJSONResponse(w, signed)
+}
+
+// HandleConsentVerify handles POST /api/consent/verify, checking the signature of an exported record
+func (h *Handler) HandleConsentVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetadataBodySize)
+	var signed SignedConsentRecord
+	if err := json.NewDecoder(r.Body).Decode(&signed); err != nil {
+		h.handleValidationError(w, r, "Invalid consent record", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"valid":    h.service.VerifyConsent(&signed),
+		"video_id": signed.Record.VideoID,
+	}
+	h.writeJSONResponse(w, response)
+}
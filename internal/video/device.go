@@ -0,0 +1,57 @@
+package video
+
+import (
+	"context"
+
+	"gooji/internal/auth"
+)
+
+// DeviceRef records the paired kiosk a video was recorded on. Name and location
+// are copied at upload time so they survive renaming or revoking the device.
+type DeviceRef struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location,omitempty"`
+}
+
+// deviceRef returns the reference for a paired device, or nil
+func deviceRef(device *auth.Device) *DeviceRef {
+	if device == nil {
+		return nil
+	}
+	return &DeviceRef{ID: device.ID, Name: device.Name, Location: device.Location}
+}
+
+// DeviceUploadStats summarizes uploads per paired device, keyed by device ID
+func (s *service) DeviceUploadStats(ctx context.Context) (map[string]auth.DeviceStats, error) {
+	if err := requirePermission(ctx, auth.PermManageDevices); err != nil {
+		return nil, err
+	}
+
+	videos, err := s.repo.ListMetadata(ctx)
+	if err != nil {
+		return nil, NewInternalError("failed to list videos", err)
+	}
+
+	stats := make(map[string]auth.DeviceStats)
+	for i := range videos {
+		device := videos[i].Device
+		if device == nil {
+			continue
+		}
+		entry := stats[device.ID]
+		entry.Uploads++
+		entry.TotalDuration += videos[i].Duration
+		if entry.LastUploadAt == nil || videos[i].CreatedAt.After(*entry.LastUploadAt) {
+			created := videos[i].CreatedAt
+			entry.LastUploadAt = &created
+		}
+		stats[device.ID] = entry
+	}
+	return stats, nil
+}
+
+// DeviceUploadStats reports per-device upload statistics for the device admin API
+func (h *Handler) DeviceUploadStats(ctx context.Context) (map[string]auth.DeviceStats, error) {
+	return h.service.DeviceUploadStats(ctx)
+}
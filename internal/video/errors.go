@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // ErrorType represents the type of error
@@ -20,8 +21,17 @@ const (
 	ErrorTypeSecurity ErrorType = "security"
 	// ErrorTypeUpload represents upload-related errors
 	ErrorTypeUpload ErrorType = "upload"
+	// ErrorTypeConflict represents edits that conflict with a newer version
+	ErrorTypeConflict ErrorType = "conflict"
+	// ErrorTypeBusy represents work refused because video processing is at capacity
+	ErrorTypeBusy ErrorType = "busy"
+	// ErrorTypeQuota represents writes refused because storage is low or a quota is used up
+	ErrorTypeQuota ErrorType = "quota"
 )
 
+// busyRetryAfter is how long clients are asked to wait after a busy error
+const busyRetryAfter = 30 * time.Second
+
 // VideoError represents a structured error with context
 type VideoError struct {
 	Type    ErrorType `json:"type"`
@@ -93,6 +103,38 @@ func NewUploadError(message string, err error) *VideoError {
 	}
 }
 
+// NewConflictError creates a new conflict error
+func NewConflictError(message string, err error) *VideoError {
+	return &VideoError{
+		Type:    ErrorTypeConflict,
+		Message: message,
+		Code:    http.StatusConflict,
+		Err:     err,
+	}
+}
+
+// NewBusyError creates a new error for work refused while video processing is at capacity
+func NewBusyError(message string, err error) *VideoError {
+	return &VideoError{
+		Type:    ErrorTypeBusy,
+		Message: message,
+		Code:    http.StatusTooManyRequests,
+		Err:     err,
+	}
+}
+
+// NewQuotaError creates a new error for writes refused because storage is low
+// or a quota is used up. Its message is shown to the user, so it should say
+// what to do about it.
+func NewQuotaError(message string, err error) *VideoError {
+	return &VideoError{
+		Type:    ErrorTypeQuota,
+		Message: message,
+		Code:    http.StatusInsufficientStorage,
+		Err:     err,
+	}
+}
+
 // IsValidationError checks if an error is a validation error
 func IsValidationError(err error) bool {
 	var videoErr *VideoError
@@ -120,6 +162,15 @@ func IsSecurityError(err error) bool {
 	return false
 }
 
+// IsConflictError checks if an error is a conflict error
+func IsConflictError(err error) bool {
+	var videoErr *VideoError
+	if errors.As(err, &videoErr) {
+		return videoErr.Type == ErrorTypeConflict
+	}
+	return false
+}
+
 // GetHTTPStatusCode returns the appropriate HTTP status code for an error
 func GetHTTPStatusCode(err error) int {
 	var videoErr *VideoError
@@ -2,17 +2,23 @@ package video
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"gooji/internal/config"
+	"gooji/internal/health"
 	"gooji/internal/logger"
+	"gooji/internal/metrics"
+	"gooji/internal/middleware"
+	"gooji/internal/signing"
+	"gooji/internal/storage"
 	"gooji/pkg/ffmpeg"
 )
 
@@ -22,10 +28,20 @@ type Handler struct {
 	templates map[string]*template.Template
 	logger    *logger.Logger
 	storage   *config.Storage
+	janitor   *Janitor
+	checks    []health.Checker
+	metrics   *metrics.Metrics
+	uploads   *UploadRules
+	limiter   *ffmpeg.Limiter
 }
 
 // NewHandler creates a new video handler
-func NewHandler(processor *ffmpeg.Processor, storage *config.Storage, log *logger.Logger) (*Handler, error) {
+func NewHandler(processor *ffmpeg.Processor, cfg *config.Config, auditLog AuditRecorder, m *metrics.Metrics, log *logger.Logger) (*Handler, error) {
+	// Measure storage against the quotas before uploads are written
+	meter := storage.NewMeter(&cfg.Storage, cfg.Quotas)
+
+	storage := &cfg.Storage
+
 	// Create storage directories
 	if err := createStorageDirectories(storage); err != nil {
 		return nil, fmt.Errorf("failed to create storage directories: %w", err)
@@ -34,12 +50,41 @@ func NewHandler(processor *ffmpeg.Processor, storage *config.Storage, log *logge
 	// Create secure processor with allowed directory restriction for uploads
 	secureProcessor := ffmpeg.NewProcessorWithSecurity(processor.FFmpegPath(), storage.Uploads)
 
-	// Create thumbnail processor that can access both uploads and thumbnails directories
+	// Create thumbnail processor that can access uploads, thumbnails and captions directories
 	thumbnailProcessor := ffmpeg.NewProcessorWithSecurity(processor.FFmpegPath(), storage.BasePath)
 
+	// Share one cap on concurrent FFmpeg processes between the processors
+	queueTimeout, err := time.ParseDuration(cfg.FFmpeg.QueueTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FFmpeg queue timeout %q: %w", cfg.FFmpeg.QueueTimeout, err)
+	}
+	limiter := ffmpeg.NewLimiter(cfg.FFmpeg.MaxConcurrent, queueTimeout)
+	secureProcessor.SetLimiter(limiter)
+	thumbnailProcessor.SetLimiter(limiter)
+	secureProcessor.SetObserver(m.ObserveFFmpeg)
+	thumbnailProcessor.SetObserver(m.ObserveFFmpeg)
+
+	// Load the key used to sign consent records
+	signer, err := signing.Load(cfg.Security.SigningKey, filepath.Join(storage.BasePath, "keys", "signing.key"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+
 	// Create repository and service
-	repo := NewRepository(storage, log)
-	service := NewService(repo, secureProcessor, thumbnailProcessor, log)
+	// Both are wrapped to record a span for each operation when tracing is on
+	repo := traceRepository(NewRepository(storage, log))
+	uploads := NewUploadRules(cfg.Video.MaxSize, cfg.Video.AllowedTypes)
+	service := traceService(NewService(repo, secureProcessor, thumbnailProcessor, thumbnailProcessor, NewSanitizer(cfg.Video.Limits), uploads, signer, meter, auditLog, log))
+
+	// Expire videos and remove stale files under the retention rules
+	janitor, err := NewJanitor(service, &cfg.Retention, m, log)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention settings: %w", err)
+	}
+
+	// Report queue depth, video counts and storage usage on each scrape
+	m.Register(queueCollectors(limiter)...)
+	m.Register(newVideoCollector(repo, meter, log))
 
 	// Parse templates
 	templates, err := parseTemplates()
@@ -58,9 +103,30 @@ func NewHandler(processor *ffmpeg.Processor, storage *config.Storage, log *logge
 		templates: templates,
 		logger:    log,
 		storage:   storage,
+		janitor:   janitor,
+		checks: []health.Checker{
+			{Name: "repository", Check: repo.Ping},
+			{Name: "disk_space", Check: health.DiskSpace(meter)},
+			{Name: "ffmpeg_queue", Check: health.Queue(limiter, cfg.Health.MaxQueue)},
+		},
+		metrics: m,
+		uploads: uploads,
+		limiter: limiter,
 	}, nil
 }
 
+// Reload applies the reloadable video settings from cfg: the upload size and
+// type limits and the FFmpeg concurrency cap and queue timeout
+func (h *Handler) Reload(cfg *config.Config) error {
+	queueTimeout, err := time.ParseDuration(cfg.FFmpeg.QueueTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid FFmpeg queue timeout %q: %w", cfg.FFmpeg.QueueTimeout, err)
+	}
+	h.uploads.Set(cfg.Video.MaxSize, cfg.Video.AllowedTypes)
+	h.limiter.SetLimits(cfg.FFmpeg.MaxConcurrent, queueTimeout)
+	return nil
+}
+
 // HandleHome serves the home page
 func (h *Handler) HandleHome(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -68,14 +134,15 @@ func (h *Handler) HandleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.logger.Debug("Serving home page")
-	h.logger.Debug("Available templates: %v", len(h.templates))
+	h.log(r).Debug("Serving home page")
+	h.log(r).Debug("Available templates: %v", len(h.templates))
 
 	if err := h.templates["index"].ExecuteTemplate(w, "index.html", map[string]interface{}{
 		"Page":         "home",
 		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
 	}); err != nil {
-		h.logger.Error("Template execution error: %v", err)
+		h.log(r).Error("Template execution error: %v", err)
 		h.handleInternalError(w, r, err)
 		return
 	}
@@ -88,11 +155,12 @@ func (h *Handler) HandleRecord(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.logger.Debug("Serving record page")
+	h.log(r).Debug("Serving record page")
 
 	if err := h.templates["record"].ExecuteTemplate(w, "base.html", map[string]interface{}{
 		"Page":         "record",
 		"IsRecordPage": true,
+		"Nonce":        middleware.CSPNonce(r.Context()),
 	}); err != nil {
 		h.handleInternalError(w, r, err)
 		return
@@ -106,11 +174,12 @@ func (h *Handler) HandleUploadPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.logger.Debug("Serving upload page")
+	h.log(r).Debug("Serving upload page")
 
 	if err := h.templates["upload"].ExecuteTemplate(w, "base.html", map[string]interface{}{
 		"Page":         "upload",
 		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
 	}); err != nil {
 		h.handleInternalError(w, r, err)
 		return
@@ -127,6 +196,7 @@ func (h *Handler) HandleEdit(w http.ResponseWriter, r *http.Request) {
 	if err := h.templates["editor"].ExecuteTemplate(w, "editor.html", map[string]interface{}{
 		"Page":         "edit",
 		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
 	}); err != nil {
 		h.handleInternalError(w, r, err)
 		return
@@ -143,23 +213,13 @@ func (h *Handler) HandleGallery(w http.ResponseWriter, r *http.Request) {
 	if err := h.templates["gallery"].ExecuteTemplate(w, "gallery.html", map[string]interface{}{
 		"Page":         "gallery",
 		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
 	}); err != nil {
 		h.handleInternalError(w, r, err)
 		return
 	}
 }
 
-// HandleHealth provides system health information
-func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		h.handleMethodNotAllowed(w, r)
-		return
-	}
-
-	health := h.getHealthStatus()
-	h.writeJSONResponse(w, health)
-}
-
 // HandleVideos handles video-related API endpoints
 func (h *Handler) HandleVideos(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -172,15 +232,43 @@ func (h *Handler) HandleVideos(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// HandleVideo handles individual video API endpoints
+// HandleVideo handles individual video API endpoints and their sub-resources
 func (h *Handler) HandleVideo(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetVideo(w, r)
-	case http.MethodDelete:
-		h.DeleteVideo(w, r)
+	_, resource := videoPathParts(r.URL.Path)
+	switch resource {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			h.GetVideo(w, r)
+		case http.MethodDelete:
+			h.DeleteVideo(w, r)
+		default:
+			h.handleMethodNotAllowed(w, r)
+		}
+	case "metadata":
+		h.HandleVideoMetadata(w, r)
+	case "captions":
+		h.HandleCaptionList(w, r)
+	case "transcript":
+		h.HandleTranscript(w, r)
+	case "consent":
+		h.HandleConsent(w, r)
+	case "access":
+		h.HandleAccess(w, r)
+	case "review":
+		h.HandleReview(w, r)
+	case "links":
+		h.HandleLinks(w, r)
 	default:
-		h.handleMethodNotAllowed(w, r)
+		if strings.HasPrefix(resource, "captions/") {
+			h.HandleCaptions(w, r)
+			return
+		}
+		if strings.HasPrefix(resource, "transcript/") {
+			h.HandleTranscript(w, r)
+			return
+		}
+		h.handleNotFoundError(w, r, "Unknown video resource", nil)
 	}
 }
 
@@ -200,15 +288,34 @@ func (h *Handler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
+	consent, err := consentFormInput(r)
+	if err != nil {
+		h.handleValidationError(w, r, "Invalid consent", err)
+		return
+	}
+
+	access, err := accessFormInput(r)
+	if err != nil {
+		h.handleValidationError(w, r, "Invalid access settings", err)
+		return
+	}
+
 	// Create upload metadata
 	metadata := &UploadMetadata{
-		Title:       r.FormValue("title"),
-		Description: r.FormValue("description"),
-		Tags:        []string{"ojibwe", "language", "culture"}, // Default tags
+		Title:        r.FormValue("title"),
+		Description:  r.FormValue("description"),
+		Language:     r.FormValue("language"),
+		Titles:       localizedFormValues(r, "title"),
+		Descriptions: localizedFormValues(r, "description"),
+		Keywords:     localizedFormKeywords(r, "keywords"),
+		Tags:         []string{"ojibwe", "language", "culture"}, // Default tags
+		Consent:      consent,
+		Access:       access,
 	}
 
 	// Process upload through service
 	videoMetadata, err := h.service.ProcessUpload(r.Context(), file, header, metadata)
+	h.metrics.ObserveUpload(uploadOutcome(err), header.Size)
 	if err != nil {
 		h.handleServiceError(w, r, err)
 		return
@@ -237,6 +344,23 @@ func (h *Handler) GetVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check the video exists and the viewer, or the signed link, may watch it
+	grant, signed, err := mediaGrant(r, "")
+	if err != nil {
+		h.handleValidationError(w, r, "Invalid signed link", err)
+		return
+	}
+	if signed {
+		if _, err := h.service.GetSignedVideo(r.Context(), id, grant); err != nil {
+			h.handleServiceError(w, r, err)
+			return
+		}
+		setSignedMediaHeaders(w, grant, id)
+	} else if _, err := h.service.GetVideo(r.Context(), id); err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
 	videoPath := filepath.Join(h.storage.Uploads, id)
 	if _, err := os.Stat(videoPath); os.IsNotExist(err) {
 		h.handleNotFoundError(w, r, "Video not found", err)
@@ -292,6 +416,23 @@ func (h *Handler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check the video exists and the viewer, or the signed link, may see it
+	grant, signed, err := mediaGrant(r, mediaScopeThumbnail)
+	if err != nil {
+		h.handleValidationError(w, r, "Invalid signed link", err)
+		return
+	}
+	if signed {
+		if _, err := h.service.GetSignedVideo(r.Context(), id, grant); err != nil {
+			h.handleServiceError(w, r, err)
+			return
+		}
+		setSignedMediaHeaders(w, grant, id)
+	} else if _, err := h.service.GetVideo(r.Context(), id); err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
 	// Strip the file extension from the id to match thumbnail naming convention
 	thumbnailName := strings.TrimSuffix(id, filepath.Ext(id)) + ".jpg"
 	thumbnailPath := filepath.Join(h.storage.Thumbnails, thumbnailName)
@@ -303,9 +444,15 @@ func (h *Handler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, thumbnailPath)
 }
 
-// ListVideos returns a list of available videos
+// ListVideos returns a list of available videos, optionally filtered by search and tag
 func (h *Handler) ListVideos(w http.ResponseWriter, r *http.Request) {
-	videos, err := h.service.ListVideos(r.Context())
+	query := &VideoQuery{
+		Search:    r.URL.Query().Get("search"),
+		Tag:       r.URL.Query().Get("tag"),
+		Languages: requestLanguages(r),
+	}
+
+	videos, err := h.service.SearchVideos(r.Context(), query)
 	if err != nil {
 		h.handleServiceError(w, r, err)
 		return
@@ -318,7 +465,7 @@ func (h *Handler) ListVideos(w http.ResponseWriter, r *http.Request) {
 
 // createStorageDirectories creates all required storage directories
 func createStorageDirectories(storage *config.Storage) error {
-	dirs := []string{storage.Uploads, storage.Temp, storage.Logs, storage.Thumbnails, storage.Metadata}
+	dirs := []string{storage.Uploads, storage.Temp, storage.Logs, storage.Thumbnails, storage.Metadata, storage.Captions}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0o750); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
@@ -341,16 +488,26 @@ func parseTemplates() (map[string]*template.Template, error) {
 		return nil, fmt.Errorf("failed to parse home template: %w", err)
 	}
 
-	recordTemplate, err := template.Must(baseTemplate.Clone()).ParseFiles("web/templates/record.html")
+	recordTemplate, err := template.Must(baseTemplate.Clone()).ParseFiles("web/templates/record.html", "web/templates/consent.html", "web/templates/access.html")
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse record template: %w", err)
 	}
 
-	uploadTemplate, err := template.Must(baseTemplate.Clone()).ParseFiles("web/templates/upload.html")
+	uploadTemplate, err := template.Must(baseTemplate.Clone()).ParseFiles("web/templates/upload.html", "web/templates/consent.html", "web/templates/access.html")
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse upload template: %w", err)
 	}
 
+	collectionTemplate, err := template.Must(baseTemplate.Clone()).ParseFiles("web/templates/collection.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse collection template: %w", err)
+	}
+
+	reviewTemplate, err := template.Must(baseTemplate.Clone()).ParseFiles("web/templates/review.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse review template: %w", err)
+	}
+
 	// Parse standalone templates
 	galleryTemplate, err := template.ParseFiles("web/templates/gallery.html")
 	if err != nil {
@@ -369,77 +526,69 @@ func parseTemplates() (map[string]*template.Template, error) {
 
 	// Create a template map for easy access
 	templates := map[string]*template.Template{
-		"home":    homeTemplate,
-		"record":  recordTemplate,
-		"upload":  uploadTemplate,
-		"gallery": galleryTemplate,
-		"editor":  editorTemplate,
-		"index":   indexTemplate,
+		"home":       homeTemplate,
+		"record":     recordTemplate,
+		"upload":     uploadTemplate,
+		"collection": collectionTemplate,
+		"review":     reviewTemplate,
+		"gallery":    galleryTemplate,
+		"editor":     editorTemplate,
+		"index":      indexTemplate,
 	}
 
 	return templates, nil
 }
 
-// getHealthStatus returns the current health status
-func (h *Handler) getHealthStatus() map[string]interface{} {
-	// Test FFmpeg availability
-	ffmpegWorking := false
-	if _, err := exec.LookPath("ffmpeg"); err == nil {
-		ffmpegWorking = true
-	}
-
-	// Check video directory
-	videoDirAccessible := false
-	if _, err := os.Stat(h.storage.Uploads); err == nil {
-		videoDirAccessible = true
-	}
-
-	return map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now(),
-		"checks": map[string]bool{
-			"ffmpeg":    ffmpegWorking,
-			"video_dir": videoDirAccessible,
-		},
-	}
+// log returns the request's logger, which carries its ID and viewer
+func (h *Handler) log(r *http.Request) *logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
 }
 
 // Error handling helpers
 
 // handleMethodNotAllowed handles HTTP method not allowed errors
 func (h *Handler) handleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
-	h.logger.Error("Method not allowed: %s %s (remote: %s)", r.Method, r.URL.Path, r.RemoteAddr)
+	h.log(r).Error("Method not allowed: %s %s (remote: %s)", r.Method, r.URL.Path, r.RemoteAddr)
 	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 }
 
 // handleValidationError handles validation errors
 func (h *Handler) handleValidationError(w http.ResponseWriter, r *http.Request, message string, err error) {
-	h.logger.Error("Validation error: %s - %v (method: %s, path: %s, remote: %s)",
+	h.log(r).Error("Validation error: %s - %v (method: %s, path: %s, remote: %s)",
 		message, err, r.Method, r.URL.Path, r.RemoteAddr)
 	http.Error(w, message, http.StatusBadRequest)
 }
 
 // handleNotFoundError handles not found errors
 func (h *Handler) handleNotFoundError(w http.ResponseWriter, r *http.Request, message string, err error) {
-	h.logger.Error("Not found error: %s - %v (method: %s, path: %s, remote: %s)",
+	h.log(r).Error("Not found error: %s - %v (method: %s, path: %s, remote: %s)",
 		message, err, r.Method, r.URL.Path, r.RemoteAddr)
 	http.Error(w, message, http.StatusNotFound)
 }
 
 // handleInternalError handles internal server errors
 func (h *Handler) handleInternalError(w http.ResponseWriter, r *http.Request, err error) {
-	h.logger.Error("Internal server error: %v (method: %s, path: %s, remote: %s)",
+	h.log(r).Error("Internal server error: %v (method: %s, path: %s, remote: %s)",
 		err, r.Method, r.URL.Path, r.RemoteAddr)
 	http.Error(w, "Internal server error", http.StatusInternalServerError)
 }
 
 // handleServiceError handles service layer errors
 func (h *Handler) handleServiceError(w http.ResponseWriter, r *http.Request, err error) {
-	h.logger.Error("Service error: %v (method: %s, path: %s, remote: %s)",
+	h.log(r).Error("Service error: %v (method: %s, path: %s, remote: %s)",
 		err, r.Method, r.URL.Path, r.RemoteAddr)
 
 	// Use structured error handling if available
 	statusCode := GetHTTPStatusCode(err)
+	if statusCode == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", strconv.Itoa(int(busyRetryAfter.Seconds())))
+	}
+	// Quota messages tell the user how to make room, so they are passed on
+	var videoErr *VideoError
+	if errors.As(err, &videoErr) && videoErr.Type == ErrorTypeQuota {
+		http.Error(w, videoErr.Message, statusCode)
+		return
+	}
 	http.Error(w, "Service error", statusCode)
 }
 
@@ -451,3 +600,17 @@ func (h *Handler) writeJSONResponse(w http.ResponseWriter, data interface{}) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
+
+// writeJSONStatus writes a JSON response with a non-default status code
+func (h *Handler) writeJSONStatus(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode JSON response: %v", err)
+	}
+}
+
+// HealthChecks returns the readiness checks for video storage and processing
+func (h *Handler) HealthChecks() []health.Checker {
+	return h.checks
+}
@@ -0,0 +1,130 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gooji/internal/auth"
+	"gooji/internal/config"
+	"gooji/internal/logger"
+	"gooji/internal/metrics"
+)
+
+// janitorName identifies the janitor in the audit log and review history
+const janitorName = "Retention janitor"
+
+// Janitor periodically removes stale temp files and uploads, and expires
+// videos under the configured retention rules
+type Janitor struct {
+	service    Service
+	cfg        config.Retention
+	interval   time.Duration
+	staleAfter time.Duration
+	metrics    *metrics.Metrics
+	logger     *logger.Logger
+
+	// runMu keeps scheduled and requested runs from overlapping
+	runMu sync.Mutex
+	mu    sync.Mutex
+	last  *RetentionReport
+}
+
+// RetentionStatus is the body of GET /api/retention
+type RetentionStatus struct {
+	Enabled    bool                   `json:"enabled"`
+	Interval   string                 `json:"interval"`
+	StaleAfter string                 `json:"stale_after"`
+	DryRun     bool                   `json:"dry_run"`
+	Rules      []config.RetentionRule `json:"rules"`
+	LastReport *RetentionReport       `json:"last_report,omitempty"`
+}
+
+// NewJanitor creates a janitor, validating its schedule and rules
+func NewJanitor(service Service, cfg *config.Retention, m *metrics.Metrics, log *logger.Logger) (*Janitor, error) {
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid retention interval %q", cfg.Interval)
+	}
+	staleAfter, err := time.ParseDuration(cfg.StaleAfter)
+	if err != nil || staleAfter <= 0 {
+		return nil, fmt.Errorf("invalid retention stale_after %q", cfg.StaleAfter)
+	}
+	if err := ValidateRetentionRules(cfg.Rules); err != nil {
+		return nil, err
+	}
+	return &Janitor{
+		service:    service,
+		cfg:        *cfg,
+		interval:   interval,
+		staleAfter: staleAfter,
+		metrics:    m,
+		logger:     log,
+	}, nil
+}
+
+// Run cleans up on the configured interval until ctx is done. It does nothing
+// when the janitor is disabled.
+func (j *Janitor) Run(ctx context.Context) {
+	if !j.cfg.Enabled {
+		return
+	}
+	j.logger.Info("Retention janitor runs every %s with %d rules", j.interval, len(j.cfg.Rules))
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := j.RunOnce(SystemContext(ctx), j.cfg.DryRun); err != nil {
+			j.logger.Error("Retention janitor failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce cleans up now as the viewer in ctx, who must be allowed to delete
+// videos, and keeps the report. A dry run only reports.
+func (j *Janitor) RunOnce(ctx context.Context, dryRun bool) (*RetentionReport, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	report, err := j.service.ApplyRetention(ctx, j.cfg.Rules, time.Now().Add(-j.staleAfter), dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if !report.DryRun {
+		j.metrics.ObserveRetention(len(report.Videos), len(report.Files))
+	}
+	for _, message := range report.Errors {
+		j.logger.Error("Retention janitor: %s", message)
+	}
+
+	j.mu.Lock()
+	j.last = report
+	j.mu.Unlock()
+	return report, nil
+}
+
+// Status returns the janitor's configuration and its last report
+func (j *Janitor) Status() *RetentionStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return &RetentionStatus{
+		Enabled:    j.cfg.Enabled,
+		Interval:   j.interval.String(),
+		StaleAfter: j.staleAfter.String(),
+		DryRun:     j.cfg.DryRun,
+		Rules:      j.cfg.Rules,
+		LastReport: j.last,
+	}
+}
+
+// SystemContext returns a context for maintenance the server does on its own,
+// such as the janitor's scheduled runs and the one-shot cleanup command
+func SystemContext(ctx context.Context) context.Context {
+	return WithViewer(ctx, &Viewer{Role: auth.RoleAdmin, Name: janitorName})
+}
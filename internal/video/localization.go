@@ -0,0 +1,370 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+
+	"gooji/internal/auth"
+)
+
+// defaultLanguage is the language assumed for untagged titles and descriptions
+const defaultLanguage = "en"
+
+// maxLanguagesPerField limits how many translations a single field may carry
+const maxLanguagesPerField = 20
+
+// LocalizedText maps BCP-47 language tags (e.g. "oj", "ciw", "en") to text
+type LocalizedText map[string]string
+
+// LocalizedKeywords maps BCP-47 language tags to keyword lists
+type LocalizedKeywords map[string][]string
+
+// LocalizedMetadataInput represents per-language metadata updates.
+// Values replace existing translations; an empty value removes the translation.
+type LocalizedMetadataInput struct {
+	Language     string            `json:"language,omitempty"`
+	Titles       LocalizedText     `json:"titles,omitempty"`
+	Descriptions LocalizedText     `json:"descriptions,omitempty"`
+	Keywords     LocalizedKeywords `json:"keywords,omitempty"`
+}
+
+// LocalizedVideo is a video with title and description resolved for a reader's languages
+type LocalizedVideo struct {
+	VideoMetadata
+	ResolvedLanguage string `json:"resolved_language"`
+}
+
+// VideoQuery represents search and filter options for listing videos
+type VideoQuery struct {
+	Search    string
+	Tag       string
+	Languages []string
+}
+
+// canonicalLanguage validates a BCP-47 tag and returns its canonical form
+func canonicalLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", fmt.Errorf("language tag is required")
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid language tag %q: %w", tag, err)
+	}
+	return parsed.String(), nil
+}
+
+// ParseLanguages parses a comma-separated language list or Accept-Language header
+// into canonical tags in preference order, skipping invalid entries
+func ParseLanguages(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	tags, _, err := language.ParseAcceptLanguage(value)
+	if err != nil {
+		return nil
+	}
+	languages := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		languages = append(languages, tag.String())
+	}
+	return languages
+}
+
+// fallbackChain returns the ordered languages to try when resolving localized text
+func fallbackChain(preferred []string, primary string) []string {
+	chain := make([]string, 0, len(preferred)*2+2)
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			chain = append(chain, tag)
+		}
+	}
+
+	for _, tag := range preferred {
+		add(tag)
+		// Fall back from a regional variant such as "oj-CA" to its base language
+		if parsed, err := language.Parse(tag); err == nil {
+			base, _ := parsed.Base()
+			add(base.String())
+		}
+	}
+	add(primary)
+	add(defaultLanguage)
+	return chain
+}
+
+// resolve picks the best translation for the fallback chain.
+// If none of the chain matches, the lexically first language is used so output is stable.
+func (t LocalizedText) resolve(chain []string) (string, string) {
+	for _, tag := range chain {
+		if text, ok := t[tag]; ok && text != "" {
+			return text, tag
+		}
+	}
+	if len(t) == 0 {
+		return "", ""
+	}
+	tags := make([]string, 0, len(t))
+	for tag := range t {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return t[tags[0]], tags[0]
+}
+
+// primaryLanguage returns the language a video's untagged fields are written in
+func (m *VideoMetadata) primaryLanguage() string {
+	if m.Language != "" {
+		return m.Language
+	}
+	return defaultLanguage
+}
+
+// localizedTitles returns the title translations, including a legacy untagged title
+func (m *VideoMetadata) localizedTitles() LocalizedText {
+	if len(m.Titles) > 0 || m.Title == "" {
+		return m.Titles
+	}
+	return LocalizedText{m.primaryLanguage(): m.Title}
+}
+
+// localizedDescriptions returns the description translations, including a legacy untagged description
+func (m *VideoMetadata) localizedDescriptions() LocalizedText {
+	if len(m.Descriptions) > 0 || m.Description == "" {
+		return m.Descriptions
+	}
+	return LocalizedText{m.primaryLanguage(): m.Description}
+}
+
+// Localize returns a copy of the metadata with Title and Description resolved for the preferred languages
+func (m *VideoMetadata) Localize(preferred []string) *LocalizedVideo {
+	chain := fallbackChain(preferred, m.primaryLanguage())
+	localized := &LocalizedVideo{VideoMetadata: *m}
+
+	if title, tag := m.localizedTitles().resolve(chain); tag != "" {
+		localized.Title = title
+		localized.ResolvedLanguage = tag
+	}
+	if description, tag := m.localizedDescriptions().resolve(chain); tag != "" {
+		localized.Description = description
+		if localized.ResolvedLanguage == "" {
+			localized.ResolvedLanguage = tag
+		}
+	}
+	return localized
+}
+
+// syncDefaultFields keeps the untagged Title and Description in step with the primary language
+func (m *VideoMetadata) syncDefaultFields() {
+	chain := fallbackChain(nil, m.primaryLanguage())
+	m.Title, _ = m.Titles.resolve(chain)
+	m.Description, _ = m.Descriptions.resolve(chain)
+}
+
+// matches reports whether the metadata matches a search term in any language
+func (m *VideoMetadata) matches(term string) bool {
+	if term == "" {
+		return true
+	}
+	candidates := []string{m.Title, m.Description}
+	candidates = append(candidates, m.Tags...)
+	for _, text := range m.Titles {
+		candidates = append(candidates, text)
+	}
+	for _, text := range m.Descriptions {
+		candidates = append(candidates, text)
+	}
+	for _, keywords := range m.Keywords {
+		candidates = append(candidates, keywords...)
+	}
+
+	for _, candidate := range candidates {
+		if strings.Contains(foldForSearch(candidate), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag reports whether the metadata carries the given tag
+func (m *VideoMetadata) hasTag(tag string) bool {
+	if tag == "" {
+		return true
+	}
+	for _, t := range m.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// foldForSearch normalizes text so that differently composed characters and cases compare equal
+func foldForSearch(text string) string {
+	return strings.ToLower(norm.NFC.String(strings.TrimSpace(text)))
+}
+
+// SearchVideos lists videos matching a query, resolving text for the query's languages
+func (s *service) SearchVideos(ctx context.Context, query *VideoQuery) ([]LocalizedVideo, error) {
+	videos, err := s.ListVideos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if query == nil {
+		query = &VideoQuery{}
+	}
+
+	term := foldForSearch(query.Search)
+	results := make([]LocalizedVideo, 0, len(videos))
+	for i := range videos {
+		if !videos[i].matches(term) || !videos[i].hasTag(query.Tag) {
+			continue
+		}
+		results = append(results, *videos[i].Localize(query.Languages))
+	}
+
+	return results, nil
+}
+
+// GetLocalizedVideo retrieves a video with text resolved for the preferred languages
+func (s *service) GetLocalizedVideo(ctx context.Context, id string, languages []string) (*LocalizedVideo, error) {
+	metadata, err := s.GetVideo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Localize(languages), nil
+}
+
+// UpdateLocalizedMetadata merges per-language titles, descriptions and keywords into a video
+func (s *service) UpdateLocalizedMetadata(ctx context.Context, id string, input *LocalizedMetadataInput) (*VideoMetadata, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, NewValidationError("video ID is required", nil)
+	}
+	if input == nil {
+		return nil, NewValidationError("metadata is required", nil)
+	}
+
+	defer s.videoLocks.lock(id)()
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+
+	before := snapshot(metadata)
+	if err := s.applyLocalizedInput(metadata, input); err != nil {
+		return nil, err
+	}
+	metadata.resubmitIfChangesRequested()
+
+	if err := s.repo.SaveMetadata(ctx, metadata); err != nil {
+		return nil, NewInternalError("failed to save metadata", err)
+	}
+
+	s.recordAudit(ctx, AuditVideoMetadata, id, before, metadata)
+
+	s.log(ctx, "update_localized_metadata", id).Info("Successfully updated localized metadata: %s", id)
+	return metadata, nil
+}
+
+// applyLocalizedInput validates and merges localized input into metadata
+func (s *service) applyLocalizedInput(metadata *VideoMetadata, input *LocalizedMetadataInput) error {
+	if input.Language != "" {
+		tag, err := canonicalLanguage(input.Language)
+		if err != nil {
+			return NewValidationError("invalid primary language", err)
+		}
+		metadata.Language = tag
+	}
+
+	// Promote legacy untagged text before merging so it is not lost
+	metadata.Titles = metadata.localizedTitles()
+	metadata.Descriptions = metadata.localizedDescriptions()
+
+	titles, err := mergeLocalizedText(metadata.Titles, input.Titles, s.sanitizer.Title)
+	if err != nil {
+		return NewValidationError("invalid titles", err)
+	}
+	descriptions, err := mergeLocalizedText(metadata.Descriptions, input.Descriptions, s.sanitizer.Description)
+	if err != nil {
+		return NewValidationError("invalid descriptions", err)
+	}
+	keywords, err := s.mergeLocalizedKeywords(metadata.Keywords, input.Keywords)
+	if err != nil {
+		return NewValidationError("invalid keywords", err)
+	}
+
+	metadata.Titles = titles
+	metadata.Descriptions = descriptions
+	metadata.Keywords = keywords
+	metadata.syncDefaultFields()
+	return nil
+}
+
+// mergeLocalizedText merges updates, cleaned by sanitize, into existing translations
+func mergeLocalizedText(existing, updates LocalizedText, sanitize func(string) string) (LocalizedText, error) {
+	merged := make(LocalizedText, len(existing)+len(updates))
+	for tag, text := range existing {
+		merged[tag] = text
+	}
+	for rawTag, text := range updates {
+		tag, err := canonicalLanguage(rawTag)
+		if err != nil {
+			return nil, err
+		}
+		text = sanitize(text)
+		if text == "" {
+			delete(merged, tag)
+			continue
+		}
+		merged[tag] = text
+	}
+	if len(merged) > maxLanguagesPerField {
+		return nil, fmt.Errorf("at most %d languages are allowed", maxLanguagesPerField)
+	}
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	return merged, nil
+}
+
+// mergeLocalizedKeywords merges sanitized keyword updates into existing keywords
+func (s *service) mergeLocalizedKeywords(existing, updates LocalizedKeywords) (LocalizedKeywords, error) {
+	merged := make(LocalizedKeywords, len(existing)+len(updates))
+	for tag, keywords := range existing {
+		merged[tag] = keywords
+	}
+	for rawTag, keywords := range updates {
+		tag, err := canonicalLanguage(rawTag)
+		if err != nil {
+			return nil, err
+		}
+		sanitized := make([]string, 0, len(keywords))
+		for _, keyword := range keywords {
+			if keyword = s.sanitizer.Keyword(keyword); keyword != "" {
+				sanitized = append(sanitized, keyword)
+			}
+		}
+		if len(sanitized) == 0 {
+			delete(merged, tag)
+			continue
+		}
+		merged[tag] = sanitized
+	}
+	if len(merged) > maxLanguagesPerField {
+		return nil, fmt.Errorf("at most %d languages are allowed", maxLanguagesPerField)
+	}
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	return merged, nil
+}
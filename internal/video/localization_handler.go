@@ -0,0 +1,126 @@
+package video
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gooji/internal/middleware"
+)
+
+// maxMetadataBodySize limits the size of metadata request bodies
+const maxMetadataBodySize = 1 << 20
+
+// HandleVideoMetadata handles localized metadata endpoints: /api/videos/{id}/metadata
+func (h *Handler) HandleVideoMetadata(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetVideoMetadata(w, r)
+	case http.MethodPut:
+		h.UpdateVideoMetadata(w, r)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// GetVideoMetadata returns video metadata resolved for the requested languages.
+// Languages come from the lang query parameter (comma-separated) or Accept-Language.
+func (h *Handler) GetVideoMetadata(w http.ResponseWriter, r *http.Request) {
+	id, _ := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	video, err := h.service.GetLocalizedVideo(r.Context(), id, requestLanguages(r))
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, video)
+}
+
+// UpdateVideoMetadata merges per-language titles, descriptions and keywords from a JSON body
+func (h *Handler) UpdateVideoMetadata(w http.ResponseWriter, r *http.Request) {
+	id, _ := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetadataBodySize)
+	var input LocalizedMetadataInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		h.handleValidationError(w, r, "Invalid metadata", err)
+		return
+	}
+
+	metadata, err := h.service.UpdateLocalizedMetadata(r.Context(), id, &input)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, metadata)
+}
+
+// requestLanguages returns the caller's preferred languages from the lang parameter or Accept-Language
+func requestLanguages(r *http.Request) []string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return ParseLanguages(lang)
+	}
+	return ParseLanguages(r.Header.Get("Accept-Language"))
+}
+
+// localizedFormValues collects form fields named like "title[oj]" into a LocalizedText
+func localizedFormValues(r *http.Request, field string) LocalizedText {
+	values := LocalizedText{}
+	for tag, value := range localizedFormFields(r, field) {
+		values[tag] = value
+	}
+	return values
+}
+
+// localizedFormKeywords collects comma-separated form fields named like "keywords[oj]"
+func localizedFormKeywords(r *http.Request, field string) LocalizedKeywords {
+	keywords := LocalizedKeywords{}
+	for tag, value := range localizedFormFields(r, field) {
+		keywords[tag] = strings.Split(value, ",")
+	}
+	return keywords
+}
+
+// localizedFormFields maps the language tag in "field[tag]" form keys to the submitted value
+func localizedFormFields(r *http.Request, field string) map[string]string {
+	fields := make(map[string]string)
+	if r.MultipartForm == nil {
+		return fields
+	}
+	prefix := field + "["
+	for key, values := range r.MultipartForm.Value {
+		if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "]") || len(values) == 0 {
+			continue
+		}
+		fields[strings.TrimSuffix(strings.TrimPrefix(key, prefix), "]")] = values[0]
+	}
+	return fields
+}
+
+// videoPathParts splits /api/videos/{id}[/{resource}] into its ID and resource name
+func videoPathParts(path string) (string, string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/videos"), "/"), "/")
+	switch {
+	case len(parts) == 0:
+		return "", ""
+	case len(parts) == 1:
+		return parts[0], ""
+	default:
+		return parts[0], strings.Join(parts[1:], "/")
+	}
+}
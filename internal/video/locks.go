@@ -0,0 +1,43 @@
+package video
+
+import "sync"
+
+// idLocks serializes changes to each stored video or collection, so concurrent
+// edits, review decisions and deletes never overwrite one another's read-modify-write
+type idLocks struct {
+	mu    sync.Mutex
+	locks map[string]*idLock
+}
+
+// idLock is one ID's lock and the number of callers holding or awaiting it
+type idLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock takes the lock for an ID and returns the function that releases it.
+// Entries are dropped once nobody holds them, so the map stays small.
+func (v *idLocks) lock(id string) func() {
+	v.mu.Lock()
+	if v.locks == nil {
+		v.locks = make(map[string]*idLock)
+	}
+	l, ok := v.locks[id]
+	if !ok {
+		l = &idLock{}
+		v.locks[id] = l
+	}
+	l.refs++
+	v.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		v.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(v.locks, id)
+		}
+		v.mu.Unlock()
+	}
+}
@@ -0,0 +1,123 @@
+package video
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"gooji/internal/logger"
+	"gooji/internal/metrics"
+	"gooji/internal/storage"
+	"gooji/pkg/ffmpeg"
+)
+
+// videoCollector reports video counts and storage usage, measured on each scrape
+type videoCollector struct {
+	repo   Repository
+	meter  *storage.Meter
+	logger *logger.Logger
+
+	videos         *prometheus.Desc
+	directoryBytes *prometheus.Desc
+	diskAvailable  *prometheus.Desc
+	diskTotal      *prometheus.Desc
+}
+
+// newVideoCollector creates the collector for a repository and storage meter
+func newVideoCollector(repo Repository, meter *storage.Meter, log *logger.Logger) *videoCollector {
+	return &videoCollector{
+		repo:   repo,
+		meter:  meter,
+		logger: log,
+		videos: prometheus.NewDesc("gooji_videos",
+			"Videos by review state.", []string{"state"}, nil),
+		directoryBytes: prometheus.NewDesc("gooji_storage_directory_bytes",
+			"Bytes used by each storage directory.", []string{"directory"}, nil),
+		diskAvailable: prometheus.NewDesc("gooji_storage_disk_available_bytes",
+			"Free bytes on the disk holding uploads.", nil, nil),
+		diskTotal: prometheus.NewDesc("gooji_storage_disk_total_bytes",
+			"Size of the disk holding uploads.", nil, nil),
+	}
+}
+
+// Describe sends the descriptors of the metrics the collector reports
+func (c *videoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.videos
+	ch <- c.directoryBytes
+	ch <- c.diskAvailable
+	ch <- c.diskTotal
+}
+
+// Collect measures videos and storage. Failures are logged and the affected
+// metrics left out, so one unreadable directory does not fail the scrape.
+func (c *videoCollector) Collect(ch chan<- prometheus.Metric) {
+	if videos, err := c.repo.ListMetadata(context.Background()); err != nil {
+		c.logger.Error("Failed to count videos for metrics: %v", err)
+	} else {
+		counts := map[ReviewState]int{
+			ReviewPending:          0,
+			ReviewApproved:         0,
+			ReviewRejected:         0,
+			ReviewChangesRequested: 0,
+		}
+		for i := range videos {
+			counts[videos[i].Review.state()]++
+		}
+		for state, count := range counts {
+			ch <- prometheus.MustNewConstMetric(c.videos, prometheus.GaugeValue, float64(count), string(state))
+		}
+	}
+
+	if dirs, err := c.meter.Directories(); err != nil {
+		c.logger.Error("Failed to measure storage for metrics: %v", err)
+	} else {
+		for _, dir := range dirs {
+			ch <- prometheus.MustNewConstMetric(c.directoryBytes, prometheus.GaugeValue, float64(dir.Bytes), dir.Name)
+		}
+	}
+
+	if disk, err := c.meter.Disk(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.diskAvailable, prometheus.GaugeValue, float64(disk.AvailableBytes))
+		ch <- prometheus.MustNewConstMetric(c.diskTotal, prometheus.GaugeValue, float64(disk.TotalBytes))
+	}
+}
+
+// queueCollectors report how many FFmpeg jobs are running and waiting
+func queueCollectors(limiter *ffmpeg.Limiter) []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "gooji_ffmpeg_running",
+			Help: "FFmpeg processes running.",
+		}, func() float64 { return float64(limiter.Running()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "gooji_ffmpeg_queue_depth",
+			Help: "FFmpeg jobs waiting for a free slot.",
+		}, func() float64 { return float64(limiter.Waiting()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "gooji_ffmpeg_capacity",
+			Help: "Maximum concurrent FFmpeg processes.",
+		}, func() float64 { return float64(limiter.Capacity()) }),
+	}
+}
+
+// uploadOutcome classifies an upload result for metrics
+func uploadOutcome(err error) string {
+	if err == nil {
+		return metrics.UploadSucceeded
+	}
+	var videoErr *VideoError
+	if !errors.As(err, &videoErr) {
+		return metrics.UploadFailed
+	}
+	switch videoErr.Type {
+	case ErrorTypeQuota:
+		return metrics.UploadOverQuota
+	case ErrorTypeBusy:
+		return metrics.UploadBusy
+	case ErrorTypeInternal, ErrorTypeNotFound:
+		return metrics.UploadFailed
+	default:
+		return metrics.UploadRejected
+	}
+}
@@ -0,0 +1,68 @@
+package video
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gooji/internal/auth"
+	"gooji/internal/middleware"
+)
+
+// Policy maps HTTP methods to the permission a route requires. Methods that
+// are not listed are rejected, so a route only accepts what its policy names.
+type Policy map[string]auth.Permission
+
+// Authorize returns middleware enforcing a route's policy against the viewer's role.
+// It must run after ViewerMiddleware. Services check the same permissions again,
+// more precisely where one route serves operations with different requirements.
+func Authorize(policy Policy) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := r.Method
+			if method == http.MethodHead {
+				method = http.MethodGet
+			}
+			permission, ok := policy[method]
+			if !ok {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			viewer := ViewerFromContext(r.Context())
+			if viewer.Can(permission) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Send anonymous visitors to pages they cannot use to the login page
+			if viewer.UserID == "" && method == http.MethodGet && !strings.HasPrefix(r.URL.Path, "/api/") {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+				return
+			}
+
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
+
+// AuthorizeOrNetworks returns middleware that admits requests from the given
+// networks without credentials, such as a Prometheus scraper on the kiosk's
+// LAN, and enforces the policy on everyone else
+func AuthorizeOrNetworks(cidrs []string, policy Policy) (middleware.Middleware, error) {
+	networks, err := parseNetworks(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	authorize := Authorize(policy)
+	return func(next http.Handler) http.Handler {
+		checked := authorize(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if remoteIPIn(r, networks) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			checked.ServeHTTP(w, r)
+		})
+	}, nil
+}
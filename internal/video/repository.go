@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gooji/internal/config"
 	"gooji/internal/logger"
@@ -29,6 +30,11 @@ func NewRepository(storage *config.Storage, logger *logger.Logger) Repository {
 	}
 }
 
+// log returns the logger carried by ctx, so file operations share the request's fields
+func (r *repository) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
+
 // SaveVideo saves a video file to storage
 func (r *repository) SaveVideo(ctx context.Context, file multipart.File, filename string) (string, error) {
 	// Ensure uploads directory exists
@@ -68,7 +74,7 @@ func (r *repository) SaveVideo(ctx context.Context, file multipart.File, filenam
 		}
 	}
 
-	r.logger.Debug("Successfully saved video file: %s", videoPath)
+	r.log(ctx).Debug("Successfully saved video file: %s", videoPath)
 	return videoPath, nil
 }
 
@@ -99,7 +105,7 @@ func (r *repository) SaveMetadata(ctx context.Context, metadata *VideoMetadata)
 		return fmt.Errorf("failed to encode metadata: %w", err)
 	}
 
-	r.logger.Debug("Successfully saved metadata: %s", metadataPath)
+	r.log(ctx).Debug("Successfully saved metadata: %s", metadataPath)
 	return nil
 }
 
@@ -162,21 +168,21 @@ func (r *repository) ListMetadata(ctx context.Context) ([]VideoMetadata, error)
 
 		// Validate path is within allowed directory
 		if err := r.validatePath(metadataPath, r.storage.Metadata); err != nil {
-			r.logger.Error("Invalid metadata path found: %s", metadataPath)
+			r.log(ctx).Error("Invalid metadata path found: %s", metadataPath)
 			continue
 		}
 
 		// Open and decode metadata file
 		metadataFile, err := os.Open(metadataPath) //nolint:gosec // Path validated above
 		if err != nil {
-			r.logger.Error("Failed to open metadata file %s: %v", metadataPath, err)
+			r.log(ctx).Error("Failed to open metadata file %s: %v", metadataPath, err)
 			continue
 		}
 
 		var metadata VideoMetadata
 		if err := json.NewDecoder(metadataFile).Decode(&metadata); err != nil {
 			metadataFile.Close()
-			r.logger.Error("Failed to decode metadata file %s: %v", metadataPath, err)
+			r.log(ctx).Error("Failed to decode metadata file %s: %v", metadataPath, err)
 			continue
 		}
 		metadataFile.Close()
@@ -193,13 +199,19 @@ func (r *repository) DeleteVideo(ctx context.Context, id string) error {
 		return fmt.Errorf("video ID is required")
 	}
 
+	// A burned-in copy relies on its source's consent record, which may outlive the source
+	var sourceID string
+	if metadata, err := r.GetMetadata(ctx, id); err == nil {
+		sourceID = metadata.SourceID
+	}
+
 	// Delete video file
 	videoPath := filepath.Join(r.storage.Uploads, id)
 	if err := r.validatePath(videoPath, r.storage.Uploads); err == nil {
 		if err := os.Remove(videoPath); err != nil && !os.IsNotExist(err) {
-			r.logger.Error("Failed to delete video file %s: %v", videoPath, err)
+			r.log(ctx).Error("Failed to delete video file %s: %v", videoPath, err)
 		} else {
-			r.logger.Debug("Deleted video file: %s", videoPath)
+			r.log(ctx).Debug("Deleted video file: %s", videoPath)
 		}
 	}
 
@@ -207,9 +219,9 @@ func (r *repository) DeleteVideo(ctx context.Context, id string) error {
 	metadataPath := filepath.Join(r.storage.Metadata, id+".json")
 	if err := r.validatePath(metadataPath, r.storage.Metadata); err == nil {
 		if err := os.Remove(metadataPath); err != nil && !os.IsNotExist(err) {
-			r.logger.Error("Failed to delete metadata file %s: %v", metadataPath, err)
+			r.log(ctx).Error("Failed to delete metadata file %s: %v", metadataPath, err)
 		} else {
-			r.logger.Debug("Deleted metadata file: %s", metadataPath)
+			r.log(ctx).Debug("Deleted metadata file: %s", metadataPath)
 		}
 	}
 
@@ -217,15 +229,73 @@ func (r *repository) DeleteVideo(ctx context.Context, id string) error {
 	thumbnailPath := filepath.Join(r.storage.Thumbnails, strings.TrimSuffix(id, filepath.Ext(id))+".jpg")
 	if err := r.validatePath(thumbnailPath, r.storage.Thumbnails); err == nil {
 		if err := os.Remove(thumbnailPath); err != nil && !os.IsNotExist(err) {
-			r.logger.Error("Failed to delete thumbnail file %s: %v", thumbnailPath, err)
+			r.log(ctx).Error("Failed to delete thumbnail file %s: %v", thumbnailPath, err)
 		} else {
-			r.logger.Debug("Deleted thumbnail file: %s", thumbnailPath)
+			r.log(ctx).Debug("Deleted thumbnail file: %s", thumbnailPath)
 		}
 	}
 
+	// Delete caption tracks
+	captionsDir := r.captionsDir(id)
+	if err := r.validatePath(captionsDir, r.storage.Captions); err == nil && captionsDir != filepath.Clean(r.storage.Captions) {
+		if err := os.RemoveAll(captionsDir); err != nil {
+			r.log(ctx).Error("Failed to delete captions directory %s: %v", captionsDir, err)
+		} else {
+			r.log(ctx).Debug("Deleted captions directory: %s", captionsDir)
+		}
+	}
+
+	// Delete transcript
+	transcriptPath := filepath.Join(r.transcriptsDir(), id+".json")
+	if err := r.validatePath(transcriptPath, r.transcriptsDir()); err == nil {
+		if err := os.Remove(transcriptPath); err != nil && !os.IsNotExist(err) {
+			r.log(ctx).Error("Failed to delete transcript file %s: %v", transcriptPath, err)
+		}
+	}
+
+	// Delete consent records no remaining video relies on: the video's own,
+	// and its source's once the source and every other copy are gone
+	r.deleteUnusedConsent(ctx, id)
+	if sourceID != "" && !r.metadataExists(sourceID) {
+		r.deleteUnusedConsent(ctx, sourceID)
+	}
+
 	return nil
 }
 
+// deleteUnusedConsent removes a video's consent record unless a burned-in copy
+// still proves its consent with it. Records are kept when copies cannot be listed.
+func (r *repository) deleteUnusedConsent(ctx context.Context, id string) {
+	videos, err := r.ListMetadata(ctx)
+	if err != nil {
+		r.log(ctx).Error("Keeping consent record of %s: failed to list videos: %v", id, err)
+		return
+	}
+	for _, video := range videos {
+		if video.SourceID == id {
+			r.log(ctx).Debug("Keeping consent record of %s for copy %s", id, video.ID)
+			return
+		}
+	}
+
+	consentPath := filepath.Join(r.consentDir(), id+".json")
+	if err := r.validatePath(consentPath, r.consentDir()); err == nil {
+		if err := os.Remove(consentPath); err != nil && !os.IsNotExist(err) {
+			r.log(ctx).Error("Failed to delete consent file %s: %v", consentPath, err)
+		}
+	}
+}
+
+// metadataExists reports whether a video's metadata file is present
+func (r *repository) metadataExists(id string) bool {
+	metadataPath := filepath.Join(r.storage.Metadata, id+".json")
+	if err := r.validatePath(metadataPath, r.storage.Metadata); err != nil {
+		return false
+	}
+	_, err := os.Stat(metadataPath)
+	return err == nil
+}
+
 // VideoExists checks if a video file exists
 func (r *repository) VideoExists(ctx context.Context, id string) bool {
 	if id == "" {
@@ -241,6 +311,213 @@ func (r *repository) VideoExists(ctx context.Context, id string) bool {
 	return err == nil
 }
 
+// collectionsDir returns the directory holding collection files
+func (r *repository) collectionsDir() string {
+	return filepath.Join(r.storage.Metadata, "collections")
+}
+
+// SaveCollection saves a collection to storage
+func (r *repository) SaveCollection(ctx context.Context, collection *Collection) error {
+	// Ensure collections directory exists
+	if err := os.MkdirAll(r.collectionsDir(), 0o750); err != nil {
+		return fmt.Errorf("failed to create collections directory: %w", err)
+	}
+
+	// Create collection file path
+	collectionPath := filepath.Join(r.collectionsDir(), collection.ID+".json")
+
+	// Validate path is within allowed directory
+	if err := r.validatePath(collectionPath, r.collectionsDir()); err != nil {
+		return fmt.Errorf("invalid collection path: %w", err)
+	}
+
+	// Create collection file
+	file, err := os.Create(collectionPath) //nolint:gosec // Path validated above
+	if err != nil {
+		return fmt.Errorf("failed to create collection file: %w", err)
+	}
+	defer file.Close()
+
+	// Encode collection as JSON
+	if err := json.NewEncoder(file).Encode(collection); err != nil {
+		return fmt.Errorf("failed to encode collection: %w", err)
+	}
+
+	r.log(ctx).Debug("Successfully saved collection: %s", collectionPath)
+	return nil
+}
+
+// GetCollection retrieves a collection by ID
+func (r *repository) GetCollection(ctx context.Context, id string) (*Collection, error) {
+	if id == "" {
+		return nil, fmt.Errorf("collection ID is required")
+	}
+
+	// Create collection file path
+	collectionPath := filepath.Join(r.collectionsDir(), id+".json")
+
+	// Validate path is within allowed directory
+	if err := r.validatePath(collectionPath, r.collectionsDir()); err != nil {
+		return nil, fmt.Errorf("invalid collection path: %w", err)
+	}
+
+	// Open collection file
+	file, err := os.Open(collectionPath) //nolint:gosec // Path validated above
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("collection not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to open collection file: %w", err)
+	}
+	defer file.Close()
+
+	// Decode collection
+	var collection Collection
+	if err := json.NewDecoder(file).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to decode collection: %w", err)
+	}
+
+	return &collection, nil
+}
+
+// ListCollections retrieves all collections
+func (r *repository) ListCollections(ctx context.Context) ([]Collection, error) {
+	// Ensure collections directory exists
+	if err := os.MkdirAll(r.collectionsDir(), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create collections directory: %w", err)
+	}
+
+	// Read collections directory
+	files, err := os.ReadDir(r.collectionsDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collections directory: %w", err)
+	}
+
+	collections := make([]Collection, 0, len(files))
+	for _, file := range files {
+		// Skip directories and non-JSON files
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		collection, err := r.GetCollection(ctx, strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			r.log(ctx).Error("Failed to load collection %s: %v", file.Name(), err)
+			continue
+		}
+
+		collections = append(collections, *collection)
+	}
+
+	return collections, nil
+}
+
+// DeleteCollection removes a collection file
+func (r *repository) DeleteCollection(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("collection ID is required")
+	}
+
+	collectionPath := filepath.Join(r.collectionsDir(), id+".json")
+	if err := r.validatePath(collectionPath, r.collectionsDir()); err != nil {
+		return fmt.Errorf("invalid collection path: %w", err)
+	}
+
+	if err := os.Remove(collectionPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("collection not found: %s", id)
+		}
+		return fmt.Errorf("failed to delete collection file: %w", err)
+	}
+
+	r.log(ctx).Debug("Deleted collection file: %s", collectionPath)
+	return nil
+}
+
+// VideoPath returns the storage path for a video file
+func (r *repository) VideoPath(filename string) string {
+	return filepath.Join(r.storage.Uploads, filename)
+}
+
+// captionsDir returns the directory holding a video's caption tracks
+func (r *repository) captionsDir(videoID string) string {
+	return filepath.Join(r.storage.Captions, videoID)
+}
+
+// CaptionPath returns the storage path for a video's WebVTT track in a language
+func (r *repository) CaptionPath(videoID, lang string) string {
+	return filepath.Join(r.captionsDir(videoID), lang+".vtt")
+}
+
+// SaveCaption saves a WebVTT caption track for a video and language
+func (r *repository) SaveCaption(ctx context.Context, videoID, lang string, data []byte) error {
+	if videoID == "" || lang == "" {
+		return fmt.Errorf("video ID and language are required")
+	}
+
+	captionPath := r.CaptionPath(videoID, lang)
+
+	// Validate path is within allowed directory
+	if err := r.validatePath(captionPath, r.storage.Captions); err != nil {
+		return fmt.Errorf("invalid caption path: %w", err)
+	}
+
+	// Ensure the video's captions directory exists
+	if err := os.MkdirAll(r.captionsDir(videoID), 0o750); err != nil {
+		return fmt.Errorf("failed to create captions directory: %w", err)
+	}
+
+	if err := os.WriteFile(captionPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write caption file: %w", err)
+	}
+
+	r.log(ctx).Debug("Successfully saved caption file: %s", captionPath)
+	return nil
+}
+
+// GetCaption retrieves a video's WebVTT caption track for a language
+func (r *repository) GetCaption(ctx context.Context, videoID, lang string) ([]byte, error) {
+	if videoID == "" || lang == "" {
+		return nil, fmt.Errorf("video ID and language are required")
+	}
+
+	captionPath := r.CaptionPath(videoID, lang)
+
+	// Validate path is within allowed directory
+	if err := r.validatePath(captionPath, r.storage.Captions); err != nil {
+		return nil, fmt.Errorf("invalid caption path: %w", err)
+	}
+
+	data, err := os.ReadFile(captionPath) //nolint:gosec // Path validated above
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("captions not found: %s/%s", videoID, lang)
+		}
+		return nil, fmt.Errorf("failed to read caption file: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteCaption removes a video's caption track for a language
+func (r *repository) DeleteCaption(ctx context.Context, videoID, lang string) error {
+	if videoID == "" || lang == "" {
+		return fmt.Errorf("video ID and language are required")
+	}
+
+	captionPath := r.CaptionPath(videoID, lang)
+	if err := r.validatePath(captionPath, r.storage.Captions); err != nil {
+		return fmt.Errorf("invalid caption path: %w", err)
+	}
+
+	if err := os.Remove(captionPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete caption file: %w", err)
+	}
+
+	r.log(ctx).Debug("Deleted caption file: %s", captionPath)
+	return nil
+}
+
 // GetThumbnailsDir returns the thumbnails directory path
 func (r *repository) GetThumbnailsDir() string {
 	return r.storage.Thumbnails
@@ -266,3 +543,196 @@ func (r *repository) validatePath(filePath, allowedDir string) error {
 
 	return nil
 }
+
+// transcriptsDir returns the directory holding transcript files
+func (r *repository) transcriptsDir() string {
+	return filepath.Join(r.storage.Metadata, "transcripts")
+}
+
+// SaveTranscript atomically writes a video's transcript so readers never see a partial file
+func (r *repository) SaveTranscript(ctx context.Context, transcript *Transcript) error {
+	if err := os.MkdirAll(r.transcriptsDir(), 0o750); err != nil {
+		return fmt.Errorf("failed to create transcripts directory: %w", err)
+	}
+
+	transcriptPath := filepath.Join(r.transcriptsDir(), transcript.VideoID+".json")
+	if err := r.validatePath(transcriptPath, r.transcriptsDir()); err != nil {
+		return fmt.Errorf("invalid transcript path: %w", err)
+	}
+
+	data, err := json.Marshal(transcript)
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript: %w", err)
+	}
+
+	tmpPath := transcriptPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript file: %w", err)
+	}
+	if err := os.Rename(tmpPath, transcriptPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace transcript file: %w", err)
+	}
+
+	r.log(ctx).Debug("Successfully saved transcript: %s", transcriptPath)
+	return nil
+}
+
+// GetTranscript retrieves a video's transcript, returning nil if none has been saved
+func (r *repository) GetTranscript(ctx context.Context, videoID string) (*Transcript, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("video ID is required")
+	}
+
+	transcriptPath := filepath.Join(r.transcriptsDir(), videoID+".json")
+	if err := r.validatePath(transcriptPath, r.transcriptsDir()); err != nil {
+		return nil, fmt.Errorf("invalid transcript path: %w", err)
+	}
+
+	data, err := os.ReadFile(transcriptPath) //nolint:gosec // Path validated above
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+
+	var transcript Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to decode transcript: %w", err)
+	}
+
+	return &transcript, nil
+}
+
+// consentDir returns the directory holding signed consent records
+func (r *repository) consentDir() string {
+	return filepath.Join(r.storage.Metadata, "consent")
+}
+
+// SaveConsent saves a signed consent record for a video
+func (r *repository) SaveConsent(ctx context.Context, signed *SignedConsentRecord) error {
+	if err := os.MkdirAll(r.consentDir(), 0o750); err != nil {
+		return fmt.Errorf("failed to create consent directory: %w", err)
+	}
+
+	consentPath := filepath.Join(r.consentDir(), signed.Record.VideoID+".json")
+	if err := r.validatePath(consentPath, r.consentDir()); err != nil {
+		return fmt.Errorf("invalid consent path: %w", err)
+	}
+
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode consent record: %w", err)
+	}
+	if err := os.WriteFile(consentPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write consent file: %w", err)
+	}
+
+	r.log(ctx).Debug("Successfully saved consent record: %s", consentPath)
+	return nil
+}
+
+// GetConsent retrieves the signed consent record for a video
+func (r *repository) GetConsent(ctx context.Context, videoID string) (*SignedConsentRecord, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("video ID is required")
+	}
+
+	consentPath := filepath.Join(r.consentDir(), videoID+".json")
+	if err := r.validatePath(consentPath, r.consentDir()); err != nil {
+		return nil, fmt.Errorf("invalid consent path: %w", err)
+	}
+
+	data, err := os.ReadFile(consentPath) //nolint:gosec // Path validated above
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("consent record not found: %s", videoID)
+		}
+		return nil, fmt.Errorf("failed to read consent file: %w", err)
+	}
+
+	var signed SignedConsentRecord
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, fmt.Errorf("failed to decode consent record: %w", err)
+	}
+
+	return &signed, nil
+}
+
+// CleanStaleFiles removes files last modified before cutoff that nothing
+// refers to: anything in the temp directory, uploads without metadata, and
+// thumbnails without a video. Recent files are kept so uploads and FFmpeg
+// runs in progress are not cut short. With dryRun set nothing is removed.
+func (r *repository) CleanStaleFiles(ctx context.Context, cutoff time.Time, dryRun bool) ([]RemovedFile, error) {
+	// Go by metadata file names, so a video whose metadata fails to decode is not mistaken for a stray
+	entries, err := os.ReadDir(r.storage.Metadata)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read metadata directory: %w", err)
+	}
+	uploads := make(map[string]bool, len(entries))
+	thumbnails := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		filename := strings.TrimSuffix(entry.Name(), ".json")
+		uploads[filename] = true
+		thumbnails[strings.TrimSuffix(filename, filepath.Ext(filename))+".jpg"] = true
+	}
+
+	var removed []RemovedFile
+	sweep := func(dir, reason string, keep map[string]bool, recursive bool) error {
+		return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				if path != dir && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !entry.Type().IsRegular() || keep[entry.Name()] {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil || !info.ModTime().Before(cutoff) {
+				return nil
+			}
+			if err := r.validatePath(path, dir); err != nil {
+				return nil
+			}
+			if !dryRun {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					r.log(ctx).Error("Failed to remove stale file %s: %v", path, err)
+					return nil
+				}
+			}
+			removed = append(removed, RemovedFile{Path: path, Reason: reason, Bytes: info.Size(), ModifiedAt: info.ModTime()})
+			return nil
+		})
+	}
+
+	if err := sweep(r.storage.Temp, "stale temp file", nil, true); err != nil {
+		return removed, fmt.Errorf("failed to clean temp directory: %w", err)
+	}
+	if err := sweep(r.storage.Uploads, "upload without metadata", uploads, false); err != nil {
+		return removed, fmt.Errorf("failed to clean uploads directory: %w", err)
+	}
+	if err := sweep(r.storage.Thumbnails, "thumbnail without video", thumbnails, false); err != nil {
+		return removed, fmt.Errorf("failed to clean thumbnails directory: %w", err)
+	}
+	return removed, nil
+}
+
+// Ping checks that the metadata directory can be read
+func (r *repository) Ping(ctx context.Context) error {
+	if _, err := os.ReadDir(r.storage.Metadata); err != nil {
+		return fmt.Errorf("failed to read metadata directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gooji/internal/auth"
+	"gooji/internal/config"
+)
+
+// ExpiredVideo is a video a retention rule removed
+type ExpiredVideo struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Rule      string    `json:"rule"`
+	CreatedAt time.Time `json:"created_at"`
+	Bytes     int64     `json:"bytes"`
+}
+
+// RemovedFile is a stale file the janitor removed
+type RemovedFile struct {
+	Path       string    `json:"path"`
+	Reason     string    `json:"reason"`
+	Bytes      int64     `json:"bytes"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// RetentionReport lists what one janitor run removed, or would remove on a dry run
+type RetentionReport struct {
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	DryRun     bool           `json:"dry_run"`
+	Videos     []ExpiredVideo `json:"videos"`
+	Files      []RemovedFile  `json:"files"`
+	FreedBytes int64          `json:"freed_bytes"`
+	Errors     []string       `json:"errors,omitempty"`
+}
+
+// ValidateRetentionRules checks that every rule has an age, sets a condition
+// or match_all but not both, and only names access levels, consent scopes and
+// review states that exist
+func ValidateRetentionRules(rules []config.RetentionRule) error {
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.MaxAgeDays <= 0 {
+			return fmt.Errorf("retention rule %s: max_age_days must be positive", name)
+		}
+		conditions := len(rule.Tags) + len(rule.Collections) + len(rule.AccessLevels) + len(rule.ConsentScopes) + len(rule.ReviewStates)
+		if conditions == 0 && !rule.MatchAll {
+			return fmt.Errorf("retention rule %s: set at least one condition, or match_all to expire every video", name)
+		}
+		if conditions > 0 && rule.MatchAll {
+			return fmt.Errorf("retention rule %s: match_all cannot be combined with conditions", name)
+		}
+		for _, level := range rule.AccessLevels {
+			if _, err := ParseAccessLevel(level); err != nil || strings.TrimSpace(level) == "" {
+				return fmt.Errorf("retention rule %s: unknown access level %q", name, level)
+			}
+		}
+		for _, scope := range rule.ConsentScopes {
+			if _, err := ParseConsentScope(scope); err != nil {
+				return fmt.Errorf("retention rule %s: %w", name, err)
+			}
+		}
+		for _, state := range rule.ReviewStates {
+			switch ReviewState(state) {
+			case ReviewPending, ReviewApproved, ReviewRejected, ReviewChangesRequested:
+			default:
+				return fmt.Errorf("retention rule %s: unknown review state %q", name, state)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyRetention removes stale files last modified before staleBefore and
+// deletes videos that the first matching rule says have expired. With dryRun
+// set it only reports what it would remove. Failures on single items are
+// collected in the report so one bad file does not stop the rest.
+func (s *service) ApplyRetention(ctx context.Context, rules []config.RetentionRule, staleBefore time.Time, dryRun bool) (*RetentionReport, error) {
+	if err := requirePermission(ctx, auth.PermDeleteVideos); err != nil {
+		return nil, err
+	}
+	if err := ValidateRetentionRules(rules); err != nil {
+		return nil, NewValidationError("invalid retention rules", err)
+	}
+
+	now := time.Now()
+	report := &RetentionReport{StartedAt: now.UTC(), DryRun: dryRun, Videos: []ExpiredVideo{}, Files: []RemovedFile{}}
+
+	files, err := s.repo.CleanStaleFiles(ctx, staleBefore, dryRun)
+	report.Files = append(report.Files, files...)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, file := range files {
+		report.FreedBytes += file.Bytes
+	}
+
+	if len(rules) > 0 {
+		if err := s.expireVideos(ctx, rules, now, dryRun, report); err != nil {
+			return nil, err
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	if dryRun {
+		s.log(ctx, "retention", "").Info("Retention dry run: would remove %d expired videos and %d stale files (%d bytes)", len(report.Videos), len(report.Files), report.FreedBytes)
+	} else {
+		s.log(ctx, "retention", "").Info("Retention: removed %d expired videos and %d stale files (%d bytes)", len(report.Videos), len(report.Files), report.FreedBytes)
+	}
+	return report, nil
+}
+
+// expireVideos deletes videos past the age of the first rule they match
+func (s *service) expireVideos(ctx context.Context, rules []config.RetentionRule, now time.Time, dryRun bool, report *RetentionReport) error {
+	videos, err := s.repo.ListMetadata(ctx)
+	if err != nil {
+		return NewInternalError("failed to list videos", err)
+	}
+	collections, err := s.repo.ListCollections(ctx)
+	if err != nil {
+		return NewInternalError("failed to list collections", err)
+	}
+	memberOf := make(map[string][]string)
+	for _, collection := range collections {
+		for _, id := range collection.VideoIDs {
+			memberOf[id] = append(memberOf[id], collection.ID)
+		}
+	}
+
+	for i := range videos {
+		video := &videos[i]
+		rule := matchRetentionRule(rules, video, memberOf[video.ID])
+		if rule == nil || now.Sub(video.CreatedAt) < time.Duration(rule.MaxAgeDays)*24*time.Hour {
+			continue
+		}
+
+		expired := ExpiredVideo{
+			ID:        video.ID,
+			Title:     video.Title,
+			Rule:      rule.Name,
+			CreatedAt: video.CreatedAt,
+			Bytes:     s.videoSize(video),
+		}
+		if !dryRun {
+			unlock := s.videoLocks.lock(video.ID)
+			err := s.repo.DeleteVideo(ctx, video.ID)
+			unlock()
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("failed to delete video %s: %v", video.ID, err))
+				continue
+			}
+			s.recordAudit(ctx, AuditVideoExpire, video.ID, video, nil)
+			s.log(ctx, "retention", video.ID).Info("Retention rule %q expired video %s", rule.Name, video.ID)
+		}
+		report.Videos = append(report.Videos, expired)
+		report.FreedBytes += expired.Bytes
+	}
+	return nil
+}
+
+// matchRetentionRule returns the first rule whose conditions the video meets, ignoring age
+func matchRetentionRule(rules []config.RetentionRule, video *VideoMetadata, collections []string) *config.RetentionRule {
+	for i := range rules {
+		if retentionRuleMatches(&rules[i], video, collections) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// retentionRuleMatches reports whether the video meets every condition the rule sets
+func retentionRuleMatches(rule *config.RetentionRule, video *VideoMetadata, collections []string) bool {
+	if len(rule.Tags) > 0 && !anyMatch(rule.Tags, video.hasTag) {
+		return false
+	}
+	if len(rule.Collections) > 0 && !anyMatch(rule.Collections, func(id string) bool { return containsString(collections, id) }) {
+		return false
+	}
+	if len(rule.AccessLevels) > 0 {
+		level := AccessPublic
+		if video.Access != nil && video.Access.Level != "" {
+			level = video.Access.Level
+		}
+		if !anyMatch(rule.AccessLevels, func(name string) bool {
+			parsed, err := ParseAccessLevel(name)
+			return err == nil && parsed == level
+		}) {
+			return false
+		}
+	}
+	if len(rule.ConsentScopes) > 0 {
+		if video.Consent == nil || !containsString(rule.ConsentScopes, string(video.Consent.Scope)) {
+			return false
+		}
+	}
+	if len(rule.ReviewStates) > 0 && !containsString(rule.ReviewStates, string(video.Review.state())) {
+		return false
+	}
+	return true
+}
+
+// anyMatch reports whether match holds for any of the values
+func anyMatch(values []string, match func(string) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values holds value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package video
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// HandleRetention serves /api/retention: GET shows the janitor's rules and last
+// report, and POST runs it now. POST with ?dry_run=true only reports what
+// would be removed.
+func (h *Handler) HandleRetention(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeJSONResponse(w, h.janitor.Status())
+	case http.MethodPost:
+		dryRun := h.janitor.cfg.DryRun
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				h.handleValidationError(w, r, "Invalid dry_run value", err)
+				return
+			}
+			dryRun = parsed
+		}
+
+		report, err := h.janitor.RunOnce(r.Context(), dryRun)
+		if err != nil {
+			h.handleServiceError(w, r, err)
+			return
+		}
+		h.writeJSONResponse(w, report)
+	default:
+		h.handleMethodNotAllowed(w, r)
+	}
+}
+
+// Janitor returns the retention janitor, for the server to schedule and the cleanup command to run
+func (h *Handler) Janitor() *Janitor {
+	return h.janitor
+}
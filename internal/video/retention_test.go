@@ -0,0 +1,35 @@
+package video
+
+import (
+	"testing"
+
+	"gooji/internal/config"
+)
+
+func TestValidateRetentionRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  config.RetentionRule
+		valid bool
+	}{
+		{name: "empty rule", rule: config.RetentionRule{Name: "everything", MaxAgeDays: 30}, valid: false},
+		{name: "explicit catch-all", rule: config.RetentionRule{Name: "everything", MaxAgeDays: 30, MatchAll: true}, valid: true},
+		{name: "catch-all with a condition", rule: config.RetentionRule{MaxAgeDays: 30, MatchAll: true, Tags: []string{"draft"}}, valid: false},
+		{name: "tag condition", rule: config.RetentionRule{MaxAgeDays: 30, Tags: []string{"draft"}}, valid: true},
+		{name: "collection condition", rule: config.RetentionRule{MaxAgeDays: 30, Collections: []string{"col_1"}}, valid: true},
+		{name: "consent and review conditions", rule: config.RetentionRule{MaxAgeDays: 30, ConsentScopes: []string{"kiosk-only"}, ReviewStates: []string{"pending"}}, valid: true},
+		{name: "no age", rule: config.RetentionRule{Tags: []string{"draft"}}, valid: false},
+		{name: "unknown access level", rule: config.RetentionRule{MaxAgeDays: 30, AccessLevels: []string{"secret"}}, valid: false},
+		{name: "unknown consent scope", rule: config.RetentionRule{MaxAgeDays: 30, ConsentScopes: []string{"everyone"}}, valid: false},
+		{name: "unknown review state", rule: config.RetentionRule{MaxAgeDays: 30, ReviewStates: []string{"done"}}, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRetentionRules([]config.RetentionRule{tt.rule})
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateRetentionRules = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gooji/internal/auth"
+)
+
+// maxReviewHistory limits how many transitions are kept on a video
+const maxReviewHistory = 100
+
+// systemReviewer is recorded for transitions made by the server rather than a person
+const systemReviewer = "system"
+
+// ReviewState is a video's position in the moderation workflow
+type ReviewState string
+
+const (
+	// ReviewPending videos are waiting for a moderator
+	ReviewPending ReviewState = "pending"
+	// ReviewApproved videos are visible in the gallery
+	ReviewApproved ReviewState = "approved"
+	// ReviewRejected videos were declined by a moderator
+	ReviewRejected ReviewState = "rejected"
+	// ReviewChangesRequested videos need edits before they are reviewed again
+	ReviewChangesRequested ReviewState = "changes_requested"
+)
+
+// ReviewAction is a moderator decision
+type ReviewAction string
+
+const (
+	// ReviewActionApprove approves a video
+	ReviewActionApprove ReviewAction = "approve"
+	// ReviewActionReject rejects a video; a reason is required
+	ReviewActionReject ReviewAction = "reject"
+	// ReviewActionRequestChanges asks for edits; a reason is required
+	ReviewActionRequestChanges ReviewAction = "request_changes"
+)
+
+// ReviewTransition records a single change of review state
+type ReviewTransition struct {
+	From     ReviewState `json:"from,omitempty"`
+	To       ReviewState `json:"to"`
+	Reviewer string      `json:"reviewer"`
+	Reason   string      `json:"reason,omitempty"`
+	At       time.Time   `json:"at"`
+}
+
+// Review holds a video's moderation state and its transition history
+type Review struct {
+	State     ReviewState        `json:"state"`
+	Reason    string             `json:"reason,omitempty"`
+	Reviewer  string             `json:"reviewer,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	History   []ReviewTransition `json:"history"`
+}
+
+// ReviewInput represents a moderator decision.
+// Reviewer is only used when the request has no identified viewer.
+type ReviewInput struct {
+	Action   ReviewAction `json:"action"`
+	Reason   string       `json:"reason"`
+	Reviewer string       `json:"reviewer"`
+}
+
+// state returns the video's review state; videos from before moderation are approved
+func (r *Review) state() ReviewState {
+	if r == nil {
+		return ReviewApproved
+	}
+	return r.State
+}
+
+// permits reports why a viewer may not see a video in this review state, or nil if they may
+func (r *Review) permits(viewer *Viewer) error {
+	if r.state() == ReviewApproved || viewer.Can(auth.PermReviewVideos) {
+		return nil
+	}
+	return errors.New("video is awaiting moderation")
+}
+
+// transition moves the review to a new state and records who did it and why
+func (m *VideoMetadata) transition(to ReviewState, reviewer, reason string) {
+	now := time.Now().UTC()
+	if m.Review == nil {
+		m.Review = &Review{}
+	}
+
+	m.Review.History = append(m.Review.History, ReviewTransition{
+		From:     m.Review.State,
+		To:       to,
+		Reviewer: reviewer,
+		Reason:   reason,
+		At:       now,
+	})
+	if len(m.Review.History) > maxReviewHistory {
+		m.Review.History = m.Review.History[len(m.Review.History)-maxReviewHistory:]
+	}
+
+	m.Review.State = to
+	m.Review.Reason = reason
+	m.Review.Reviewer = reviewer
+	m.Review.UpdatedAt = now
+}
+
+// ReviewVideo applies a moderator decision to a video
+func (s *service) ReviewVideo(ctx context.Context, id string, input *ReviewInput) (*VideoMetadata, error) {
+	if err := requirePermission(ctx, auth.PermReviewVideos); err != nil {
+		return nil, err
+	}
+	viewer := ViewerFromContext(ctx)
+	if input == nil {
+		return nil, NewValidationError("review decision is required", nil)
+	}
+
+	var to ReviewState
+	switch input.Action {
+	case ReviewActionApprove:
+		to = ReviewApproved
+	case ReviewActionReject:
+		to = ReviewRejected
+	case ReviewActionRequestChanges:
+		to = ReviewChangesRequested
+	default:
+		return nil, NewValidationError(fmt.Sprintf("unknown review action %q", input.Action), nil)
+	}
+
+	reason := s.sanitizer.Description(input.Reason)
+	if to != ReviewApproved && reason == "" {
+		return nil, NewValidationError("a reason is required when rejecting or requesting changes", nil)
+	}
+	reviewer := viewer.Name
+	if reviewer == "" {
+		reviewer = s.sanitizer.Title(input.Reviewer)
+	}
+	if reviewer == "" {
+		return nil, NewValidationError("reviewer is required", nil)
+	}
+
+	defer s.videoLocks.lock(id)()
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+	if metadata.Review.state() == to {
+		return nil, NewConflictError(fmt.Sprintf("video is already %s", to), nil)
+	}
+
+	before := snapshot(metadata.Review)
+	metadata.transition(to, reviewer, reason)
+	if err := s.repo.SaveMetadata(ctx, metadata); err != nil {
+		return nil, NewInternalError("failed to save metadata", err)
+	}
+
+	s.recordAudit(ctx, AuditVideoReview, id, before, metadata.Review)
+
+	s.log(ctx, "review", id).Info("Video %s moved to %s by %s", id, to, reviewer)
+	return metadata, nil
+}
+
+// ListReviewQueue returns videos in a review state, oldest first.
+// An empty state lists videos waiting for a moderator.
+func (s *service) ListReviewQueue(ctx context.Context, state ReviewState) ([]VideoMetadata, error) {
+	if err := requirePermission(ctx, auth.PermReviewVideos); err != nil {
+		return nil, err
+	}
+	if state == "" {
+		state = ReviewPending
+	}
+	switch state {
+	case ReviewPending, ReviewApproved, ReviewRejected, ReviewChangesRequested:
+	default:
+		return nil, NewValidationError(fmt.Sprintf("unknown review state %q", state), nil)
+	}
+
+	videos, err := s.repo.ListMetadata(ctx)
+	if err != nil {
+		return nil, NewInternalError("failed to list videos", err)
+	}
+
+	queue := make([]VideoMetadata, 0)
+	for i := range videos {
+		if videos[i].Review.state() == state {
+			queue = append(queue, videos[i])
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].CreatedAt.Before(queue[j].CreatedAt)
+	})
+
+	return queue, nil
+}
+
+// resubmitIfChangesRequested returns an edited video to the queue after a moderator asked for changes
+func (m *VideoMetadata) resubmitIfChangesRequested() {
+	if m.Review.state() == ReviewChangesRequested {
+		m.transition(ReviewPending, systemReviewer, "resubmitted after edits")
+	}
+}
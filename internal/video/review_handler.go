@@ -0,0 +1,75 @@
+package video
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gooji/internal/middleware"
+)
+
+// HandleReview handles POST /api/videos/{id}/review with a moderator decision
+func (h *Handler) HandleReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	id, _ := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetadataBodySize)
+	var input ReviewInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		h.handleValidationError(w, r, "Invalid review decision", err)
+		return
+	}
+
+	metadata, err := h.service.ReviewVideo(r.Context(), id, &input)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, metadata)
+}
+
+// HandleReviewQueue handles GET /api/review?state=pending
+func (h *Handler) HandleReviewQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	queue, err := h.service.ListReviewQueue(r.Context(), ReviewState(r.URL.Query().Get("state")))
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeJSONResponse(w, queue)
+}
+
+// HandleReviewPage serves the moderation queue page
+func (h *Handler) HandleReviewPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	if err := h.templates["review"].ExecuteTemplate(w, "base.html", map[string]interface{}{
+		"Page":         "review",
+		"IsRecordPage": false,
+		"Nonce":        middleware.CSPNonce(r.Context()),
+	}); err != nil {
+		h.handleInternalError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package video
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"gooji/internal/config"
+)
+
+// Sanitizer cleans user-supplied text before it is stored.
+// Text is normalized to NFC, stripped of control characters and limited by
+// character count; it is stored raw and escaped only when rendered.
+type Sanitizer struct {
+	limits config.InputLimits
+}
+
+// NewSanitizer creates a sanitizer with per-field length limits
+func NewSanitizer(limits config.InputLimits) *Sanitizer {
+	return &Sanitizer{limits: limits}
+}
+
+// Title sanitizes a single-line title
+func (s *Sanitizer) Title(input string) string {
+	return s.Clean(input, s.limits.Title, false)
+}
+
+// Description sanitizes a multi-line description
+func (s *Sanitizer) Description(input string) string {
+	return s.Clean(input, s.limits.Description, true)
+}
+
+// Tag sanitizes a single tag
+func (s *Sanitizer) Tag(input string) string {
+	return s.Clean(input, s.limits.Tag, false)
+}
+
+// Keyword sanitizes a single search keyword
+func (s *Sanitizer) Keyword(input string) string {
+	return s.Clean(input, s.limits.Keyword, false)
+}
+
+// Clean normalizes input to NFC, removes control characters, trims whitespace
+// and truncates to at most maxChars user-perceived characters. Line breaks and
+// tabs are kept only when multiline is true. A non-positive maxChars disables truncation.
+func (s *Sanitizer) Clean(input string, maxChars int, multiline bool) string {
+	input = norm.NFC.String(input)
+
+	var b strings.Builder
+	b.Grow(len(input))
+	for _, r := range input {
+		switch {
+		case r == '\r':
+			// Normalize CRLF and lone CR line endings to LF
+			continue
+		case r == '\n' || r == '\t':
+			if multiline {
+				b.WriteRune(r)
+			} else {
+				b.WriteRune(' ')
+			}
+		case r == unicode.ReplacementChar, unicode.IsControl(r), isUnsafeFormatRune(r):
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.TrimSpace(truncateChars(strings.TrimSpace(b.String()), maxChars))
+}
+
+// isUnsafeFormatRune reports whether r is an invisible format character that can
+// spoof text direction. Joiners and variation selectors are allowed because
+// syllabics and emoji rely on them.
+func isUnsafeFormatRune(r rune) bool {
+	switch {
+	case r >= 0x202A && r <= 0x202E: // bidirectional embeddings and overrides
+		return true
+	case r >= 0x2066 && r <= 0x2069: // bidirectional isolates
+		return true
+	case r == 0xFEFF: // byte order mark
+		return true
+	}
+	return false
+}
+
+// truncateChars limits text to maxChars characters without splitting a base
+// character from the combining marks that follow it
+func truncateChars(text string, maxChars int) string {
+	if maxChars <= 0 {
+		return text
+	}
+
+	count := 0
+	for i, r := range text {
+		if extendsPreviousChar(r) {
+			continue
+		}
+		if count == maxChars {
+			return text[:i]
+		}
+		count++
+	}
+	return text
+}
+
+// CharCount returns the number of user-perceived characters in text
+func CharCount(text string) int {
+	count := 0
+	for _, r := range text {
+		if !extendsPreviousChar(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// extendsPreviousChar reports whether r combines with the preceding character
+func extendsPreviousChar(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == 0x200D || // zero width joiner
+		unicode.In(r, unicode.Variation_Selector)
+}
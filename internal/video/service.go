@@ -2,13 +2,20 @@ package video
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"gooji/internal/auth"
+	"gooji/internal/config"
 	"gooji/internal/logger"
+	"gooji/internal/signing"
+	"gooji/internal/storage"
+	"gooji/pkg/captions"
 	"gooji/pkg/ffmpeg"
 )
 
@@ -17,8 +24,36 @@ type Service interface {
 	ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, metadata *UploadMetadata) (*VideoMetadata, error)
 	GetVideo(ctx context.Context, id string) (*VideoMetadata, error)
 	ListVideos(ctx context.Context) ([]VideoMetadata, error)
+	SearchVideos(ctx context.Context, query *VideoQuery) ([]LocalizedVideo, error)
+	GetLocalizedVideo(ctx context.Context, id string, languages []string) (*LocalizedVideo, error)
+	UpdateLocalizedMetadata(ctx context.Context, id string, input *LocalizedMetadataInput) (*VideoMetadata, error)
+	SaveCaptions(ctx context.Context, id, lang string, data []byte, format captions.Format, label string) (*CaptionTrack, error)
+	GetCaptions(ctx context.Context, id, lang string, format captions.Format) ([]byte, error)
+	ListCaptions(ctx context.Context, id string) ([]CaptionTrack, error)
+	DeleteCaptions(ctx context.Context, id, lang string) error
+	BurnInCaptions(ctx context.Context, id, lang string) (*VideoMetadata, error)
 	DeleteVideo(ctx context.Context, id string) error
 	GenerateThumbnail(ctx context.Context, videoPath string) error
+	CreateCollection(ctx context.Context, input *CollectionInput) (*Collection, error)
+	GetCollection(ctx context.Context, id string) (*CollectionDetail, error)
+	ListCollections(ctx context.Context) ([]Collection, error)
+	UpdateCollection(ctx context.Context, id string, input *CollectionInput) (*Collection, error)
+	DeleteCollection(ctx context.Context, id string) error
+	GetTranscript(ctx context.Context, id string) (*Transcript, error)
+	CreateSegment(ctx context.Context, id string, input *SegmentInput) (*Segment, error)
+	UpdateSegment(ctx context.Context, id, segmentID string, input *SegmentInput) (*Segment, error)
+	DeleteSegment(ctx context.Context, id, segmentID string, version int) error
+	ExportTranscript(ctx context.Context, id string, format TranscriptFormat) ([]byte, error)
+	ExportConsent(ctx context.Context, id string) (*SignedConsentRecord, error)
+	VerifyConsent(signed *SignedConsentRecord) bool
+	UpdateAccess(ctx context.Context, id string, input *AccessInput) (*VideoMetadata, error)
+	ReviewVideo(ctx context.Context, id string, input *ReviewInput) (*VideoMetadata, error)
+	ListReviewQueue(ctx context.Context, state ReviewState) ([]VideoMetadata, error)
+	DeviceUploadStats(ctx context.Context) (map[string]auth.DeviceStats, error)
+	SignMediaURL(ctx context.Context, id string, input *SignedURLInput) (*SignedURL, error)
+	GetSignedVideo(ctx context.Context, id string, grant *MediaGrant) (*VideoMetadata, error)
+	StorageUsage(ctx context.Context) (*StorageUsage, error)
+	ApplyRetention(ctx context.Context, rules []config.RetentionRule, staleBefore time.Time, dryRun bool) (*RetentionReport, error)
 }
 
 // Repository defines the interface for data persistence operations
@@ -30,35 +65,69 @@ type Repository interface {
 	DeleteVideo(ctx context.Context, id string) error
 	VideoExists(ctx context.Context, id string) bool
 	GetThumbnailsDir() string
+	VideoPath(filename string) string
+	CaptionPath(videoID, lang string) string
+	SaveCaption(ctx context.Context, videoID, lang string, data []byte) error
+	GetCaption(ctx context.Context, videoID, lang string) ([]byte, error)
+	DeleteCaption(ctx context.Context, videoID, lang string) error
+	SaveCollection(ctx context.Context, collection *Collection) error
+	GetCollection(ctx context.Context, id string) (*Collection, error)
+	ListCollections(ctx context.Context) ([]Collection, error)
+	DeleteCollection(ctx context.Context, id string) error
+	SaveTranscript(ctx context.Context, transcript *Transcript) error
+	GetTranscript(ctx context.Context, videoID string) (*Transcript, error)
+	SaveConsent(ctx context.Context, signed *SignedConsentRecord) error
+	GetConsent(ctx context.Context, videoID string) (*SignedConsentRecord, error)
+	CleanStaleFiles(ctx context.Context, cutoff time.Time, dryRun bool) ([]RemovedFile, error)
+	Ping(ctx context.Context) error
 }
 
 // Processor defines the interface for video processing operations
 type Processor interface {
-	GetVideoInfo(inputPath string) (*ffmpeg.VideoInfo, error)
-	ValidateVideo(inputPath string) error
+	GetVideoInfo(ctx context.Context, inputPath string) (*ffmpeg.VideoInfo, error)
+	ValidateVideo(ctx context.Context, inputPath string) error
 }
 
 // ThumbnailProcessor defines the interface for thumbnail generation operations
 type ThumbnailProcessor interface {
-	GenerateThumbnail(inputPath, outputPath string, timestamp float64) error
+	GenerateThumbnail(ctx context.Context, inputPath, outputPath string, timestamp float64) error
 }
 
-// VideoMetadata represents metadata for a recorded video
+// VideoMetadata represents metadata for a recorded video.
+// Title and Description hold the primary-language text; translations live in the localized fields.
 type VideoMetadata struct {
-	ID          string    `json:"id"`
-	Filename    string    `json:"filename"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Duration    float64   `json:"duration"`
-	CreatedAt   time.Time `json:"created_at"`
-	Tags        []string  `json:"tags"`
+	ID           string            `json:"id"`
+	Filename     string            `json:"filename"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Language     string            `json:"language,omitempty"`
+	Titles       LocalizedText     `json:"titles,omitempty"`
+	Descriptions LocalizedText     `json:"descriptions,omitempty"`
+	Keywords     LocalizedKeywords `json:"keywords,omitempty"`
+	Duration     float64           `json:"duration"`
+	Size         int64             `json:"size,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Tags         []string          `json:"tags"`
+	Captions     []CaptionTrack    `json:"captions,omitempty"`
+	SourceID     string            `json:"source_id,omitempty"`
+	Consent      *Consent          `json:"consent,omitempty"`
+	Access       *Access           `json:"access,omitempty"`
+	Review       *Review           `json:"review,omitempty"`
+	UploadedBy   string            `json:"uploaded_by,omitempty"`
+	Device       *DeviceRef        `json:"device,omitempty"`
 }
 
 // UploadMetadata represents metadata for video uploads
 type UploadMetadata struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Language     string            `json:"language"`
+	Titles       LocalizedText     `json:"titles"`
+	Descriptions LocalizedText     `json:"descriptions"`
+	Keywords     LocalizedKeywords `json:"keywords"`
+	Tags         []string          `json:"tags"`
+	Consent      *ConsentInput     `json:"consent"`
+	Access       *AccessInput      `json:"access"`
 }
 
 // VideoInfo contains metadata about a video file
@@ -69,41 +138,100 @@ type service struct {
 	repo               Repository
 	processor          Processor
 	thumbnailProcessor ThumbnailProcessor
+	captionBurner      CaptionBurner
+	sanitizer          *Sanitizer
+	uploads            *UploadRules
+	signer             *signing.Signer
+	meter              *storage.Meter
+	audit              AuditRecorder
 	logger             *logger.Logger
+
+	// transcriptMu serializes transcript read-modify-write cycles
+	transcriptMu sync.Mutex
+	// videoLocks serializes metadata read-modify-write cycles per video
+	videoLocks idLocks
+	// collectionLocks does the same for collections
+	collectionLocks idLocks
 }
 
 // NewService creates a new video service
-func NewService(repo Repository, processor Processor, thumbnailProcessor ThumbnailProcessor, logger *logger.Logger) Service {
+func NewService(repo Repository, processor Processor, thumbnailProcessor ThumbnailProcessor, captionBurner CaptionBurner, sanitizer *Sanitizer, uploads *UploadRules, signer *signing.Signer, meter *storage.Meter, auditLog AuditRecorder, logger *logger.Logger) Service {
 	return &service{
 		repo:               repo,
 		processor:          processor,
 		thumbnailProcessor: thumbnailProcessor,
+		captionBurner:      captionBurner,
+		sanitizer:          sanitizer,
+		uploads:            uploads,
+		signer:             signer,
+		meter:              meter,
+		audit:              auditLog,
 		logger:             logger,
 	}
 }
 
+// log returns the request's logger tagged with the operation and video, either of which may be empty
+func (s *service) log(ctx context.Context, operation, videoID string) *logger.Logger {
+	log := logger.FromContext(ctx, s.logger)
+	if operation != "" {
+		log = log.With(logger.FieldOperation, operation)
+	}
+	if videoID != "" {
+		log = log.With(logger.FieldVideoID, videoID)
+	}
+	return log
+}
+
 // ProcessUpload handles the complete video upload process
 func (s *service) ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, metadata *UploadMetadata) (*VideoMetadata, error) {
+	if err := requirePermission(ctx, auth.PermUploadVideos); err != nil {
+		return nil, err
+	}
 	// Validate upload
 	if err := s.validateUpload(file, header); err != nil {
 		return nil, fmt.Errorf("upload validation failed: %w", err)
 	}
 
+	// Validate consent before anything is stored
+	consent, err := s.newConsentRecord(ctx, metadata.Consent)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.newAccess(metadata.Access)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse uploads that would fill the disk or exceed a quota before writing anything
+	if err := s.checkStorageSpace(header.Size); err != nil {
+		return nil, err
+	}
+	if err := s.checkUploadQuota(ctx, header.Size); err != nil {
+		return nil, err
+	}
+
 	// Generate secure filename
 	filename := s.generateSecureFilename(header.Filename)
+	log := s.log(ctx, "upload", filename)
 
 	// Save video file
 	videoPath, err := s.repo.SaveVideo(ctx, file, filename)
 	if err != nil {
+		if isDiskFull(err) {
+			return nil, NewQuotaError("The disk is full, so the video could not be saved. Ask an administrator to free up space.", err)
+		}
 		return nil, fmt.Errorf("failed to save video: %w", err)
 	}
 
 	// Get video information
-	info, err := s.processor.GetVideoInfo(videoPath)
+	info, err := s.processor.GetVideoInfo(ctx, videoPath)
 	if err != nil {
 		// Clean up saved file on error
 		if cleanupErr := s.repo.DeleteVideo(ctx, filename); cleanupErr != nil {
-			s.logger.Error("Failed to cleanup video file after error: %v", cleanupErr)
+			log.Error("Failed to cleanup video file after error: %v", cleanupErr)
+		}
+		if errors.Is(err, ffmpeg.ErrBusy) {
+			return nil, NewBusyError("video processing is busy, try again shortly", err)
 		}
 		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
@@ -112,31 +240,64 @@ func (s *service) ProcessUpload(ctx context.Context, file multipart.File, header
 	videoMetadata := &VideoMetadata{
 		ID:          filename,
 		Filename:    filename,
-		Title:       s.sanitizeInput(metadata.Title),
-		Description: s.sanitizeInput(metadata.Description),
+		Title:       s.sanitizer.Title(metadata.Title),
+		Description: s.sanitizer.Description(metadata.Description),
 		Duration:    info.Duration,
+		Size:        storage.FileSize(videoPath),
 		CreatedAt:   time.Now(),
 		Tags:        s.sanitizeTags(metadata.Tags),
+		Consent:     consent.summary(),
+		Access:      access,
+		UploadedBy:  ViewerFromContext(ctx).UserID,
+		Device:      deviceRef(ViewerFromContext(ctx).Device),
+	}
+	videoMetadata.transition(ReviewPending, systemReviewer, "")
+
+	// Merge language-tagged titles, descriptions and keywords
+	if err := s.applyLocalizedInput(videoMetadata, &LocalizedMetadataInput{
+		Language:     metadata.Language,
+		Titles:       metadata.Titles,
+		Descriptions: metadata.Descriptions,
+		Keywords:     metadata.Keywords,
+	}); err != nil {
+		if cleanupErr := s.repo.DeleteVideo(ctx, filename); cleanupErr != nil {
+			log.Error("Failed to cleanup video file after metadata error: %v", cleanupErr)
+		}
+		return nil, err
+	}
+
+	// Sign and save the consent record before the video becomes readable
+	consent.VideoID = filename
+	signed, err := s.signConsentRecord(consent)
+	if err == nil {
+		err = s.repo.SaveConsent(ctx, signed)
+	}
+	if err != nil {
+		if cleanupErr := s.repo.DeleteVideo(ctx, filename); cleanupErr != nil {
+			log.Error("Failed to cleanup video file after consent error: %v", cleanupErr)
+		}
+		return nil, NewInternalError("failed to save consent record", err)
 	}
 
 	// Save metadata
 	if err := s.repo.SaveMetadata(ctx, videoMetadata); err != nil {
 		// Clean up saved file on error
 		if cleanupErr := s.repo.DeleteVideo(ctx, filename); cleanupErr != nil {
-			s.logger.Error("Failed to cleanup video file after metadata save error: %v", cleanupErr)
+			log.Error("Failed to cleanup video file after metadata save error: %v", cleanupErr)
 		}
 		return nil, fmt.Errorf("failed to save metadata: %w", err)
 	}
 
 	// Generate thumbnail synchronously to ensure it's available immediately
 	if err := s.GenerateThumbnail(ctx, videoPath); err != nil {
-		s.logger.Error("Failed to generate thumbnail for %s: %v", videoPath, err)
+		log.Error("Failed to generate thumbnail for %s: %v", videoPath, err)
 		// Don't fail the upload if thumbnail generation fails
 	} else {
-		s.logger.Info("Successfully generated thumbnail for: %s", videoPath)
+		log.Info("Successfully generated thumbnail for: %s", videoPath)
 	}
 
-	s.logger.Info("Successfully processed video upload: %s", filename)
+	s.recordAudit(ctx, AuditVideoUpload, videoMetadata.ID, nil, videoMetadata)
+	log.Info("Successfully processed video upload: %s", filename)
 	return videoMetadata, nil
 }
 
@@ -148,33 +309,48 @@ func (s *service) GetVideo(ctx context.Context, id string) (*VideoMetadata, erro
 
 	metadata, err := s.repo.GetMetadata(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get video metadata: %w", err)
+		return nil, NewNotFoundError("video not found", err)
+	}
+
+	if err := s.authorizeView(ctx, metadata); err != nil {
+		return nil, err
 	}
 
 	return metadata, nil
 }
 
-// ListVideos retrieves all video metadata
+// ListVideos retrieves metadata for the videos the current viewer may see
 func (s *service) ListVideos(ctx context.Context) ([]VideoMetadata, error) {
 	videos, err := s.repo.ListMetadata(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list videos: %w", err)
 	}
 
-	return videos, nil
+	return s.filterViewable(ctx, videos), nil
 }
 
 // DeleteVideo removes a video and its metadata
 func (s *service) DeleteVideo(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, auth.PermDeleteVideos); err != nil {
+		return err
+	}
 	if id == "" {
 		return fmt.Errorf("video ID is required")
 	}
 
+	defer s.videoLocks.lock(id)()
+	// Keep what was deleted so the audit log can say what was lost
+	var before *VideoMetadata
+	if metadata, err := s.repo.GetMetadata(ctx, id); err == nil {
+		before = metadata
+	}
+
 	if err := s.repo.DeleteVideo(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete video: %w", err)
 	}
 
-	s.logger.Info("Successfully deleted video: %s", id)
+	s.recordAudit(ctx, AuditVideoDelete, id, before, nil)
+	s.log(ctx, "delete", id).Info("Successfully deleted video: %s", id)
 	return nil
 }
 
@@ -192,7 +368,7 @@ func (s *service) GenerateThumbnail(ctx context.Context, videoPath string) error
 	thumbnailPath := filepath.Join(s.repo.GetThumbnailsDir(), thumbnailName)
 
 	// Generate thumbnail at 1 second mark
-	if err := s.thumbnailProcessor.GenerateThumbnail(videoPath, thumbnailPath, 1.0); err != nil {
+	if err := s.thumbnailProcessor.GenerateThumbnail(ctx, videoPath, thumbnailPath, 1.0); err != nil {
 		return fmt.Errorf("failed to generate thumbnail: %w", err)
 	}
 
@@ -201,26 +377,9 @@ func (s *service) GenerateThumbnail(ctx context.Context, videoPath string) error
 
 // validateUpload validates the uploaded file
 func (s *service) validateUpload(file multipart.File, header *multipart.FileHeader) error {
-	// Check file size (max 100MB)
-	const maxSize = 100 * 1024 * 1024
-	if header.Size > maxSize {
-		return fmt.Errorf("file size %d exceeds maximum allowed size %d", header.Size, maxSize)
-	}
-
-	// Validate MIME type
-	allowedTypes := []string{"video/mp4", "video/webm", "video/avi", "video/mov"}
-	contentType := header.Header.Get("Content-Type")
-
-	isAllowed := false
-	for _, allowedType := range allowedTypes {
-		if contentType == allowedType {
-			isAllowed = true
-			break
-		}
-	}
-
-	if !isAllowed {
-		return fmt.Errorf("content type %s is not allowed", contentType)
+	// Check size and MIME type against the configured, reloadable limits
+	if err := s.uploads.check(header); err != nil {
+		return err
 	}
 
 	// Validate file extension
@@ -289,23 +448,6 @@ func (s *service) generateSecureFilename(originalName string) string {
 	return fmt.Sprintf("%d_%s%s", timestamp, generateUUID(), ext)
 }
 
-// sanitizeInput sanitizes user input to prevent XSS
-func (s *service) sanitizeInput(input string) string {
-	// Trim whitespace and limit length
-	input = strings.TrimSpace(input)
-	if len(input) > 200 {
-		input = input[:200]
-	}
-
-	// Basic HTML escaping (additional protection beyond template escaping)
-	input = strings.ReplaceAll(input, "<", "&lt;")
-	input = strings.ReplaceAll(input, ">", "&gt;")
-	input = strings.ReplaceAll(input, "\"", "&quot;")
-	input = strings.ReplaceAll(input, "'", "&#39;")
-
-	return input
-}
-
 // sanitizeTags sanitizes and validates tags
 func (s *service) sanitizeTags(tags []string) []string {
 	if tags == nil {
@@ -314,8 +456,7 @@ func (s *service) sanitizeTags(tags []string) []string {
 
 	sanitized := make([]string, 0, len(tags))
 	for _, tag := range tags {
-		tag = s.sanitizeInput(tag)
-		if tag != "" && len(tag) <= 50 {
+		if tag = s.sanitizer.Tag(tag); tag != "" {
 			sanitized = append(sanitized, tag)
 		}
 	}
@@ -0,0 +1,158 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gooji/internal/auth"
+)
+
+const (
+	// defaultSignedURLTTL applies when a link is created without an expiry
+	defaultSignedURLTTL = 7 * 24 * time.Hour
+	// maxSignedURLTTL bounds how long a shared link may work, roughly one school term
+	maxSignedURLTTL = 180 * 24 * time.Hour
+	// signedURLVersion separates media link signatures from other uses of the signing key
+	signedURLVersion = "gooji-media-url:v1"
+)
+
+// MediaScope says how a signed video link may be used
+type MediaScope string
+
+// Media scopes
+const (
+	// MediaScopeStream plays the video in the browser
+	MediaScopeStream MediaScope = "stream"
+	// MediaScopeDownload serves the video as an attachment
+	MediaScopeDownload MediaScope = "download"
+	// mediaScopeThumbnail is the scope of a signed thumbnail link
+	mediaScopeThumbnail MediaScope = "thumbnail"
+)
+
+// SignedURLInput is the body of POST /api/videos/{id}/links
+type SignedURLInput struct {
+	Scope         MediaScope `json:"scope"`
+	ExpiresInDays int        `json:"expires_in_days"`
+}
+
+// SignedURL is a shareable, expiring link to a video and its thumbnail
+type SignedURL struct {
+	URL          string     `json:"url"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	Scope        MediaScope `json:"scope"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+}
+
+// MediaGrant is the signature carried by a signed media request
+type MediaGrant struct {
+	Scope     MediaScope
+	Expires   int64
+	Signature string
+}
+
+// linkViewer is who a signed link's holder is treated as: a community member
+// without cultural roles, so links never reach restricted or kiosk-only recordings
+var linkViewer = Viewer{Member: true, Role: auth.RoleViewer, Name: "Signed link"}
+
+// SignMediaURL creates expiring links to a video for sharing outside the app.
+// Only videos a community member could watch can be shared this way.
+func (s *service) SignMediaURL(ctx context.Context, id string, input *SignedURLInput) (*SignedURL, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return nil, NewValidationError("link details are required", nil)
+	}
+
+	scope := input.Scope
+	if scope == "" {
+		scope = MediaScopeStream
+	}
+	if scope != MediaScopeStream && scope != MediaScopeDownload {
+		return nil, NewValidationError(fmt.Sprintf("unknown link scope %q: use stream or download", scope), nil)
+	}
+
+	ttl := defaultSignedURLTTL
+	if input.ExpiresInDays < 0 {
+		return nil, NewValidationError("expires_in_days must not be negative", nil)
+	}
+	if input.ExpiresInDays > 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > maxSignedURLTTL {
+		return nil, NewValidationError(fmt.Sprintf("links may last at most %d days", int(maxSignedURLTTL/(24*time.Hour))), nil)
+	}
+
+	metadata, err := s.GetVideo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeView(WithViewer(ctx, &linkViewer), metadata); err != nil {
+		return nil, NewValidationError("video cannot be shared by link: "+err.Error(), nil)
+	}
+
+	expiresAt := time.Now().Add(ttl).UTC().Truncate(time.Second)
+	expires := expiresAt.Unix()
+
+	videoQuery := url.Values{}
+	videoQuery.Set("scope", string(scope))
+	videoQuery.Set("expires", strconv.FormatInt(expires, 10))
+	videoQuery.Set("sig", s.signMedia(metadata.ID, scope, expires))
+
+	thumbnailQuery := url.Values{}
+	thumbnailQuery.Set("id", metadata.ID)
+	thumbnailQuery.Set("expires", strconv.FormatInt(expires, 10))
+	thumbnailQuery.Set("sig", s.signMedia(metadata.ID, mediaScopeThumbnail, expires))
+
+	s.recordAudit(ctx, AuditVideoLink, metadata.ID, nil, map[string]interface{}{
+		"scope":      scope,
+		"expires_at": expiresAt,
+	})
+	s.log(ctx, "sign_url", id).Info("%s created a %s link to video %s expiring %s",
+		ViewerFromContext(ctx).Name, scope, metadata.ID, expiresAt.Format(time.RFC3339))
+
+	return &SignedURL{
+		URL:          "/api/videos/" + url.PathEscape(metadata.ID) + "?" + videoQuery.Encode(),
+		ThumbnailURL: "/api/thumbnails?" + thumbnailQuery.Encode(),
+		Scope:        scope,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// GetSignedVideo verifies a signed media request and returns the video it grants.
+// The grant stands in for the requester's own identity, and the video is checked
+// again as a link holder would see it, so withdrawn consent, a new access level
+// or a pending review stop a link working before it expires.
+func (s *service) GetSignedVideo(ctx context.Context, id string, grant *MediaGrant) (*VideoMetadata, error) {
+	if id == "" || grant == nil {
+		return nil, NewValidationError("video ID and signature are required", nil)
+	}
+	if !s.signer.Verify([]byte(mediaPayload(id, grant.Scope, grant.Expires)), grant.Signature) {
+		return nil, NewSecurityError("invalid link signature", nil)
+	}
+	if !time.Now().Before(time.Unix(grant.Expires, 0)) {
+		return nil, NewSecurityError("link has expired", nil)
+	}
+
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+	if err := s.authorizeView(WithViewer(ctx, &linkViewer), metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// signMedia signs a media link for a video, scope and expiry
+func (s *service) signMedia(id string, scope MediaScope, expires int64) string {
+	return s.signer.Sign([]byte(mediaPayload(id, scope, expires)))
+}
+
+// mediaPayload is the signed form of a media link
+func mediaPayload(id string, scope MediaScope, expires int64) string {
+	return fmt.Sprintf("%s\n%s\n%s\n%d", signedURLVersion, id, scope, expires)
+}
@@ -0,0 +1,75 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"gooji/internal/middleware"
+)
+
+// HandleLinks handles POST /api/videos/{id}/links, creating a signed link to share the video
+func (h *Handler) HandleLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	id, _ := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	if !middleware.RequireJSON(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetadataBodySize)
+	var input SignedURLInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		h.handleValidationError(w, r, "Invalid link request", err)
+		return
+	}
+
+	link, err := h.service.SignMediaURL(r.Context(), id, &input)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.writeJSONResponse(w, link)
+}
+
+// mediaGrant reads the signature of a signed media request; ok is false for unsigned requests
+func mediaGrant(r *http.Request, scope MediaScope) (grant *MediaGrant, ok bool, err error) {
+	query := r.URL.Query()
+	if !query.Has("sig") {
+		return nil, false, nil
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid link expiry: %w", err)
+	}
+	if scope == "" {
+		scope = MediaScope(query.Get("scope"))
+		if scope != MediaScopeStream && scope != MediaScopeDownload {
+			return nil, true, fmt.Errorf("invalid link scope %q", scope)
+		}
+	}
+	return &MediaGrant{Scope: scope, Expires: expires, Signature: query.Get("sig")}, true, nil
+}
+
+// setSignedMediaHeaders keeps signed responses out of shared caches and
+// serves downloads as attachments
+func setSignedMediaHeaders(w http.ResponseWriter, grant *MediaGrant, filename string) {
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if grant.Scope == MediaScopeDownload {
+		w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+	}
+}
@@ -0,0 +1,84 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"gooji/internal/signing"
+)
+
+func TestSignatureDomains(t *testing.T) {
+	signer, err := signing.New([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("signing.New: %v", err)
+	}
+	s := &service{signer: signer}
+
+	const id = "talk.mp4"
+	expires := time.Now().Add(time.Hour).Unix()
+	record := &ConsentRecord{VideoID: id, StatementVersion: "v1", Scope: ConsentScopeCommunity, RecordedAt: time.Now().UTC()}
+	consent, err := s.signConsentRecord(record)
+	if err != nil {
+		t.Fatalf("signConsentRecord: %v", err)
+	}
+	consentPayload, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("encode consent record: %v", err)
+	}
+	mediaSignature := s.signMedia(id, MediaScopeStream, expires)
+
+	tests := []struct {
+		name  string
+		valid func() bool
+		want  bool
+	}{
+		{
+			name:  "consent signature verifies as consent",
+			valid: func() bool { return s.VerifyConsent(consent) },
+			want:  true,
+		},
+		{
+			name:  "media signature verifies as media",
+			valid: func() bool { return signer.Verify([]byte(mediaPayload(id, MediaScopeStream, expires)), mediaSignature) },
+			want:  true,
+		},
+		{
+			name: "media signature rejected on a consent record",
+			valid: func() bool {
+				forged := *consent
+				forged.Signature = mediaSignature
+				return s.VerifyConsent(&forged)
+			},
+		},
+		{
+			name: "consent signature rejected as a media link",
+			valid: func() bool {
+				_, err := s.GetSignedVideo(context.Background(), id, &MediaGrant{Scope: MediaScopeStream, Expires: expires, Signature: consent.Signature})
+				var videoErr *VideoError
+				if !errors.As(err, &videoErr) || videoErr.Type != ErrorTypeSecurity {
+					t.Fatalf("GetSignedVideo error = %v, want a security error", err)
+				}
+				return false
+			},
+		},
+		{
+			name:  "media payload cannot be read as a consent record",
+			valid: func() bool { return json.Valid([]byte(mediaPayload(id, MediaScopeStream, expires))) },
+		},
+		{
+			name:  "consent payload cannot be read as a media link",
+			valid: func() bool { return string(consentPayload[:len(signedURLVersion)]) == signedURLVersion },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.valid(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
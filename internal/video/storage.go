@@ -0,0 +1,194 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"syscall"
+
+	"gooji/internal/auth"
+	"gooji/internal/config"
+	"gooji/internal/storage"
+)
+
+// OwnerUsage is the space used by the videos one account or paired kiosk uploaded
+type OwnerUsage struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	Videos     int    `json:"videos"`
+	Bytes      int64  `json:"bytes"`
+	QuotaBytes int64  `json:"quota_bytes,omitempty"`
+}
+
+// StorageUsage is the body of GET /api/storage
+type StorageUsage struct {
+	Disk        *storage.Disk       `json:"disk,omitempty"`
+	LowSpace    bool                `json:"low_space"`
+	TotalBytes  int64               `json:"total_bytes"`
+	Quotas      config.Quotas       `json:"quotas"`
+	Directories []storage.Directory `json:"directories"`
+	Users       []OwnerUsage        `json:"users"`
+	Devices     []OwnerUsage        `json:"devices"`
+}
+
+// StorageUsage reports disk space and the space used per storage directory, account and device
+func (s *service) StorageUsage(ctx context.Context) (*StorageUsage, error) {
+	if err := requirePermission(ctx, auth.PermViewStorage); err != nil {
+		return nil, err
+	}
+	if s.meter == nil {
+		return nil, NewInternalError("storage accounting is not available", nil)
+	}
+
+	usage := &StorageUsage{Quotas: s.meter.Quotas()}
+	disk, err := s.meter.Disk()
+	if err != nil {
+		s.log(ctx, "storage_usage", "").Error("Failed to measure free disk space: %v", err)
+	} else {
+		usage.Disk = disk
+		usage.LowSpace = s.meter.Low(disk)
+	}
+
+	dirs, err := s.meter.Directories()
+	if err != nil {
+		return nil, NewInternalError("failed to measure storage", err)
+	}
+	usage.Directories = dirs
+	usage.TotalBytes = storage.Total(dirs)
+
+	videos, err := s.repo.ListMetadata(ctx)
+	if err != nil {
+		return nil, NewInternalError("failed to list videos", err)
+	}
+	users, devices := s.ownerUsage(videos)
+	usage.Users = sortedUsage(users, usage.Quotas.PerUserBytes)
+	usage.Devices = sortedUsage(devices, usage.Quotas.PerDeviceBytes)
+	return usage, nil
+}
+
+// checkStorageSpace refuses to write size more bytes when that would cross the
+// low watermark or the storage cap
+func (s *service) checkStorageSpace(size int64) error {
+	if s.meter == nil {
+		return nil
+	}
+	quotas := s.meter.Quotas()
+
+	if quotas.MinFreeBytes > 0 {
+		disk, err := s.meter.Disk()
+		if err != nil {
+			// Not knowing is no reason to stop a recording; the write itself may still fail
+			s.logger.Error("Failed to measure free disk space: %v", err)
+		} else if disk.AvailableBytes-size < quotas.MinFreeBytes {
+			return NewQuotaError(fmt.Sprintf("Not enough disk space for this video: %s free, %s must stay free. Ask an administrator to free up space.",
+				storage.FormatBytes(disk.AvailableBytes), storage.FormatBytes(quotas.MinFreeBytes)), nil)
+		}
+	}
+
+	if quotas.MaxStorageBytes > 0 {
+		dirs, err := s.meter.Directories()
+		if err != nil {
+			return NewInternalError("failed to measure storage", err)
+		}
+		if used := storage.Total(dirs); used+size > quotas.MaxStorageBytes {
+			return NewQuotaError(fmt.Sprintf("Video storage is full: %s of %s used. Ask an administrator to free up space.",
+				storage.FormatBytes(used), storage.FormatBytes(quotas.MaxStorageBytes)), nil)
+		}
+	}
+	return nil
+}
+
+// checkUploadQuota refuses an upload of size bytes that would take the
+// uploading account or paired kiosk over its quota
+func (s *service) checkUploadQuota(ctx context.Context, size int64) error {
+	if s.meter == nil {
+		return nil
+	}
+	quotas := s.meter.Quotas()
+	viewer := ViewerFromContext(ctx)
+	checkUser := quotas.PerUserBytes > 0 && viewer.UserID != ""
+	checkDevice := quotas.PerDeviceBytes > 0 && viewer.Device != nil
+	if !checkUser && !checkDevice {
+		return nil
+	}
+
+	videos, err := s.repo.ListMetadata(ctx)
+	if err != nil {
+		return NewInternalError("failed to list videos", err)
+	}
+	users, devices := s.ownerUsage(videos)
+
+	if checkUser {
+		if used := users[viewer.UserID].Bytes; used+size > quotas.PerUserBytes {
+			return NewQuotaError(fmt.Sprintf("Your upload quota is used up: %s of %s. Delete old videos or ask an administrator for more space.",
+				storage.FormatBytes(used), storage.FormatBytes(quotas.PerUserBytes)), nil)
+		}
+	}
+	if checkDevice {
+		if used := devices[viewer.Device.ID].Bytes; used+size > quotas.PerDeviceBytes {
+			return NewQuotaError(fmt.Sprintf("This kiosk's upload quota is used up: %s of %s. Ask an administrator for more space.",
+				storage.FormatBytes(used), storage.FormatBytes(quotas.PerDeviceBytes)), nil)
+		}
+	}
+	return nil
+}
+
+// ownerUsage sums video sizes per uploading account and per paired kiosk
+func (s *service) ownerUsage(videos []VideoMetadata) (users, devices map[string]*OwnerUsage) {
+	users = make(map[string]*OwnerUsage)
+	devices = make(map[string]*OwnerUsage)
+	add := func(owners map[string]*OwnerUsage, id, name string, size int64) {
+		owner, ok := owners[id]
+		if !ok {
+			owner = &OwnerUsage{ID: id}
+			owners[id] = owner
+		}
+		if name != "" {
+			owner.Name = name
+		}
+		owner.Videos++
+		owner.Bytes += size
+	}
+
+	for i := range videos {
+		size := s.videoSize(&videos[i])
+		if videos[i].UploadedBy != "" {
+			add(users, videos[i].UploadedBy, "", size)
+		}
+		if device := videos[i].Device; device != nil {
+			add(devices, device.ID, device.Name, size)
+		}
+	}
+	return users, devices
+}
+
+// videoSize returns the size of a video file, measuring it for videos
+// uploaded before sizes were recorded
+func (s *service) videoSize(metadata *VideoMetadata) int64 {
+	if metadata.Size > 0 {
+		return metadata.Size
+	}
+	return storage.FileSize(s.repo.VideoPath(metadata.Filename))
+}
+
+// sortedUsage lists owners by the space they use, largest first
+func sortedUsage(owners map[string]*OwnerUsage, quota int64) []OwnerUsage {
+	list := make([]OwnerUsage, 0, len(owners))
+	for _, owner := range owners {
+		owner.QuotaBytes = quota
+		list = append(list, *owner)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// isDiskFull reports whether a write failed because the disk is full
+func isDiskFull(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
@@ -0,0 +1,18 @@
+package video
+
+import "net/http"
+
+// HandleStorage serves GET /api/storage: free space and usage per directory, account and device
+func (h *Handler) HandleStorage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.handleMethodNotAllowed(w, r)
+		return
+	}
+
+	usage, err := h.service.StorageUsage(r.Context())
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+	h.writeJSONResponse(w, usage)
+}
@@ -0,0 +1,388 @@
+package video
+
+import (
+	"context"
+	"mime/multipart"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"gooji/internal/auth"
+	"gooji/internal/config"
+	"gooji/pkg/captions"
+)
+
+// tracer records spans for service methods and repository operations; it is
+// a no-op until a tracer provider is installed
+var tracer = otel.Tracer("gooji/internal/video")
+
+// startSpan starts a span tagged with the video it concerns, if any
+func startSpan(ctx context.Context, name, videoID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if videoID != "" {
+		attrs = append(attrs, attribute.String("gooji.video_id", videoID))
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan marks the span failed when err is set and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedService records a span around each Service method
+type tracedService struct {
+	next Service
+}
+
+// traceService wraps a service so each of its methods records a span
+func traceService(next Service) Service {
+	return &tracedService{next: next}
+}
+
+func (t *tracedService) ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, metadata *UploadMetadata) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.ProcessUpload", "", attribute.Int64("gooji.upload.size", header.Size))
+	defer func() {
+		if result != nil {
+			span.SetAttributes(attribute.String("gooji.video_id", result.ID))
+		}
+		endSpan(span, err)
+	}()
+	return t.next.ProcessUpload(ctx, file, header, metadata)
+}
+
+func (t *tracedService) GetVideo(ctx context.Context, id string) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.GetVideo", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetVideo(ctx, id)
+}
+
+func (t *tracedService) ListVideos(ctx context.Context) (result []VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.ListVideos", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.ListVideos(ctx)
+}
+
+func (t *tracedService) SearchVideos(ctx context.Context, query *VideoQuery) (result []LocalizedVideo, err error) {
+	ctx, span := startSpan(ctx, "Service.SearchVideos", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.SearchVideos(ctx, query)
+}
+
+func (t *tracedService) GetLocalizedVideo(ctx context.Context, id string, languages []string) (result *LocalizedVideo, err error) {
+	ctx, span := startSpan(ctx, "Service.GetLocalizedVideo", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetLocalizedVideo(ctx, id, languages)
+}
+
+func (t *tracedService) UpdateLocalizedMetadata(ctx context.Context, id string, input *LocalizedMetadataInput) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.UpdateLocalizedMetadata", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.UpdateLocalizedMetadata(ctx, id, input)
+}
+
+func (t *tracedService) SaveCaptions(ctx context.Context, id, lang string, data []byte, format captions.Format, label string) (result *CaptionTrack, err error) {
+	ctx, span := startSpan(ctx, "Service.SaveCaptions", id, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveCaptions(ctx, id, lang, data, format, label)
+}
+
+func (t *tracedService) GetCaptions(ctx context.Context, id, lang string, format captions.Format) (result []byte, err error) {
+	ctx, span := startSpan(ctx, "Service.GetCaptions", id, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetCaptions(ctx, id, lang, format)
+}
+
+func (t *tracedService) ListCaptions(ctx context.Context, id string) (result []CaptionTrack, err error) {
+	ctx, span := startSpan(ctx, "Service.ListCaptions", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.ListCaptions(ctx, id)
+}
+
+func (t *tracedService) DeleteCaptions(ctx context.Context, id, lang string) (err error) {
+	ctx, span := startSpan(ctx, "Service.DeleteCaptions", id, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteCaptions(ctx, id, lang)
+}
+
+func (t *tracedService) BurnInCaptions(ctx context.Context, id, lang string) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.BurnInCaptions", id, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.BurnInCaptions(ctx, id, lang)
+}
+
+func (t *tracedService) DeleteVideo(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "Service.DeleteVideo", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteVideo(ctx, id)
+}
+
+func (t *tracedService) GenerateThumbnail(ctx context.Context, videoPath string) (err error) {
+	ctx, span := startSpan(ctx, "Service.GenerateThumbnail", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.GenerateThumbnail(ctx, videoPath)
+}
+
+func (t *tracedService) CreateCollection(ctx context.Context, input *CollectionInput) (result *Collection, err error) {
+	ctx, span := startSpan(ctx, "Service.CreateCollection", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.CreateCollection(ctx, input)
+}
+
+func (t *tracedService) GetCollection(ctx context.Context, id string) (result *CollectionDetail, err error) {
+	ctx, span := startSpan(ctx, "Service.GetCollection", "", attribute.String("gooji.collection_id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetCollection(ctx, id)
+}
+
+func (t *tracedService) ListCollections(ctx context.Context) (result []Collection, err error) {
+	ctx, span := startSpan(ctx, "Service.ListCollections", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.ListCollections(ctx)
+}
+
+func (t *tracedService) UpdateCollection(ctx context.Context, id string, input *CollectionInput) (result *Collection, err error) {
+	ctx, span := startSpan(ctx, "Service.UpdateCollection", "", attribute.String("gooji.collection_id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.UpdateCollection(ctx, id, input)
+}
+
+func (t *tracedService) DeleteCollection(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "Service.DeleteCollection", "", attribute.String("gooji.collection_id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteCollection(ctx, id)
+}
+
+func (t *tracedService) GetTranscript(ctx context.Context, id string) (result *Transcript, err error) {
+	ctx, span := startSpan(ctx, "Service.GetTranscript", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetTranscript(ctx, id)
+}
+
+func (t *tracedService) CreateSegment(ctx context.Context, id string, input *SegmentInput) (result *Segment, err error) {
+	ctx, span := startSpan(ctx, "Service.CreateSegment", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.CreateSegment(ctx, id, input)
+}
+
+func (t *tracedService) UpdateSegment(ctx context.Context, id, segmentID string, input *SegmentInput) (result *Segment, err error) {
+	ctx, span := startSpan(ctx, "Service.UpdateSegment", id, attribute.String("gooji.segment_id", segmentID))
+	defer func() { endSpan(span, err) }()
+	return t.next.UpdateSegment(ctx, id, segmentID, input)
+}
+
+func (t *tracedService) DeleteSegment(ctx context.Context, id, segmentID string, version int) (err error) {
+	ctx, span := startSpan(ctx, "Service.DeleteSegment", id, attribute.String("gooji.segment_id", segmentID))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteSegment(ctx, id, segmentID, version)
+}
+
+func (t *tracedService) ExportTranscript(ctx context.Context, id string, format TranscriptFormat) (result []byte, err error) {
+	ctx, span := startSpan(ctx, "Service.ExportTranscript", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.ExportTranscript(ctx, id, format)
+}
+
+func (t *tracedService) ExportConsent(ctx context.Context, id string) (result *SignedConsentRecord, err error) {
+	ctx, span := startSpan(ctx, "Service.ExportConsent", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.ExportConsent(ctx, id)
+}
+
+// VerifyConsent takes no context, so it has no parent to attach a span to
+func (t *tracedService) VerifyConsent(signed *SignedConsentRecord) bool {
+	return t.next.VerifyConsent(signed)
+}
+
+func (t *tracedService) UpdateAccess(ctx context.Context, id string, input *AccessInput) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.UpdateAccess", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.UpdateAccess(ctx, id, input)
+}
+
+func (t *tracedService) ReviewVideo(ctx context.Context, id string, input *ReviewInput) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.ReviewVideo", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.ReviewVideo(ctx, id, input)
+}
+
+func (t *tracedService) ListReviewQueue(ctx context.Context, state ReviewState) (result []VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.ListReviewQueue", "", attribute.String("gooji.review_state", string(state)))
+	defer func() { endSpan(span, err) }()
+	return t.next.ListReviewQueue(ctx, state)
+}
+
+func (t *tracedService) DeviceUploadStats(ctx context.Context) (result map[string]auth.DeviceStats, err error) {
+	ctx, span := startSpan(ctx, "Service.DeviceUploadStats", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.DeviceUploadStats(ctx)
+}
+
+func (t *tracedService) SignMediaURL(ctx context.Context, id string, input *SignedURLInput) (result *SignedURL, err error) {
+	ctx, span := startSpan(ctx, "Service.SignMediaURL", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.SignMediaURL(ctx, id, input)
+}
+
+func (t *tracedService) GetSignedVideo(ctx context.Context, id string, grant *MediaGrant) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Service.GetSignedVideo", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetSignedVideo(ctx, id, grant)
+}
+
+func (t *tracedService) StorageUsage(ctx context.Context) (result *StorageUsage, err error) {
+	ctx, span := startSpan(ctx, "Service.StorageUsage", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.StorageUsage(ctx)
+}
+
+func (t *tracedService) ApplyRetention(ctx context.Context, rules []config.RetentionRule, staleBefore time.Time, dryRun bool) (result *RetentionReport, err error) {
+	ctx, span := startSpan(ctx, "Service.ApplyRetention", "", attribute.Bool("gooji.dry_run", dryRun))
+	defer func() { endSpan(span, err) }()
+	return t.next.ApplyRetention(ctx, rules, staleBefore, dryRun)
+}
+
+// tracedRepository records a span around each Repository operation that takes a context
+type tracedRepository struct {
+	next Repository
+}
+
+// traceRepository wraps a repository so each of its operations records a span
+func traceRepository(next Repository) Repository {
+	return &tracedRepository{next: next}
+}
+
+func (t *tracedRepository) SaveVideo(ctx context.Context, file multipart.File, filename string) (result string, err error) {
+	ctx, span := startSpan(ctx, "Repository.SaveVideo", filename)
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveVideo(ctx, file, filename)
+}
+
+func (t *tracedRepository) SaveMetadata(ctx context.Context, metadata *VideoMetadata) (err error) {
+	ctx, span := startSpan(ctx, "Repository.SaveMetadata", metadata.ID)
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveMetadata(ctx, metadata)
+}
+
+func (t *tracedRepository) GetMetadata(ctx context.Context, id string) (result *VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Repository.GetMetadata", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetMetadata(ctx, id)
+}
+
+func (t *tracedRepository) ListMetadata(ctx context.Context) (result []VideoMetadata, err error) {
+	ctx, span := startSpan(ctx, "Repository.ListMetadata", "")
+	defer func() {
+		span.SetAttributes(attribute.Int("gooji.videos", len(result)))
+		endSpan(span, err)
+	}()
+	return t.next.ListMetadata(ctx)
+}
+
+func (t *tracedRepository) DeleteVideo(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "Repository.DeleteVideo", id)
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteVideo(ctx, id)
+}
+
+func (t *tracedRepository) VideoExists(ctx context.Context, id string) bool {
+	ctx, span := startSpan(ctx, "Repository.VideoExists", id)
+	defer span.End()
+	return t.next.VideoExists(ctx, id)
+}
+
+func (t *tracedRepository) GetThumbnailsDir() string {
+	return t.next.GetThumbnailsDir()
+}
+
+func (t *tracedRepository) VideoPath(filename string) string {
+	return t.next.VideoPath(filename)
+}
+
+func (t *tracedRepository) CaptionPath(videoID, lang string) string {
+	return t.next.CaptionPath(videoID, lang)
+}
+
+func (t *tracedRepository) SaveCaption(ctx context.Context, videoID, lang string, data []byte) (err error) {
+	ctx, span := startSpan(ctx, "Repository.SaveCaption", videoID, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveCaption(ctx, videoID, lang, data)
+}
+
+func (t *tracedRepository) GetCaption(ctx context.Context, videoID, lang string) (result []byte, err error) {
+	ctx, span := startSpan(ctx, "Repository.GetCaption", videoID, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetCaption(ctx, videoID, lang)
+}
+
+func (t *tracedRepository) DeleteCaption(ctx context.Context, videoID, lang string) (err error) {
+	ctx, span := startSpan(ctx, "Repository.DeleteCaption", videoID, attribute.String("gooji.lang", lang))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteCaption(ctx, videoID, lang)
+}
+
+func (t *tracedRepository) SaveCollection(ctx context.Context, collection *Collection) (err error) {
+	ctx, span := startSpan(ctx, "Repository.SaveCollection", "", attribute.String("gooji.collection_id", collection.ID))
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveCollection(ctx, collection)
+}
+
+func (t *tracedRepository) GetCollection(ctx context.Context, id string) (result *Collection, err error) {
+	ctx, span := startSpan(ctx, "Repository.GetCollection", "", attribute.String("gooji.collection_id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetCollection(ctx, id)
+}
+
+func (t *tracedRepository) ListCollections(ctx context.Context) (result []Collection, err error) {
+	ctx, span := startSpan(ctx, "Repository.ListCollections", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.ListCollections(ctx)
+}
+
+func (t *tracedRepository) DeleteCollection(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "Repository.DeleteCollection", "", attribute.String("gooji.collection_id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteCollection(ctx, id)
+}
+
+func (t *tracedRepository) SaveTranscript(ctx context.Context, transcript *Transcript) (err error) {
+	ctx, span := startSpan(ctx, "Repository.SaveTranscript", transcript.VideoID)
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveTranscript(ctx, transcript)
+}
+
+func (t *tracedRepository) GetTranscript(ctx context.Context, videoID string) (result *Transcript, err error) {
+	ctx, span := startSpan(ctx, "Repository.GetTranscript", videoID)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetTranscript(ctx, videoID)
+}
+
+func (t *tracedRepository) SaveConsent(ctx context.Context, signed *SignedConsentRecord) (err error) {
+	ctx, span := startSpan(ctx, "Repository.SaveConsent", signed.Record.VideoID)
+	defer func() { endSpan(span, err) }()
+	return t.next.SaveConsent(ctx, signed)
+}
+
+func (t *tracedRepository) GetConsent(ctx context.Context, videoID string) (result *SignedConsentRecord, err error) {
+	ctx, span := startSpan(ctx, "Repository.GetConsent", videoID)
+	defer func() { endSpan(span, err) }()
+	return t.next.GetConsent(ctx, videoID)
+}
+
+func (t *tracedRepository) CleanStaleFiles(ctx context.Context, cutoff time.Time, dryRun bool) (result []RemovedFile, err error) {
+	ctx, span := startSpan(ctx, "Repository.CleanStaleFiles", "", attribute.Bool("gooji.dry_run", dryRun))
+	defer func() { endSpan(span, err) }()
+	return t.next.CleanStaleFiles(ctx, cutoff, dryRun)
+}
+
+func (t *tracedRepository) Ping(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "Repository.Ping", "")
+	defer func() { endSpan(span, err) }()
+	return t.next.Ping(ctx)
+}
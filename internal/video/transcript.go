@@ -0,0 +1,241 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gooji/internal/auth"
+)
+
+// maxTranscriptSegments limits the number of segments in a single transcript
+const maxTranscriptSegments = 5000
+
+// SegmentTiers holds the interlinear annotation tiers of a segment
+type SegmentTiers struct {
+	Text        string `json:"text"`
+	Morphemes   string `json:"morphemes,omitempty"`
+	Gloss       string `json:"gloss,omitempty"`
+	Translation string `json:"translation,omitempty"`
+}
+
+// Segment is a time-aligned utterance with interlinear annotations.
+// Start and End are in seconds from the beginning of the video.
+type Segment struct {
+	ID        string       `json:"id"`
+	Start     float64      `json:"start"`
+	End       float64      `json:"end"`
+	Speaker   string       `json:"speaker,omitempty"`
+	Tiers     SegmentTiers `json:"tiers"`
+	Version   int          `json:"version"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// SegmentInput represents the editable fields of a segment.
+// Version must match the stored segment's version when updating.
+type SegmentInput struct {
+	Start   float64      `json:"start"`
+	End     float64      `json:"end"`
+	Speaker string       `json:"speaker"`
+	Tiers   SegmentTiers `json:"tiers"`
+	Version int          `json:"version"`
+}
+
+// Transcript is the ordered set of segments for a video.
+// Version increases with every change so clients can detect concurrent edits.
+type Transcript struct {
+	VideoID   string    `json:"video_id"`
+	Version   int       `json:"version"`
+	Segments  []Segment `json:"segments"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetTranscript retrieves the transcript for a video, returning an empty one if none exists
+func (s *service) GetTranscript(ctx context.Context, id string) (*Transcript, error) {
+	metadata, err := s.GetVideo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadTranscript(ctx, metadata.ID)
+}
+
+// CreateSegment adds a segment to a video's transcript
+func (s *service) CreateSegment(ctx context.Context, id string, input *SegmentInput) (*Segment, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	s.transcriptMu.Lock()
+	defer s.transcriptMu.Unlock()
+
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+	transcript, err := s.loadTranscript(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transcript.Segments) >= maxTranscriptSegments {
+		return nil, NewValidationError(fmt.Sprintf("a transcript may contain at most %d segments", maxTranscriptSegments), nil)
+	}
+
+	segment := Segment{ID: fmt.Sprintf("seg_%s", generateUUID())}
+	if err := s.applySegmentInput(&segment, input, metadata.Duration); err != nil {
+		return nil, err
+	}
+	segment.Version = 1
+
+	transcript.Segments = append(transcript.Segments, segment)
+	if err := s.saveTranscript(ctx, transcript); err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, AuditSegmentCreate, id, nil, segment)
+
+	return &segment, nil
+}
+
+// UpdateSegment replaces a segment's fields if the input version matches the stored version
+func (s *service) UpdateSegment(ctx context.Context, id, segmentID string, input *SegmentInput) (*Segment, error) {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return nil, err
+	}
+	s.transcriptMu.Lock()
+	defer s.transcriptMu.Unlock()
+
+	metadata, err := s.repo.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, NewNotFoundError("video not found", err)
+	}
+	transcript, err := s.loadTranscript(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	index := transcript.segmentIndex(segmentID)
+	if index < 0 {
+		return nil, NewNotFoundError("segment not found", nil)
+	}
+	segment := transcript.Segments[index]
+	if input == nil || input.Version != segment.Version {
+		return nil, NewConflictError(fmt.Sprintf("segment was modified by someone else (current version %d)", segment.Version), nil)
+	}
+
+	before := snapshot(segment)
+	if err := s.applySegmentInput(&segment, input, metadata.Duration); err != nil {
+		return nil, err
+	}
+	segment.Version++
+
+	transcript.Segments[index] = segment
+	if err := s.saveTranscript(ctx, transcript); err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, AuditSegmentUpdate, id, before, segment)
+
+	return &segment, nil
+}
+
+// DeleteSegment removes a segment if version matches the stored version
+func (s *service) DeleteSegment(ctx context.Context, id, segmentID string, version int) error {
+	if err := requirePermission(ctx, auth.PermEditVideos); err != nil {
+		return err
+	}
+	s.transcriptMu.Lock()
+	defer s.transcriptMu.Unlock()
+
+	if _, err := s.repo.GetMetadata(ctx, id); err != nil {
+		return NewNotFoundError("video not found", err)
+	}
+	transcript, err := s.loadTranscript(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	index := transcript.segmentIndex(segmentID)
+	if index < 0 {
+		return NewNotFoundError("segment not found", nil)
+	}
+	if current := transcript.Segments[index].Version; version != current {
+		return NewConflictError(fmt.Sprintf("segment was modified by someone else (current version %d)", current), nil)
+	}
+
+	before := transcript.Segments[index]
+	transcript.Segments = append(transcript.Segments[:index], transcript.Segments[index+1:]...)
+	if err := s.saveTranscript(ctx, transcript); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, AuditSegmentDelete, id, before, nil)
+	return nil
+}
+
+// loadTranscript reads a transcript from the repository
+func (s *service) loadTranscript(ctx context.Context, id string) (*Transcript, error) {
+	transcript, err := s.repo.GetTranscript(ctx, id)
+	if err != nil {
+		return nil, NewInternalError("failed to load transcript", err)
+	}
+	if transcript == nil {
+		transcript = &Transcript{VideoID: id, Segments: []Segment{}}
+	}
+	return transcript, nil
+}
+
+// saveTranscript sorts segments, bumps the transcript version and persists it
+func (s *service) saveTranscript(ctx context.Context, transcript *Transcript) error {
+	sort.SliceStable(transcript.Segments, func(i, j int) bool {
+		return transcript.Segments[i].Start < transcript.Segments[j].Start
+	})
+	transcript.Version++
+	transcript.UpdatedAt = time.Now()
+
+	if err := s.repo.SaveTranscript(ctx, transcript); err != nil {
+		return NewInternalError("failed to save transcript", err)
+	}
+
+	s.log(ctx, "save_transcript", transcript.VideoID).Debug("Saved transcript for %s (version %d)", transcript.VideoID, transcript.Version)
+	return nil
+}
+
+// applySegmentInput validates input and copies it onto a segment
+func (s *service) applySegmentInput(segment *Segment, input *SegmentInput, duration float64) error {
+	if input == nil {
+		return NewValidationError("segment is required", nil)
+	}
+	if input.Start < 0 || input.End <= input.Start {
+		return NewValidationError("segment must have 0 <= start < end", nil)
+	}
+	if duration > 0 && input.End > duration+0.5 {
+		return NewValidationError(fmt.Sprintf("segment ends after the video (%.2fs)", duration), nil)
+	}
+
+	tiers := SegmentTiers{
+		Text:        s.sanitizer.Description(input.Tiers.Text),
+		Morphemes:   s.sanitizer.Description(input.Tiers.Morphemes),
+		Gloss:       s.sanitizer.Description(input.Tiers.Gloss),
+		Translation: s.sanitizer.Description(input.Tiers.Translation),
+	}
+	if tiers.Text == "" && tiers.Translation == "" {
+		return NewValidationError("segment text or translation is required", nil)
+	}
+
+	segment.Start = input.Start
+	segment.End = input.End
+	segment.Speaker = s.sanitizer.Title(input.Speaker)
+	segment.Tiers = tiers
+	segment.UpdatedAt = time.Now()
+	return nil
+}
+
+// segmentIndex returns the position of a segment, or -1 if it does not exist
+func (t *Transcript) segmentIndex(segmentID string) int {
+	for i := range t.Segments {
+		if t.Segments[i].ID == segmentID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,348 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gooji/pkg/captions"
+)
+
+// TranscriptFormat identifies a transcript export format
+type TranscriptFormat string
+
+const (
+	// TranscriptFormatWebVTT exports segments as WebVTT cues with speaker voice tags
+	TranscriptFormatWebVTT TranscriptFormat = "vtt"
+	// TranscriptFormatELAN exports an ELAN annotation document (.eaf)
+	TranscriptFormatELAN TranscriptFormat = "eaf"
+	// TranscriptFormatText exports a plain-text interlinear transcript
+	TranscriptFormatText TranscriptFormat = "txt"
+)
+
+// ParseTranscriptFormat converts a format name or file extension into a TranscriptFormat
+func ParseTranscriptFormat(name string) (TranscriptFormat, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "vtt", "webvtt":
+		return TranscriptFormatWebVTT, nil
+	case "eaf", "elan":
+		return TranscriptFormatELAN, nil
+	case "txt", "text":
+		return TranscriptFormatText, nil
+	default:
+		return "", fmt.Errorf("unsupported transcript format: %s", name)
+	}
+}
+
+// ContentType returns the MIME type for a transcript format
+func (f TranscriptFormat) ContentType() string {
+	switch f {
+	case TranscriptFormatELAN:
+		return "application/xml; charset=utf-8"
+	case TranscriptFormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return captions.FormatWebVTT.ContentType()
+	}
+}
+
+// ExportTranscript renders a video's transcript in the requested format
+func (s *service) ExportTranscript(ctx context.Context, id string, format TranscriptFormat) ([]byte, error) {
+	metadata, err := s.GetVideo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	transcript, err := s.loadTranscript(ctx, metadata.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case TranscriptFormatWebVTT:
+		vtt, err := segmentsToTrack(transcript.Segments).Encode(captions.FormatWebVTT)
+		if err != nil {
+			return nil, NewInternalError("failed to encode transcript", err)
+		}
+		return vtt, nil
+	case TranscriptFormatELAN:
+		return encodeELAN(metadata, transcript)
+	case TranscriptFormatText:
+		return encodeInterlinearText(metadata, transcript), nil
+	default:
+		return nil, NewValidationError("unsupported transcript format", nil)
+	}
+}
+
+// segmentsToTrack converts segments into WebVTT cues: the text tier with a voice tag,
+// followed by the free translation on its own line
+func segmentsToTrack(segments []Segment) *captions.Track {
+	track := &captions.Track{Cues: make([]captions.Cue, 0, len(segments))}
+	for _, segment := range segments {
+		var lines []string
+		if text := vttText(segment.Tiers.Text); text != "" {
+			if segment.Speaker != "" {
+				text = "<v " + vttText(segment.Speaker) + ">" + text
+			}
+			lines = append(lines, text)
+		}
+		if translation := vttText(segment.Tiers.Translation); translation != "" {
+			lines = append(lines, translation)
+		}
+
+		track.Cues = append(track.Cues, captions.Cue{
+			ID:    segment.ID,
+			Start: secondsToDuration(segment.Start),
+			End:   secondsToDuration(segment.End),
+			Text:  strings.Join(lines, "\n"),
+		})
+	}
+	return track
+}
+
+// vttText escapes cue text and removes blank lines, which would end a cue early
+func vttText(text string) string {
+	text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// encodeInterlinearText writes one block per segment with its tiers on aligned lines
+func encodeInterlinearText(metadata *VideoMetadata, transcript *Transcript) []byte {
+	var b bytes.Buffer
+	if metadata.Title != "" {
+		b.WriteString(metadata.Title + "\n")
+	} else {
+		b.WriteString(metadata.ID + "\n")
+	}
+
+	for _, segment := range transcript.Segments {
+		fmt.Fprintf(&b, "\n[%s - %s]", formatClock(segment.Start), formatClock(segment.End))
+		if segment.Speaker != "" {
+			b.WriteString(" " + segment.Speaker)
+		}
+		b.WriteString("\n")
+
+		tiers := []struct{ label, value string }{
+			{"text", segment.Tiers.Text},
+			{"morph", segment.Tiers.Morphemes},
+			{"gloss", segment.Tiers.Gloss},
+			{"trans", segment.Tiers.Translation},
+		}
+		for _, tier := range tiers {
+			if tier.value == "" {
+				continue
+			}
+			value := strings.ReplaceAll(tier.value, "\n", "\n        ")
+			fmt.Fprintf(&b, "  %-5s %s\n", tier.label+":", value)
+		}
+	}
+	return b.Bytes()
+}
+
+// formatClock formats seconds as hh:mm:ss.mmm
+func formatClock(seconds float64) string {
+	d := secondsToDuration(seconds)
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	secs := d / time.Second
+	d -= secs * time.Second
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, secs, d/time.Millisecond)
+}
+
+// secondsToDuration converts seconds to a duration rounded to the millisecond
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds*1000)) * time.Millisecond
+}
+
+// ELAN annotation document structures (EAF 3.0)
+type eafDocument struct {
+	XMLName        xml.Name            `xml:"ANNOTATION_DOCUMENT"`
+	Author         string              `xml:"AUTHOR,attr"`
+	Date           string              `xml:"DATE,attr"`
+	Format         string              `xml:"FORMAT,attr"`
+	Version        string              `xml:"VERSION,attr"`
+	XSI            string              `xml:"xmlns:xsi,attr"`
+	Schema         string              `xml:"xsi:noNamespaceSchemaLocation,attr"`
+	Header         eafHeader           `xml:"HEADER"`
+	TimeSlots      []eafTimeSlot       `xml:"TIME_ORDER>TIME_SLOT"`
+	Tiers          []eafTier           `xml:"TIER"`
+	LinguisticType []eafLinguisticType `xml:"LINGUISTIC_TYPE"`
+	Constraints    []eafConstraint     `xml:"CONSTRAINT"`
+}
+
+type eafHeader struct {
+	MediaFile  string             `xml:"MEDIA_FILE,attr"`
+	TimeUnits  string             `xml:"TIME_UNITS,attr"`
+	Descriptor eafMediaDescriptor `xml:"MEDIA_DESCRIPTOR"`
+}
+
+type eafMediaDescriptor struct {
+	MediaURL         string `xml:"MEDIA_URL,attr"`
+	MimeType         string `xml:"MIME_TYPE,attr"`
+	RelativeMediaURL string `xml:"RELATIVE_MEDIA_URL,attr"`
+}
+
+type eafTimeSlot struct {
+	ID    string `xml:"TIME_SLOT_ID,attr"`
+	Value int64  `xml:"TIME_VALUE,attr"`
+}
+
+type eafTier struct {
+	ID          string          `xml:"TIER_ID,attr"`
+	Type        string          `xml:"LINGUISTIC_TYPE_REF,attr"`
+	Participant string          `xml:"PARTICIPANT,attr,omitempty"`
+	Parent      string          `xml:"PARENT_REF,attr,omitempty"`
+	Annotations []eafAnnotation `xml:"ANNOTATION"`
+}
+
+type eafAnnotation struct {
+	Alignable *eafAlignable `xml:"ALIGNABLE_ANNOTATION,omitempty"`
+	Ref       *eafRef       `xml:"REF_ANNOTATION,omitempty"`
+}
+
+type eafAlignable struct {
+	ID    string `xml:"ANNOTATION_ID,attr"`
+	Slot1 string `xml:"TIME_SLOT_REF1,attr"`
+	Slot2 string `xml:"TIME_SLOT_REF2,attr"`
+	Value string `xml:"ANNOTATION_VALUE"`
+}
+
+type eafRef struct {
+	ID     string `xml:"ANNOTATION_ID,attr"`
+	Parent string `xml:"ANNOTATION_REF,attr"`
+	Value  string `xml:"ANNOTATION_VALUE"`
+}
+
+type eafLinguisticType struct {
+	ID            string `xml:"LINGUISTIC_TYPE_ID,attr"`
+	TimeAlignable bool   `xml:"TIME_ALIGNABLE,attr"`
+	Constraints   string `xml:"CONSTRAINTS,attr,omitempty"`
+}
+
+type eafConstraint struct {
+	Stereotype  string `xml:"STEREOTYPE,attr"`
+	Description string `xml:"DESCRIPTION,attr"`
+}
+
+// eafSpeakerTiers holds the tier set for one speaker
+type eafSpeakerTiers struct {
+	text, morphemes, gloss, translation *eafTier
+}
+
+// encodeELAN writes the transcript as an ELAN document with one tier set per speaker.
+// The text tier is time-aligned; morpheme, gloss and translation tiers are symbolic children.
+func encodeELAN(metadata *VideoMetadata, transcript *Transcript) ([]byte, error) {
+	doc := eafDocument{
+		Date:    transcript.UpdatedAt.UTC().Format(time.RFC3339),
+		Format:  "3.0",
+		Version: "3.0",
+		XSI:     "http://www.w3.org/2001/XMLSchema-instance",
+		Schema:  "http://www.mpi.nl/tools/elan/EAFv3.0.xsd",
+		Header: eafHeader{
+			TimeUnits: "milliseconds",
+			Descriptor: eafMediaDescriptor{
+				MediaURL:         "file:///" + metadata.Filename,
+				MimeType:         "video/" + strings.TrimPrefix(strings.ToLower(filepath.Ext(metadata.Filename)), "."),
+				RelativeMediaURL: "./" + metadata.Filename,
+			},
+		},
+		LinguisticType: []eafLinguisticType{
+			{ID: "utterance", TimeAlignable: true},
+			{ID: "morphemes", Constraints: "Symbolic_Association"},
+			{ID: "gloss", Constraints: "Symbolic_Association"},
+			{ID: "translation", Constraints: "Symbolic_Association"},
+		},
+		Constraints: []eafConstraint{{
+			Stereotype:  "Symbolic_Association",
+			Description: "1-1 association with a parent annotation",
+		}},
+	}
+
+	speakers := make(map[string]*eafSpeakerTiers)
+	var order []string
+	annotationID := 0
+	nextID := func() string {
+		annotationID++
+		return fmt.Sprintf("a%d", annotationID)
+	}
+
+	for i, segment := range transcript.Segments {
+		tiers, ok := speakers[segment.Speaker]
+		if !ok {
+			tiers = newEAFSpeakerTiers(segment.Speaker)
+			speakers[segment.Speaker] = tiers
+			order = append(order, segment.Speaker)
+		}
+
+		slot1 := fmt.Sprintf("ts%d", 2*i+1)
+		slot2 := fmt.Sprintf("ts%d", 2*i+2)
+		doc.TimeSlots = append(doc.TimeSlots,
+			eafTimeSlot{ID: slot1, Value: secondsToDuration(segment.Start).Milliseconds()},
+			eafTimeSlot{ID: slot2, Value: secondsToDuration(segment.End).Milliseconds()},
+		)
+
+		parentID := nextID()
+		tiers.text.Annotations = append(tiers.text.Annotations, eafAnnotation{
+			Alignable: &eafAlignable{ID: parentID, Slot1: slot1, Slot2: slot2, Value: segment.Tiers.Text},
+		})
+		children := []struct {
+			tier  *eafTier
+			value string
+		}{
+			{tiers.morphemes, segment.Tiers.Morphemes},
+			{tiers.gloss, segment.Tiers.Gloss},
+			{tiers.translation, segment.Tiers.Translation},
+		}
+		for _, child := range children {
+			if child.value == "" {
+				continue
+			}
+			child.tier.Annotations = append(child.tier.Annotations, eafAnnotation{
+				Ref: &eafRef{ID: nextID(), Parent: parentID, Value: child.value},
+			})
+		}
+	}
+
+	for _, speaker := range order {
+		tiers := speakers[speaker]
+		doc.Tiers = append(doc.Tiers, *tiers.text, *tiers.morphemes, *tiers.gloss, *tiers.translation)
+	}
+	if len(doc.Tiers) == 0 {
+		empty := newEAFSpeakerTiers("")
+		doc.Tiers = append(doc.Tiers, *empty.text, *empty.morphemes, *empty.gloss, *empty.translation)
+	}
+
+	output, err := xml.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return nil, NewInternalError("failed to encode ELAN document", err)
+	}
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+// newEAFSpeakerTiers creates the tier set for a speaker; tier IDs are suffixed with the speaker name
+func newEAFSpeakerTiers(speaker string) *eafSpeakerTiers {
+	suffix := ""
+	if speaker != "" {
+		suffix = "@" + speaker
+	}
+	text := "Text" + suffix
+	return &eafSpeakerTiers{
+		text:        &eafTier{ID: text, Type: "utterance", Participant: speaker},
+		morphemes:   &eafTier{ID: "Morphemes" + suffix, Type: "morphemes", Participant: speaker, Parent: text},
+		gloss:       &eafTier{ID: "Gloss" + suffix, Type: "gloss", Participant: speaker, Parent: text},
+		translation: &eafTier{ID: "Translation" + suffix, Type: "translation", Participant: speaker, Parent: text},
+	}
+}
@@ -0,0 +1,204 @@
+package video
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gooji/internal/middleware"
+)
+
+// maxSegmentBodySize limits the size of segment request bodies
+const maxSegmentBodySize = 256 << 10
+
+// HandleTranscript handles /api/videos/{id}/transcript[/segments[/{segmentID}]]
+func (h *Handler) HandleTranscript(w http.ResponseWriter, r *http.Request) {
+	id, resource := videoPathParts(r.URL.Path)
+	if id == "" {
+		h.handleValidationError(w, r, "Missing video ID", nil)
+		return
+	}
+
+	parts := strings.Split(resource, "/")
+	switch {
+	case len(parts) == 1:
+		if r.Method != http.MethodGet {
+			h.handleMethodNotAllowed(w, r)
+			return
+		}
+		h.GetTranscript(w, r, id)
+	case len(parts) == 2 && parts[1] == "segments":
+		if r.Method != http.MethodPost {
+			h.handleMethodNotAllowed(w, r)
+			return
+		}
+		h.CreateSegment(w, r, id)
+	case len(parts) == 3 && parts[1] == "segments" && parts[2] != "":
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdateSegment(w, r, id, parts[2])
+		case http.MethodDelete:
+			h.DeleteSegment(w, r, id, parts[2])
+		default:
+			h.handleMethodNotAllowed(w, r)
+		}
+	default:
+		h.handleNotFoundError(w, r, "Unknown transcript resource", nil)
+	}
+}
+
+// GetTranscript returns the transcript as JSON, or exported via ?format=vtt|eaf|txt
+func (h *Handler) GetTranscript(w http.ResponseWriter, r *http.Request, id string) {
+	name := r.URL.Query().Get("format")
+	if name == "" || name == "json" {
+		transcript, err := h.service.GetTranscript(r.Context(), id)
+		if err != nil {
+			h.handleServiceError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", versionETag(transcript.Version))
+		h.writeJSONResponse(w, transcript)
+		return
+	}
+
+	format, err := ParseTranscriptFormat(name)
+	if err != nil {
+		h.handleValidationError(w, r, "Unsupported transcript format", err)
+		return
+	}
+	data, err := h.service.ExportTranscript(r.Context(), id, format)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if r.URL.Query().Get("download") != "" {
+		filename := strings.TrimSuffix(id, filepath.Ext(id)) + ".transcript." + string(format)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	if _, err := w.Write(data); err != nil {
+		h.log(r).Error("Failed to write transcript response: %v", err)
+	}
+}
+
+// CreateSegment adds a segment from a JSON body
+func (h *Handler) CreateSegment(w http.ResponseWriter, r *http.Request, id string) {
+	input, ok := h.decodeSegmentInput(w, r)
+	if !ok {
+		return
+	}
+
+	segment, err := h.service.CreateSegment(r.Context(), id, input)
+	if err != nil {
+		h.handleTranscriptError(w, r, id, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(segment.Version))
+	h.writeJSONStatus(w, http.StatusCreated, segment)
+}
+
+// UpdateSegment replaces a segment from a JSON body.
+// The expected version comes from an If-Match header or the body's version field.
+func (h *Handler) UpdateSegment(w http.ResponseWriter, r *http.Request, id, segmentID string) {
+	input, ok := h.decodeSegmentInput(w, r)
+	if !ok {
+		return
+	}
+	if version, found := ifMatchVersion(r); found {
+		input.Version = version
+	}
+
+	segment, err := h.service.UpdateSegment(r.Context(), id, segmentID, input)
+	if err != nil {
+		h.handleTranscriptError(w, r, id, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(segment.Version))
+	h.writeJSONResponse(w, segment)
+}
+
+// DeleteSegment removes a segment; the expected version comes from If-Match or ?version=
+func (h *Handler) DeleteSegment(w http.ResponseWriter, r *http.Request, id, segmentID string) {
+	version, found := ifMatchVersion(r)
+	if !found {
+		parsed, err := strconv.Atoi(r.URL.Query().Get("version"))
+		if err != nil {
+			h.handleValidationError(w, r, "Segment version is required", err)
+			return
+		}
+		version = parsed
+	}
+
+	if err := h.service.DeleteSegment(r.Context(), id, segmentID, version); err != nil {
+		h.handleTranscriptError(w, r, id, err)
+		return
+	}
+
+	response := map[string]string{
+		"message": "Segment deleted successfully",
+		"id":      segmentID,
+	}
+	h.writeJSONResponse(w, response)
+}
+
+// decodeSegmentInput decodes a segment JSON body, writing an error response on failure
+func (h *Handler) decodeSegmentInput(w http.ResponseWriter, r *http.Request) (*SegmentInput, bool) {
+	if !middleware.RequireJSON(w, r) {
+		return nil, false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSegmentBodySize)
+	var input SegmentInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		h.handleValidationError(w, r, "Invalid segment", err)
+		return nil, false
+	}
+	return &input, true
+}
+
+// handleTranscriptError responds to conflicts with the current transcript so the
+// client can merge its edit; other errors are handled as service errors
+func (h *Handler) handleTranscriptError(w http.ResponseWriter, r *http.Request, id string, err error) {
+	if !IsConflictError(err) {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.log(r).Info("Transcript edit conflict: %v (method: %s, path: %s)", err, r.Method, r.URL.Path)
+	transcript, loadErr := h.service.GetTranscript(r.Context(), id)
+	if loadErr != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+	response := map[string]interface{}{
+		"error":      err.Error(),
+		"transcript": transcript,
+	}
+	h.writeJSONStatus(w, http.StatusConflict, response)
+}
+
+// ifMatchVersion parses a version from an If-Match header such as "3" or W/"3"
+func ifMatchVersion(r *http.Request) (int, bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		return 0, false
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// versionETag formats a version number as an ETag
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
@@ -0,0 +1,48 @@
+package video
+
+import (
+	"fmt"
+	"mime/multipart"
+	"slices"
+	"sync/atomic"
+)
+
+// uploadLimits are one generation of the upload settings
+type uploadLimits struct {
+	maxSize      int64
+	allowedTypes []string
+}
+
+// UploadRules holds the size and MIME type limits on uploads, which can
+// change while running without disturbing uploads already being checked
+type UploadRules struct {
+	current atomic.Pointer[uploadLimits]
+}
+
+// NewUploadRules creates upload rules allowing files up to maxSize bytes of the allowed MIME types
+func NewUploadRules(maxSize int64, allowedTypes []string) *UploadRules {
+	rules := &UploadRules{}
+	rules.Set(maxSize, allowedTypes)
+	return rules
+}
+
+// Set replaces the limits
+func (u *UploadRules) Set(maxSize int64, allowedTypes []string) {
+	u.current.Store(&uploadLimits{
+		maxSize:      maxSize,
+		allowedTypes: slices.Clone(allowedTypes),
+	})
+}
+
+// check rejects files over the size limit or of a type not allowed
+func (u *UploadRules) check(header *multipart.FileHeader) error {
+	limits := u.current.Load()
+	if header.Size > limits.maxSize {
+		return NewValidationError(fmt.Sprintf("file size %d exceeds maximum allowed size %d", header.Size, limits.maxSize), nil)
+	}
+	contentType := header.Header.Get("Content-Type")
+	if !slices.Contains(limits.allowedTypes, contentType) {
+		return NewValidationError(fmt.Sprintf("content type %s is not allowed", contentType), nil)
+	}
+	return nil
+}
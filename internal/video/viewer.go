@@ -0,0 +1,215 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"gooji/internal/auth"
+	"gooji/internal/config"
+	"gooji/internal/logger"
+	"gooji/internal/middleware"
+)
+
+// viewerContextKey is the context key for the current Viewer
+type viewerContextKey struct{}
+
+// Viewer describes who is reading videos, used to enforce consent scopes and cultural protocol
+type Viewer struct {
+	// Kiosk is set for requests from the on-site recording kiosk
+	Kiosk bool `json:"kiosk"`
+	// Member is set for identified community members
+	Member bool `json:"member"`
+	// Researcher is set for approved researchers, who may use research-scoped
+	// recordings but are not community members
+	Researcher bool `json:"researcher"`
+	// Roles lists the account's community roles, used for restricted teachings
+	Roles []string `json:"roles,omitempty"`
+	// Role is the viewer's permission level for API operations
+	Role auth.Role `json:"role"`
+	// Name identifies the viewer in review history, if known
+	Name string `json:"name,omitempty"`
+	// UserID is the signed-in account, empty for anonymous viewers
+	UserID string `json:"user_id,omitempty"`
+	// TokenID is the API token used, if any; its scopes narrow Role
+	TokenID string `json:"token_id,omitempty"`
+	// Device is the paired kiosk the request came from, if any
+	Device *auth.Device `json:"-"`
+
+	token *auth.Token
+}
+
+// WithViewer returns a context carrying the viewer
+func WithViewer(ctx context.Context, viewer *Viewer) context.Context {
+	return context.WithValue(ctx, viewerContextKey{}, viewer)
+}
+
+// ViewerFromContext returns the viewer for a request; requests without one are anonymous public viewers
+func ViewerFromContext(ctx context.Context) *Viewer {
+	if viewer, ok := ctx.Value(viewerContextKey{}).(*Viewer); ok && viewer != nil {
+		return viewer
+	}
+	return &Viewer{Role: auth.RoleViewer}
+}
+
+// Can reports whether the viewer's role, and token scopes if any, grant a permission
+func (v *Viewer) Can(permission auth.Permission) bool {
+	if v.token != nil && !v.token.Allows(permission) {
+		return false
+	}
+	return v.Role.Can(permission)
+}
+
+// logFields identifies the viewer in log entries
+func (v *Viewer) logFields() []interface{} {
+	var fields []interface{}
+	if v.UserID != "" {
+		fields = append(fields, logger.FieldUser, v.UserID)
+	}
+	if v.TokenID != "" {
+		fields = append(fields, logger.FieldToken, v.TokenID)
+	}
+	if v.Device != nil {
+		fields = append(fields, logger.FieldDevice, v.Device.ID)
+	}
+	return fields
+}
+
+// ViewerMiddleware attaches a Viewer to each request. Anonymous requests are
+// viewers, the kiosk networks and paired kiosk devices may record, and the
+// moderator networks act as moderators.
+// Signed-in users are community members with the stronger of their account role
+// and their network's role, and hold their account's community roles; approved
+// researchers are not members. API tokens act with their owner's role, or their
+// scopes' role for service tokens, narrowed by scope. It must run after the
+// auth.Sessions, auth.Devices and auth.Bearer middleware.
+func ViewerMiddleware(kiosk *config.Kiosk) (middleware.Middleware, error) {
+	kioskNetworks, err := parseNetworks(kiosk.Networks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kiosk networks: %w", err)
+	}
+	moderatorNetworks, err := parseNetworks(kiosk.ModeratorNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid moderator networks: %w", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			viewer := &Viewer{
+				Kiosk: remoteIPIn(r, kioskNetworks),
+				Role:  auth.RoleViewer,
+			}
+			user := auth.UserFromContext(r.Context())
+			if token := auth.TokenFromContext(r.Context()); token != nil {
+				// Token requests act only with the token's own authority, never the network's
+				viewer.Member = true
+				viewer.Name = token.Name
+				viewer.TokenID = token.ID
+				viewer.token = token
+				viewer.Role = token.ServiceRole()
+				if user != nil {
+					viewer.Role = user.Role
+				}
+			} else {
+				if device := auth.DeviceFromContext(r.Context()); device != nil {
+					viewer.Kiosk = true
+					viewer.Device = device
+				}
+				if viewer.Kiosk {
+					viewer.Role = auth.RoleRecorder
+				}
+				if remoteIPIn(r, moderatorNetworks) {
+					viewer.Role = viewer.Role.Max(auth.RoleModerator)
+				}
+				if user != nil {
+					viewer.Role = viewer.Role.Max(user.Role)
+				}
+			}
+			if user != nil {
+				viewer.Member = !user.Researcher
+				viewer.Researcher = user.Researcher
+				viewer.Name = user.Name()
+				viewer.UserID = user.ID
+				viewer.Roles = user.CommunityRoles
+			}
+			ctx := logger.WithFields(r.Context(), viewer.logFields()...)
+			next.ServeHTTP(w, r.WithContext(WithViewer(ctx, viewer)))
+		})
+	}, nil
+}
+
+// parseNetworks parses a list of CIDR ranges
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// remoteIPIn reports whether the request's remote address is inside one of the networks
+func remoteIPIn(r *http.Request, networks []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeView returns a security error if the viewer in ctx may not see the video.
+// This is the single place consent, cultural protocol and moderation are evaluated for reads.
+// Moderators and admins see restricted teachings without holding one of their roles,
+// as they must to review them; consent, seasons and expiry still apply to them.
+func (s *service) authorizeView(ctx context.Context, metadata *VideoMetadata) error {
+	viewer := ViewerFromContext(ctx)
+	if !viewer.Can(auth.PermViewVideos) {
+		return NewSecurityError("viewing videos is not permitted", nil)
+	}
+	now := time.Now()
+	if err := metadata.Consent.permits(viewer, now); err != nil {
+		return NewSecurityError(err.Error(), nil)
+	}
+	if err := metadata.Access.permits(viewer, now); err != nil {
+		return NewSecurityError(err.Error(), nil)
+	}
+	if err := metadata.Review.permits(viewer); err != nil {
+		return NewSecurityError(err.Error(), nil)
+	}
+	return nil
+}
+
+// requirePermission returns a security error unless the viewer in ctx holds the permission.
+// Route policies check the same permissions; this keeps the service safe on its own.
+func requirePermission(ctx context.Context, permission auth.Permission) error {
+	viewer := ViewerFromContext(ctx)
+	if !viewer.Can(permission) {
+		return NewSecurityError(fmt.Sprintf("role %q may not perform %s", viewer.Role, permission), nil)
+	}
+	return nil
+}
+
+// filterViewable returns the videos the viewer in ctx may see
+func (s *service) filterViewable(ctx context.Context, videos []VideoMetadata) []VideoMetadata {
+	visible := make([]VideoMetadata, 0, len(videos))
+	for i := range videos {
+		if s.authorizeView(ctx, &videos[i]) == nil {
+			visible = append(visible, videos[i])
+		}
+	}
+	return visible
+}
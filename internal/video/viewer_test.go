@@ -0,0 +1,97 @@
+package video
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gooji/internal/auth"
+	"gooji/internal/config"
+)
+
+// viewerFor runs a request through ViewerMiddleware as the user, or anonymously for nil
+func viewerFor(t *testing.T, user *auth.User) *Viewer {
+	t.Helper()
+	mw, err := ViewerMiddleware(&config.Kiosk{})
+	if err != nil {
+		t.Fatalf("ViewerMiddleware: %v", err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/api/videos/talk.mp4", nil)
+	r.RemoteAddr = "192.0.2.10:5000"
+	if user != nil {
+		r = r.WithContext(auth.WithUser(r.Context(), user, &auth.Session{}))
+	}
+
+	var viewer *Viewer
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer = ViewerFromContext(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	return viewer
+}
+
+func TestAuthorizeViewRestricted(t *testing.T) {
+	metadata := &VideoMetadata{
+		ID:      "talk.mp4",
+		Consent: &Consent{Scope: ConsentScopeCommunity},
+		Access:  &Access{Level: AccessRestricted, Roles: []string{"midewiwin"}},
+		Review:  &Review{State: ReviewApproved},
+	}
+
+	tests := []struct {
+		name  string
+		user  *auth.User
+		allow bool
+	}{
+		{name: "role holder", user: &auth.User{ID: "usr_1", Role: auth.RoleViewer, CommunityRoles: []string{"midewiwin"}}, allow: true},
+		{name: "holder of one of several roles", user: &auth.User{ID: "usr_2", Role: auth.RoleViewer, CommunityRoles: []string{"elder", "midewiwin"}}, allow: true},
+		{name: "member without the role", user: &auth.User{ID: "usr_3", Role: auth.RoleEditor, CommunityRoles: []string{"elder"}}, allow: false},
+		{name: "member without community roles", user: &auth.User{ID: "usr_4", Role: auth.RoleViewer}, allow: false},
+		{name: "anonymous", user: nil, allow: false},
+		{name: "moderator reviewing", user: &auth.User{ID: "usr_5", Role: auth.RoleModerator}, allow: true},
+		{name: "admin", user: &auth.User{ID: "usr_6", Role: auth.RoleAdmin}, allow: true},
+	}
+
+	s := &service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer := viewerFor(t, tt.user)
+			err := s.authorizeView(WithViewer(t.Context(), viewer), metadata)
+			if (err == nil) != tt.allow {
+				t.Errorf("authorizeView = %v, want allowed %v", err, tt.allow)
+			}
+		})
+	}
+}
+
+func TestAuthorizeViewResearch(t *testing.T) {
+	researcher := &auth.User{ID: "usr_1", Role: auth.RoleViewer, Researcher: true}
+	member := &auth.User{ID: "usr_2", Role: auth.RoleViewer}
+
+	tests := []struct {
+		name  string
+		user  *auth.User
+		scope ConsentScope
+		allow bool
+	}{
+		{name: "researcher sees research scope", user: researcher, scope: ConsentScopeResearch, allow: true},
+		{name: "researcher sees public scope", user: researcher, scope: ConsentScopePublic, allow: true},
+		{name: "researcher does not see community scope", user: researcher, scope: ConsentScopeCommunity, allow: false},
+		{name: "member sees research scope", user: member, scope: ConsentScopeResearch, allow: true},
+		{name: "anonymous does not see research scope", user: nil, scope: ConsentScopeResearch, allow: false},
+	}
+
+	s := &service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &VideoMetadata{
+				ID:      "talk.mp4",
+				Consent: &Consent{Scope: tt.scope},
+				Review:  &Review{State: ReviewApproved},
+			}
+			err := s.authorizeView(WithViewer(t.Context(), viewerFor(t, tt.user)), metadata)
+			if (err == nil) != tt.allow {
+				t.Errorf("authorizeView = %v, want allowed %v", err, tt.allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gooji/internal/video"
+)
+
+// janitorUsage describes the cleanup subcommand
+const janitorUsage = `usage:
+  gooji janitor [-dry-run]
+
+Removes stale temp files and uploads, expires videos under the retention rules
+in config/config.json, and prints a JSON report of what was removed.`
+
+// runJanitorCommand runs the retention janitor once and returns the exit code
+func runJanitorCommand(janitor *video.Janitor, args []string) int {
+	flags := flag.NewFlagSet("janitor", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be removed without removing it")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, janitorUsage)
+		return 2
+	}
+
+	report, err := janitor.RunOnce(video.SystemContext(context.Background()), *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cleanup failed: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+	if len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,23 +10,38 @@ import (
 	"syscall"
 	"time"
 
+	"gooji/internal/audit"
+	"gooji/internal/auth"
 	"gooji/internal/config"
+	"gooji/internal/health"
 	"gooji/internal/logger"
+	"gooji/internal/metrics"
 	"gooji/internal/middleware"
+	"gooji/internal/storage"
+	"gooji/internal/tracing"
 	"gooji/internal/video"
 	"gooji/pkg/ffmpeg"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load("config/config.json")
+	// Load configuration: defaults, then the file, then GOOJI_* variables, then flags
+	opts := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	opts.Environ = os.Environ()
+	cfg, err := config.Load(*opts)
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
+	args := flag.Args()
+
+	// Show the effective configuration: gooji config print [-json]
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfigCommand(cfg, args[1:]))
+	}
 
 	// Initialize logger
-	log, err := logger.New(cfg.Storage.Logs)
+	log, err := logger.New(cfg.Storage.Logs, &cfg.Logging)
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -39,16 +55,94 @@ func main() {
 		log.Debug("APP_DEBUG=%s", os.Getenv("APP_DEBUG"))
 	}
 
+	// Export spans for requests, video operations and FFmpeg runs when tracing is enabled
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing)
+	if err != nil {
+		log.Error("Failed to set up tracing: %v", err)
+		return
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("%v", err)
+		}
+	}()
+
 	// Create video processor
 	processor := ffmpeg.NewProcessor(cfg.FFmpeg.Path)
 
+	// Open the audit log of mutating operations
+	auditLog, err := audit.New(cfg.Storage.Audit, log)
+	if err != nil {
+		log.Error("Failed to open audit log: %v", err)
+		return
+	}
+	auditHandler := audit.NewHandler(auditLog, log)
+
+	// Collect Prometheus metrics from the middleware, uploads and FFmpeg
+	m := metrics.New()
+
 	// Create video handler
-	handler, err := video.NewHandler(processor, &cfg.Storage, log)
+	handler, err := video.NewHandler(processor, cfg, auditLog, m, log)
 	if err != nil {
 		log.Error("Failed to create video handler: %v", err)
 		return // Let defer handle cleanup
 	}
 
+	// Register readiness checks: FFmpeg, writable storage, and the video handler's own
+	checkTimeout, err := time.ParseDuration(cfg.Health.Timeout)
+	if err != nil {
+		log.Error("Invalid health check timeout %q: %v", cfg.Health.Timeout, err)
+		return
+	}
+	ffmpegInterval, err := time.ParseDuration(cfg.Health.FFmpegInterval)
+	if err != nil {
+		log.Error("Invalid FFmpeg check interval %q: %v", cfg.Health.FFmpegInterval, err)
+		return
+	}
+	checks := health.New(checkTimeout, log)
+	checks.Register(health.Checker{Name: "ffmpeg", Check: health.Cached(health.FFmpeg(processor, cfg.Health.RequiredEncoders), ffmpegInterval)})
+	for _, dir := range storage.Directories(&cfg.Storage) {
+		checks.Register(health.Checker{Name: "writable:" + dir.Name, Check: health.Writable(dir.Path)})
+	}
+	checks.Register(handler.HealthChecks()...)
+
+	// Clean up once from the command line: gooji janitor [-dry-run]
+	if len(args) > 0 && args[0] == "janitor" {
+		code := runJanitorCommand(handler.Janitor(), args[1:])
+		log.Close()
+		os.Exit(code)
+	}
+
+	// Create auth handler for accounts, sessions and kiosk devices
+	authHandler, err := auth.NewHandler(cfg, handler, auditLog, log)
+	if err != nil {
+		log.Error("Failed to create auth handler: %v", err)
+		return
+	}
+
+	// Manage accounts from the command line: gooji user add|passwd|list
+	if len(args) > 0 && args[0] == "user" {
+		code := runUserCommand(authHandler.Service(), args[1:])
+		log.Close()
+		os.Exit(code)
+	}
+
+	// Identify the viewer's kiosk, moderator and account roles
+	viewer, err := video.ViewerMiddleware(&cfg.Kiosk)
+	if err != nil {
+		log.Error("Failed to configure kiosk networks: %v", err)
+		return
+	}
+
+	// Allow cross-origin API use only from configured origins
+	cors, err := middleware.CORS(&cfg.CORS)
+	if err != nil {
+		log.Error("Failed to configure CORS: %v", err)
+		return
+	}
+
 	// Create router
 	mux := http.NewServeMux()
 
@@ -56,25 +150,99 @@ func main() {
 	fs := http.FileServer(http.Dir("web/static"))
 	mux.Handle("/static/", http.StripPrefix("/static/", fs))
 
+	// route registers a handler behind the permission policy for its methods
+	route := func(pattern string, policy video.Policy, h http.HandlerFunc) {
+		mux.Handle(pattern, video.Authorize(policy)(h))
+	}
+	view := video.Policy{http.MethodGet: auth.PermViewVideos}
+
 	// API routes
-	mux.HandleFunc("/api/videos", handler.HandleVideos)
-	mux.HandleFunc("/api/thumbnails", handler.GetThumbnail)
-	mux.HandleFunc("/api/videos/", handler.HandleVideo)
+	route("/api/videos", video.Policy{http.MethodGet: auth.PermViewVideos, http.MethodPost: auth.PermUploadVideos}, handler.HandleVideos)
+	route("/api/thumbnails", view, handler.GetThumbnail)
+	// Subresources are edits; DELETE of a whole video and review decisions are narrowed in the service
+	route("/api/videos/", video.Policy{
+		http.MethodGet:    auth.PermViewVideos,
+		http.MethodPost:   auth.PermEditVideos,
+		http.MethodPut:    auth.PermEditVideos,
+		http.MethodDelete: auth.PermEditVideos,
+	}, handler.HandleVideo)
+	route("/api/collections", video.Policy{http.MethodGet: auth.PermViewVideos, http.MethodPost: auth.PermEditVideos}, handler.HandleCollections)
+	route("/api/collections/", video.Policy{
+		http.MethodGet:    auth.PermViewVideos,
+		http.MethodPut:    auth.PermEditVideos,
+		http.MethodDelete: auth.PermEditVideos,
+	}, handler.HandleCollection)
+	route("/api/consent/verify", video.Policy{http.MethodPost: auth.PermViewVideos}, handler.HandleConsentVerify)
+	route("/api/review", video.Policy{http.MethodGet: auth.PermReviewVideos}, handler.HandleReviewQueue)
+	route("/api/users", video.Policy{http.MethodGet: auth.PermManageUsers}, authHandler.HandleUsers)
+	route("/api/users/", video.Policy{http.MethodPut: auth.PermManageUsers}, authHandler.HandleUser)
+	route("/api/tokens", video.Policy{http.MethodGet: auth.PermViewVideos, http.MethodPost: auth.PermViewVideos}, authHandler.HandleTokens)
+	route("/api/tokens/", video.Policy{http.MethodDelete: auth.PermViewVideos}, authHandler.HandleToken)
+	route("/api/devices", video.Policy{http.MethodGet: auth.PermManageDevices}, authHandler.HandleDevices)
+	// Pairing redeems a code on a kiosk that has no credentials yet; the handler checks the rest
+	mux.HandleFunc("/api/devices/", authHandler.HandleDevice)
+	route("/api/audit", video.Policy{http.MethodGet: auth.PermViewAudit}, auditHandler.HandleAudit)
+	route("/api/audit/export", video.Policy{http.MethodGet: auth.PermViewAudit}, auditHandler.HandleExport)
+	route("/api/audit/verify", video.Policy{http.MethodGet: auth.PermViewAudit}, auditHandler.HandleVerify)
+	route("/api/storage", video.Policy{http.MethodGet: auth.PermViewStorage}, handler.HandleStorage)
+	route("/api/retention", video.Policy{http.MethodGet: auth.PermViewStorage, http.MethodPost: auth.PermDeleteVideos}, handler.HandleRetention)
+	route("/api/logging", video.Policy{http.MethodGet: auth.PermManageSettings, http.MethodPut: auth.PermManageSettings}, log.HandleLevels)
+	mux.HandleFunc("/api/auth/me", authHandler.HandleMe)
 
 	// Page routes
-	mux.HandleFunc("/", handler.HandleHome)
-	mux.HandleFunc("/record", handler.HandleRecord)
-	mux.HandleFunc("/upload", handler.HandleUploadPage)
-	mux.HandleFunc("/edit/", handler.HandleEdit)
-	mux.HandleFunc("/gallery", handler.HandleGallery)
+	route("/", view, handler.HandleHome)
+	route("/record", video.Policy{http.MethodGet: auth.PermUploadVideos}, handler.HandleRecord)
+	route("/upload", video.Policy{http.MethodGet: auth.PermUploadVideos}, handler.HandleUploadPage)
+	route("/edit/", video.Policy{http.MethodGet: auth.PermEditVideos}, handler.HandleEdit)
+	route("/gallery", view, handler.HandleGallery)
+	route("/gallery/collections/", view, handler.HandleCollectionPage)
+	route("/review", video.Policy{http.MethodGet: auth.PermReviewVideos}, handler.HandleReviewPage)
+	mux.HandleFunc("/login", authHandler.HandleLogin)
+	mux.HandleFunc("/logout", authHandler.HandleLogout)
+	mux.HandleFunc("/pair", authHandler.HandlePair)
+
+	// Liveness and readiness; /health is kept for monitors that predate /readyz
+	mux.HandleFunc("/livez", checks.HandleLive)
+	mux.HandleFunc("/readyz", checks.HandleReady)
+	mux.HandleFunc("/health", checks.HandleReady)
+
+	// Prometheus metrics for scrapers on the metrics networks, and for moderators and admins
+	if cfg.Metrics.Enabled {
+		scrape, err := video.AuthorizeOrNetworks(cfg.Metrics.Networks, video.Policy{http.MethodGet: auth.PermViewStorage})
+		if err != nil {
+			log.Error("Failed to configure metrics networks: %v", err)
+			return
+		}
+		mux.Handle("/metrics", scrape(m.Handler()))
+	}
+
+	// routeOf names the pattern serving a request, for span names and metric labels
+	routeOf := func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
 
-	// Health check endpoint
-	mux.HandleFunc("/health", handler.HandleHealth)
+	// Rate limits are swapped in place when the configuration is reloaded
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimits, auth.ClientKey)
 
-	// Create server with middleware
+	// Create server with middleware; sessions, devices and bearer tokens must resolve before CSRF checks and the viewer
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler: middleware.Chain(mux, middleware.Logging(log), middleware.Recovery(log), middleware.CORS()),
+		Addr: fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler: middleware.Chain(mux,
+			middleware.RequestID(),
+			middleware.Tracing(routeOf),
+			middleware.Metrics(m, routeOf),
+			middleware.Logging(log),
+			middleware.Recovery(log),
+			middleware.SecurityHeaders(&cfg.Headers),
+			cors,
+			auth.Sessions(authHandler.Service(), cfg.Auth.SecureCookies),
+			auth.Devices(authHandler.Service(), cfg.Auth.SecureCookies),
+			auth.Bearer(authHandler.Service()),
+			rateLimiter.Middleware(),
+			auth.CSRF(),
+			viewer,
+		),
 	}
 
 	// Start server in a goroutine
@@ -87,6 +255,52 @@ func main() {
 		}
 	}()
 
+	// Remove stale files and expired videos in the background
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	go handler.Janitor().Run(janitorCtx)
+
+	// Reload allowed types, rate limits, FFmpeg limits and log levels on SIGHUP
+	// or when the configuration file changes; invalid configurations are rejected
+	watcher := config.NewWatcher(*opts, cfg, func(prev, next *config.Config) error {
+		// Log levels go first: they are the only settings that can still be refused
+		if err := log.Reload(&prev.Logging, &next.Logging); err != nil {
+			return err
+		}
+		if err := handler.Reload(next); err != nil {
+			return err
+		}
+		rateLimiter.Update(next.RateLimits)
+		return nil
+	})
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go func() {
+		err := watcher.Run(reloadCtx, func(trigger string, changed []string, err error) {
+			if err != nil {
+				log.Errorw("Configuration rejected; keeping the current one", "trigger", trigger, "error", err.Error())
+				return
+			}
+			if len(changed) == 0 {
+				log.Infow("Configuration reloaded without changes", "trigger", trigger)
+				return
+			}
+			var restart []string
+			for _, path := range changed {
+				if !config.Reloadable(path) {
+					restart = append(restart, path)
+				}
+			}
+			log.Infow("Configuration reloaded", "trigger", trigger, "changed", changed)
+			if len(restart) > 0 {
+				log.Infow("Some changed settings take effect on restart", "settings", restart)
+			}
+		})
+		if err != nil {
+			log.Error("Configuration reload disabled: %v", err)
+		}
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -98,6 +312,7 @@ func main() {
 
 	// Attempt graceful shutdown
 	log.Info("Shutting down server...")
+	stopJanitor()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Error("Server forced to shutdown: %v", err)
 	}
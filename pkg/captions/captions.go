@@ -0,0 +1,319 @@
+package captions
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format identifies a caption file format
+type Format string
+
+const (
+	// FormatWebVTT is the W3C WebVTT format used by HTML5 video
+	FormatWebVTT Format = "vtt"
+	// FormatSRT is the SubRip format
+	FormatSRT Format = "srt"
+)
+
+// MaxCues limits the number of cues accepted in a single track
+const MaxCues = 10000
+
+// Cue is a single timed caption
+type Cue struct {
+	ID    string
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Track is an ordered list of caption cues
+type Track struct {
+	Cues []Cue
+}
+
+// ParseFormat converts a format name or file extension into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "vtt", "webvtt", "text/vtt":
+		return FormatWebVTT, nil
+	case "srt", "subrip", "application/x-subrip":
+		return FormatSRT, nil
+	default:
+		return "", fmt.Errorf("unsupported caption format: %s", name)
+	}
+}
+
+// DetectFormat guesses the format of caption data from its content
+func DetectFormat(data []byte) Format {
+	trimmed := bytes.TrimPrefix(data, []byte("\ufeff"))
+	if bytes.HasPrefix(trimmed, []byte("WEBVTT")) {
+		return FormatWebVTT
+	}
+	return FormatSRT
+}
+
+// Parse parses and validates caption data in the given format
+func Parse(data []byte, format Format) (*Track, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("caption data must be valid UTF-8")
+	}
+
+	switch format {
+	case FormatWebVTT:
+		return parseVTT(data)
+	case FormatSRT:
+		return parseSRT(data)
+	default:
+		return nil, fmt.Errorf("unsupported caption format: %s", format)
+	}
+}
+
+// Encode writes a track in the given format
+func (t *Track) Encode(format Format) ([]byte, error) {
+	switch format {
+	case FormatWebVTT:
+		return t.encodeVTT(), nil
+	case FormatSRT:
+		return t.encodeSRT(), nil
+	default:
+		return nil, fmt.Errorf("unsupported caption format: %s", format)
+	}
+}
+
+// ContentType returns the MIME type for a caption format
+func (f Format) ContentType() string {
+	if f == FormatSRT {
+		return "application/x-subrip; charset=utf-8"
+	}
+	return "text/vtt; charset=utf-8"
+}
+
+// parseVTT parses a WebVTT file
+func parseVTT(data []byte) (*Track, error) {
+	blocks := splitBlocks(data)
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0][0], "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+
+	track := &Track{}
+	for _, block := range blocks[1:] {
+		// Skip comment, style and region blocks
+		if strings.HasPrefix(block[0], "NOTE") || block[0] == "STYLE" || block[0] == "REGION" {
+			continue
+		}
+
+		var id string
+		lines := block
+		if !strings.Contains(lines[0], "-->") {
+			id = lines[0]
+			lines = lines[1:]
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("cue %q has no timing line", id)
+		}
+
+		start, end, err := parseTimingLine(lines[0], '.')
+		if err != nil {
+			return nil, fmt.Errorf("cue %d: %w", len(track.Cues)+1, err)
+		}
+		if err := track.add(Cue{ID: id, Start: start, End: end, Text: strings.Join(lines[1:], "\n")}); err != nil {
+			return nil, err
+		}
+	}
+
+	return track, nil
+}
+
+// parseSRT parses a SubRip file
+func parseSRT(data []byte) (*Track, error) {
+	track := &Track{}
+	for _, block := range splitBlocks(data) {
+		lines := block
+		var id string
+		if !strings.Contains(lines[0], "-->") {
+			id = lines[0]
+			if _, err := strconv.Atoi(id); err != nil {
+				return nil, fmt.Errorf("invalid cue number %q", id)
+			}
+			lines = lines[1:]
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("cue %s has no timing line", id)
+		}
+
+		start, end, err := parseTimingLine(lines[0], ',')
+		if err != nil {
+			return nil, fmt.Errorf("cue %d: %w", len(track.Cues)+1, err)
+		}
+		if err := track.add(Cue{Start: start, End: end, Text: strings.Join(lines[1:], "\n")}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(track.Cues) == 0 {
+		return nil, fmt.Errorf("no cues found")
+	}
+	return track, nil
+}
+
+// add validates and appends a cue
+func (t *Track) add(cue Cue) error {
+	if len(t.Cues) >= MaxCues {
+		return fmt.Errorf("too many cues (maximum %d)", MaxCues)
+	}
+	if cue.End <= cue.Start {
+		return fmt.Errorf("cue %d ends before it starts", len(t.Cues)+1)
+	}
+	if strings.Contains(cue.Text, "-->") {
+		return fmt.Errorf("cue %d text must not contain \"-->\"", len(t.Cues)+1)
+	}
+	t.Cues = append(t.Cues, cue)
+	return nil
+}
+
+// splitBlocks splits caption data into blank-line separated blocks of trimmed lines
+func splitBlocks(data []byte) [][]string {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	var blocks [][]string
+	var current []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r \t")
+		if line == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// parseTimingLine parses "start --> end [settings]" using the given fractional separator
+func parseTimingLine(line string, fractionSep byte) (time.Duration, time.Duration, error) {
+	parts := strings.SplitN(line, "-->", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid timing line %q", line)
+	}
+
+	start, err := parseTimestamp(strings.TrimSpace(parts[0]), fractionSep)
+	if err != nil {
+		return 0, 0, err
+	}
+	endFields := strings.Fields(parts[1])
+	if len(endFields) == 0 {
+		return 0, 0, fmt.Errorf("missing end time in %q", line)
+	}
+	end, err := parseTimestamp(endFields[0], fractionSep)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// maxTimestampHours bounds the hours field so timestamps cannot overflow a time.Duration
+const maxTimestampHours = 99
+
+// parseTimestamp parses [hh:]mm:ss<sep>mmm
+func parseTimestamp(value string, fractionSep byte) (time.Duration, error) {
+	sep := strings.LastIndexByte(value, fractionSep)
+	if sep < 0 || len(value)-sep-1 != 3 || !isDigits(value[sep+1:]) {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	millis, err := strconv.Atoi(value[sep+1:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	fields := strings.Split(value[:sep], ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	var total time.Duration
+	for i, field := range fields {
+		if !isDigits(field) {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		n, err := strconv.Atoi(field)
+		limit := 59
+		if i == 0 && len(fields) == 3 {
+			limit = maxTimestampHours
+		}
+		if err != nil || n > limit {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		total = total*60 + time.Duration(n)
+	}
+	return total*time.Second + time.Duration(millis)*time.Millisecond, nil
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTimestamp formats a duration as hh:mm:ss<sep>mmm
+func formatTimestamp(d time.Duration, fractionSep byte) string {
+	if d < 0 {
+		d = 0
+	}
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", hours, minutes, seconds, fractionSep, d/time.Millisecond)
+}
+
+// encodeVTT writes the track as WebVTT
+func (t *Track) encodeVTT() []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n")
+	for _, cue := range t.Cues {
+		b.WriteString("\n")
+		if cue.ID != "" {
+			b.WriteString(cue.ID + "\n")
+		}
+		fmt.Fprintf(&b, "%s --> %s\n", formatTimestamp(cue.Start, '.'), formatTimestamp(cue.End, '.'))
+		if cue.Text != "" {
+			b.WriteString(cue.Text + "\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// encodeSRT writes the track as SubRip, numbering cues from 1
+func (t *Track) encodeSRT() []byte {
+	var b bytes.Buffer
+	for i, cue := range t.Cues {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n", i+1, formatTimestamp(cue.Start, ','), formatTimestamp(cue.End, ','))
+		if cue.Text != "" {
+			b.WriteString(cue.Text + "\n")
+		}
+	}
+	return b.Bytes()
+}
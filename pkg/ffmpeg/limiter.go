@@ -0,0 +1,108 @@
+package ffmpeg
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBusy is returned when no FFmpeg slot frees up within the limiter's wait
+var ErrBusy = errors.New("too many FFmpeg processes running")
+
+// Limiter caps how many FFmpeg processes run at once across every processor sharing it
+type Limiter struct {
+	mu      sync.Mutex
+	max     int
+	running int
+	wait    time.Duration
+	// freed is closed and replaced whenever a slot frees up or the cap is raised
+	freed   chan struct{}
+	waiting atomic.Int64
+}
+
+// NewLimiter creates a limiter allowing max concurrent processes; callers wait
+// up to wait for a free slot before failing with ErrBusy
+func NewLimiter(max int, wait time.Duration) *Limiter {
+	l := &Limiter{freed: make(chan struct{})}
+	l.SetLimits(max, wait)
+	return l
+}
+
+// SetLimits changes the cap and the wait while running. Processes already
+// running keep their slots; lowering the cap holds back new ones until enough finish.
+func (l *Limiter) SetLimits(max int, wait time.Duration) {
+	if max < 1 {
+		max = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+	l.wait = wait
+	l.wake()
+}
+
+// Running returns how many processes hold a slot
+func (l *Limiter) Running() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running
+}
+
+// Capacity returns the maximum number of concurrent processes
+func (l *Limiter) Capacity() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.max
+}
+
+// Waiting returns how many callers are queued for a slot
+func (l *Limiter) Waiting() int {
+	return int(l.waiting.Load())
+}
+
+// acquire takes a slot, returning the function that releases it
+func (l *Limiter) acquire() (func(), error) {
+	l.mu.Lock()
+	if l.running < l.max {
+		l.running++
+		l.mu.Unlock()
+		return l.release, nil
+	}
+	timer := time.NewTimer(l.wait)
+	l.mu.Unlock()
+	defer timer.Stop()
+
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+	for {
+		l.mu.Lock()
+		if l.running < l.max {
+			l.running++
+			l.mu.Unlock()
+			return l.release, nil
+		}
+		freed := l.freed
+		l.mu.Unlock()
+
+		select {
+		case <-freed:
+		case <-timer.C:
+			return nil, ErrBusy
+		}
+	}
+}
+
+// release gives a slot back and wakes the callers waiting for one
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	l.wake()
+}
+
+// wake signals waiting callers to try again; l.mu must be held
+func (l *Limiter) wake() {
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
@@ -0,0 +1,58 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Capabilities describes the installed FFmpeg build
+type Capabilities struct {
+	Version  string
+	Encoders map[string]bool
+}
+
+// Probe asks FFmpeg for its version and encoders. It does not take a limiter
+// slot, since both queries return at once without touching any media.
+func (p *Processor) Probe(ctx context.Context) (*Capabilities, error) {
+	if err := p.validateFFmpegPath(); err != nil {
+		return nil, fmt.Errorf("FFmpeg path validation failed: %w", err)
+	}
+
+	version, err := exec.CommandContext(ctx, p.ffmpegPath, "-hide_banner", "-version").Output() //nolint:gosec // Path validated above
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s -version: %w", p.ffmpegPath, err)
+	}
+	encoders, err := exec.CommandContext(ctx, p.ffmpegPath, "-hide_banner", "-encoders").Output() //nolint:gosec // Path validated above
+	if err != nil {
+		return nil, fmt.Errorf("failed to list FFmpeg encoders: %w", err)
+	}
+
+	caps := &Capabilities{Encoders: parseEncoders(string(encoders))}
+	if line, _, _ := strings.Cut(string(version), "\n"); line != "" {
+		caps.Version = strings.TrimSpace(line)
+	}
+	return caps, nil
+}
+
+// parseEncoders reads encoder names from "ffmpeg -encoders" output. Each
+// encoder line starts with six capability flags, then the name; the legend
+// above the list ends with a "------" line.
+func parseEncoders(output string) map[string]bool {
+	encoders := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	listing := false
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if !listing {
+			listing = len(fields) == 1 && strings.HasPrefix(fields[0], "---")
+			continue
+		}
+		if len(fields) >= 2 {
+			encoders[fields[1]] = true
+		}
+	}
+	return encoders
+}
@@ -2,12 +2,14 @@ package ffmpeg
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // VideoInfo contains metadata about a video file
@@ -26,8 +28,13 @@ type VideoInfo struct {
 type Processor struct {
 	ffmpegPath string
 	allowedDir string
+	limiter    *Limiter
+	observer   Observer
 }
 
+// Observer is told how long each FFmpeg run took and whether it failed
+type Observer func(operation string, duration time.Duration, err error)
+
 // NewProcessor creates a new FFmpeg processor
 func NewProcessor(ffmpegPath string) *Processor {
 	if ffmpegPath == "" {
@@ -54,6 +61,31 @@ func (p *Processor) FFmpegPath() string {
 	return p.ffmpegPath
 }
 
+// SetLimiter makes the processor share a cap on concurrent FFmpeg processes
+func (p *Processor) SetLimiter(limiter *Limiter) {
+	p.limiter = limiter
+}
+
+// SetObserver reports every FFmpeg run to observer, for metrics
+func (p *Processor) SetObserver(observer Observer) {
+	p.observer = observer
+}
+
+// observe reports a finished run to the observer, if any
+func (p *Processor) observe(operation string, start time.Time, err error) {
+	if p.observer != nil {
+		p.observer(operation, time.Since(start), err)
+	}
+}
+
+// acquireSlot waits for the limiter, if any, and returns the function that releases the slot
+func (p *Processor) acquireSlot() (func(), error) {
+	if p.limiter == nil {
+		return func() {}, nil
+	}
+	return p.limiter.acquire()
+}
+
 // validatePath ensures a file path is secure and within allowed directory
 func (p *Processor) validatePath(filePath string) error {
 	if filePath == "" {
@@ -128,7 +160,24 @@ func (p *Processor) validateFFmpegPath() error {
 }
 
 // executeCommand executes a command with security validation
-func (p *Processor) executeCommand(args []string) error {
+func (p *Processor) executeCommand(ctx context.Context, operation string, args []string) error {
+	return p.run(ctx, operation, args, nil)
+}
+
+// executeCommandWithOutput executes a command with security validation and returns output
+func (p *Processor) executeCommandWithOutput(ctx context.Context, operation string, args []string) (string, error) {
+	var stderr bytes.Buffer
+	err := p.run(ctx, operation, args, &stderr)
+	return stderr.String(), err
+}
+
+// run validates and runs FFmpeg in a span of its own, capturing stderr when
+// it is not nil. The run is not cancelled with ctx, which only carries the
+// trace, so a client that disconnects cannot leave half-written files behind.
+func (p *Processor) run(ctx context.Context, operation string, args []string, stderr *bytes.Buffer) (err error) {
+	ctx, span := startSpan(ctx, operation, args)
+	defer func() { endSpan(span, err) }()
+
 	// Validate FFmpeg path
 	if err := p.validateFFmpegPath(); err != nil {
 		return fmt.Errorf("FFmpeg path validation failed: %w", err)
@@ -148,46 +197,33 @@ func (p *Processor) executeCommand(args []string) error {
 
 	// Execute command with validated arguments
 	// Note: All arguments have been validated above, so this is safe
-	cmd := exec.Command(p.ffmpegPath, args...) //nolint:gosec // All arguments validated above
-	return cmd.Run()
-}
-
-// executeCommandWithOutput executes a command with security validation and returns output
-func (p *Processor) executeCommandWithOutput(args []string) (string, error) {
-	// Validate FFmpeg path
-	if err := p.validateFFmpegPath(); err != nil {
-		return "", fmt.Errorf("FFmpeg path validation failed: %w", err)
-	}
-
-	// Validate all arguments that are file paths
-	for i, arg := range args {
-		// Skip FFmpeg options that start with -
-		if strings.HasPrefix(arg, "-") {
-			continue
-		}
-		// Validate file paths
-		if err := p.validatePath(arg); err != nil {
-			return "", fmt.Errorf("invalid argument %d: %w", i, err)
-		}
+	waitStart := time.Now()
+	release, err := p.acquireSlot()
+	recordWait(ctx, time.Since(waitStart))
+	if err != nil {
+		return err
 	}
+	defer release()
 
-	// Execute command with validated arguments
-	// Note: All arguments have been validated above, so this is safe
+	start := time.Now()
 	cmd := exec.Command(p.ffmpegPath, args...) //nolint:gosec // All arguments validated above
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	return stderr.String(), err
+	if stderr != nil {
+		cmd.Stderr = stderr
+	}
+	err = cmd.Run()
+	recordExit(ctx, cmd.ProcessState)
+	p.observe(operation, start, err)
+	return err
 }
 
 // GetVideoInfo retrieves metadata about a video file
-func (p *Processor) GetVideoInfo(inputPath string) (*VideoInfo, error) {
+func (p *Processor) GetVideoInfo(ctx context.Context, inputPath string) (*VideoInfo, error) {
 	// Validate input path
 	if err := p.validatePath(inputPath); err != nil {
 		return nil, fmt.Errorf("invalid input path: %w", err)
 	}
 
-	output, err := p.executeCommandWithOutput([]string{"-i", inputPath, "-f", "null", "-"})
+	output, err := p.executeCommandWithOutput(ctx, "info", []string{"-i", inputPath, "-f", "null", "-"})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
@@ -246,7 +282,7 @@ func (p *Processor) GetVideoInfo(inputPath string) (*VideoInfo, error) {
 }
 
 // GenerateThumbnail creates a thumbnail image from a video file
-func (p *Processor) GenerateThumbnail(inputPath, outputPath string, timestamp float64) error {
+func (p *Processor) GenerateThumbnail(ctx context.Context, inputPath, outputPath string, timestamp float64) error {
 	// Validate input and output paths
 	if err := p.validatePath(inputPath); err != nil {
 		return fmt.Errorf("invalid input path: %w", err)
@@ -260,7 +296,7 @@ func (p *Processor) GenerateThumbnail(inputPath, outputPath string, timestamp fl
 		return fmt.Errorf("timestamp must be non-negative")
 	}
 
-	return p.executeCommand([]string{
+	return p.executeCommand(ctx, "thumbnail", []string{
 		"-i", inputPath,
 		"-ss", fmt.Sprintf("%.2f", timestamp),
 		"-vframes", "1",
@@ -270,7 +306,7 @@ func (p *Processor) GenerateThumbnail(inputPath, outputPath string, timestamp fl
 }
 
 // TrimVideo trims a video to the specified start and end times
-func (p *Processor) TrimVideo(inputPath, outputPath string, startTime, endTime float64) error {
+func (p *Processor) TrimVideo(ctx context.Context, inputPath, outputPath string, startTime, endTime float64) error {
 	// Validate input and output paths
 	if err := p.validatePath(inputPath); err != nil {
 		return fmt.Errorf("invalid input path: %w", err)
@@ -287,7 +323,7 @@ func (p *Processor) TrimVideo(inputPath, outputPath string, startTime, endTime f
 		return fmt.Errorf("start time must be less than end time")
 	}
 
-	return p.executeCommand([]string{
+	return p.executeCommand(ctx, "trim", []string{
 		"-i", inputPath,
 		"-ss", fmt.Sprintf("%.2f", startTime),
 		"-to", fmt.Sprintf("%.2f", endTime),
@@ -311,7 +347,7 @@ func extractValue(output, prefix string) string {
 }
 
 // AddWatermark adds a watermark to the video
-func (p *Processor) AddWatermark(inputPath, outputPath, watermarkPath string) error {
+func (p *Processor) AddWatermark(ctx context.Context, inputPath, outputPath, watermarkPath string) error {
 	// Validate all paths
 	if err := p.validatePath(inputPath); err != nil {
 		return fmt.Errorf("invalid input path: %w", err)
@@ -323,7 +359,7 @@ func (p *Processor) AddWatermark(inputPath, outputPath, watermarkPath string) er
 		return fmt.Errorf("invalid watermark path: %w", err)
 	}
 
-	return p.executeCommand([]string{
+	return p.executeCommand(ctx, "watermark", []string{
 		"-i", inputPath,
 		"-i", watermarkPath,
 		"-filter_complex", "overlay=10:10",
@@ -333,7 +369,7 @@ func (p *Processor) AddWatermark(inputPath, outputPath, watermarkPath string) er
 }
 
 // ConvertToMP4 converts a video to MP4 format
-func (p *Processor) ConvertToMP4(inputPath, outputPath string) error {
+func (p *Processor) ConvertToMP4(ctx context.Context, inputPath, outputPath string) error {
 	// Validate input and output paths
 	if err := p.validatePath(inputPath); err != nil {
 		return fmt.Errorf("invalid input path: %w", err)
@@ -342,7 +378,7 @@ func (p *Processor) ConvertToMP4(inputPath, outputPath string) error {
 		return fmt.Errorf("invalid output path: %w", err)
 	}
 
-	return p.executeCommand([]string{
+	return p.executeCommand(ctx, "convert", []string{
 		"-i", inputPath,
 		"-c:v", "libx264",
 		"-c:a", "aac",
@@ -351,8 +387,36 @@ func (p *Processor) ConvertToMP4(inputPath, outputPath string) error {
 	})
 }
 
+// BurnSubtitles renders a subtitle file permanently into the video frames
+func (p *Processor) BurnSubtitles(ctx context.Context, inputPath, subtitlePath, outputPath string) error {
+	// Validate all paths
+	if err := p.validatePath(inputPath); err != nil {
+		return fmt.Errorf("invalid input path: %w", err)
+	}
+	if err := p.validatePath(subtitlePath); err != nil {
+		return fmt.Errorf("invalid subtitle path: %w", err)
+	}
+	if err := p.validatePath(outputPath); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+
+	// Characters with special meaning in filtergraphs cannot be safely passed to the subtitles filter
+	if strings.ContainsAny(subtitlePath, ":',=") {
+		return fmt.Errorf("subtitle path contains characters not supported by the subtitles filter: %s", subtitlePath)
+	}
+
+	return p.executeCommand(ctx, "burn_subtitles", []string{
+		"-y",
+		"-i", inputPath,
+		"-vf", "subtitles=" + subtitlePath,
+		"-c:v", "libx264",
+		"-c:a", "copy",
+		outputPath,
+	})
+}
+
 // ValidateVideo validates that a file is a valid video file
-func (p *Processor) ValidateVideo(inputPath string) error {
+func (p *Processor) ValidateVideo(ctx context.Context, inputPath string) error {
 	// Validate input path
 	if err := p.validatePath(inputPath); err != nil {
 		return fmt.Errorf("invalid input path: %w", err)
@@ -364,7 +428,7 @@ func (p *Processor) ValidateVideo(inputPath string) error {
 	}
 
 	// Try to get video info to validate it's a proper video file
-	_, err := p.GetVideoInfo(inputPath)
+	_, err := p.GetVideoInfo(ctx, inputPath)
 	if err != nil {
 		return fmt.Errorf("invalid video file: %w", err)
 	}
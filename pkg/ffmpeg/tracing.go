@@ -0,0 +1,44 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a span for each FFmpeg run; it is a no-op until a tracer provider is installed
+var tracer = otel.Tracer("gooji/pkg/ffmpeg")
+
+// startSpan starts the span for one FFmpeg run with its operation and arguments
+func startSpan(ctx context.Context, operation string, args []string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "ffmpeg "+operation, trace.WithAttributes(
+		attribute.String("ffmpeg.operation", operation),
+		attribute.StringSlice("ffmpeg.args", args),
+	))
+}
+
+// recordWait notes how long the run waited for a limiter slot
+func recordWait(ctx context.Context, wait time.Duration) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("ffmpeg.queue_wait_ms", wait.Milliseconds()))
+}
+
+// recordExit notes the process's exit status, if it started
+func recordExit(ctx context.Context, state *os.ProcessState) {
+	if state != nil {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("process.exit.code", state.ExitCode()))
+	}
+}
+
+// endSpan marks the span failed when err is set and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
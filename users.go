@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gooji/internal/auth"
+)
+
+// userUsage describes the user management subcommands
+const userUsage = `usage:
+  gooji user add [-role viewer|recorder|editor|moderator|admin] <username> [display name]
+  gooji user passwd <username>
+  gooji user role <username> <role>
+  gooji user community <username> [community role...]
+  gooji user researcher <username> on|off
+  gooji user list
+
+The password is read from GOOJI_PASSWORD, or from the first line of standard input.`
+
+// runUserCommand manages local accounts from the command line and returns the exit code
+func runUserCommand(svc *auth.Service, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+
+	ctx := auth.CommandLineContext(context.Background())
+	switch {
+	case args[0] == "add":
+		flags := flag.NewFlagSet("user add", flag.ContinueOnError)
+		role := flags.String("role", string(auth.RoleViewer), "account role")
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() < 1 || flags.NArg() > 2 {
+			fmt.Fprintln(os.Stderr, userUsage)
+			return 2
+		}
+		password, err := readPassword()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read password: %v\n", err)
+			return 1
+		}
+		user, err := svc.CreateUser(ctx, flags.Arg(0), flags.Arg(1), password, auth.Role(*role))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create user: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Created user %s (%s) with role %s\n", user.Username, user.ID, user.Role)
+	case args[0] == "passwd" && len(args) == 2:
+		password, err := readPassword()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read password: %v\n", err)
+			return 1
+		}
+		if err := svc.SetPassword(ctx, args[1], password); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set password: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Password updated for %s; existing sessions were signed out\n", args[1])
+	case args[0] == "role" && len(args) == 3:
+		user, err := svc.SetRole(ctx, args[1], auth.Role(args[2]))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set role: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Role of %s is now %s\n", user.Username, user.Role)
+	case args[0] == "community" && len(args) >= 2:
+		user, err := svc.SetCommunityRoles(ctx, args[1], args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set community roles: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Community roles of %s are now %s\n", user.Username, strings.Join(user.CommunityRoles, ", "))
+	case args[0] == "researcher" && len(args) == 3 && (args[2] == "on" || args[2] == "off"):
+		user, err := svc.SetResearcher(ctx, args[1], args[2] == "on")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set researcher access: %v\n", err)
+			return 1
+		}
+		if user.Researcher {
+			fmt.Printf("%s is now an approved researcher\n", user.Username)
+		} else {
+			fmt.Printf("%s is no longer an approved researcher\n", user.Username)
+		}
+	case args[0] == "list" && len(args) == 1:
+		users, err := svc.ListUsers(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list users: %v\n", err)
+			return 1
+		}
+		for _, user := range users {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", user.ID, user.Username, user.Role, user.DisplayName, strings.Join(user.CommunityRoles, ","))
+		}
+	default:
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+	return 0
+}
+
+// readPassword returns GOOJI_PASSWORD, or the first line of standard input
+func readPassword() (string, error) {
+	if password := os.Getenv("GOOJI_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
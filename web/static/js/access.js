@@ -0,0 +1,42 @@
+// Cultural protocol fields shared by the record and upload pages
+
+document.addEventListener('DOMContentLoaded', function () {
+    const level = document.getElementById('accessLevel');
+    const season = document.getElementById('accessSeason');
+
+    if (!level || !season) {
+        return;
+    }
+
+    const rolesField = document.getElementById('accessRolesField');
+    const roles = document.getElementById('accessRoles');
+    const seasonWindow = document.getElementById('accessSeasonWindow');
+    const seasonLabel = document.getElementById('accessSeasonLabel');
+    const seasonStart = document.getElementById('accessSeasonStart');
+    const seasonEnd = document.getElementById('accessSeasonEnd');
+
+    level.addEventListener('change', function () {
+        const restricted = level.value === 'restricted';
+        rolesField.classList.toggle('hidden', !restricted);
+        roles.required = restricted;
+    });
+
+    season.addEventListener('change', function () {
+        const option = season.options[season.selectedIndex];
+        seasonWindow.classList.toggle('hidden', season.value === '');
+        seasonLabel.value = season.value === 'custom' ? '' : season.value;
+        seasonStart.value = option.dataset.start || '';
+        seasonEnd.value = option.dataset.end || '';
+        seasonStart.required = season.value !== '';
+        seasonEnd.required = season.value !== '';
+    });
+});
+
+// Append the cultural protocol fields to an upload's form data
+function appendAccess(formData) {
+    document.querySelectorAll('#accessFields [name]').forEach(function (field) {
+        if (field.value) {
+            formData.append(field.name, field.value);
+        }
+    });
+}
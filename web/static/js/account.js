@@ -0,0 +1,33 @@
+// Replace the "Sign in" link with the signed-in user's name and a sign-out button
+
+document.addEventListener('DOMContentLoaded', async function () {
+    const accountNav = document.getElementById('accountNav');
+    if (!accountNav) {
+        return;
+    }
+
+    try {
+        const response = await fetch('/api/auth/me');
+        if (!response.ok) {
+            return;
+        }
+        const me = await response.json();
+        if (!me.authenticated) {
+            return;
+        }
+
+        const token = (document.cookie.match(/(?:^|;\s*)gooji_csrf=([^;]*)/) || [])[1] || '';
+        accountNav.innerHTML = `
+            <span class="text-sm text-gray-600">${escapeHTML(me.display_name)}</span>
+            <form method="POST" action="/logout">
+                <input type="hidden" name="csrf_token" value="${escapeHTML(decodeURIComponent(token))}">
+                <button type="submit"
+                    class="px-4 py-2 rounded-lg border border-gray-200 text-gray-700 font-medium hover:bg-gray-50 transition-colors duration-200">
+                    Sign out
+                </button>
+            </form>
+        `;
+    } catch (err) {
+        console.error('Error loading account:', err);
+    }
+});
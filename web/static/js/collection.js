@@ -0,0 +1,52 @@
+// Collection player elements
+const collectionVideo = document.getElementById('collectionVideo');
+const currentTitle = document.getElementById('currentTitle');
+const currentDescription = document.getElementById('currentDescription');
+const prevBtn = document.getElementById('prevBtn');
+const nextBtn = document.getElementById('nextBtn');
+const playlistItems = document.querySelectorAll('.playlist-item');
+
+// State
+const collectionVideos = JSON.parse(document.getElementById('collectionData').textContent || '[]') || [];
+let currentIndex = 0;
+
+// Play the video at the given position in the collection
+function playAt(index) {
+    if (index < 0 || index >= collectionVideos.length) {
+        return;
+    }
+
+    currentIndex = index;
+    const video = collectionVideos[index];
+
+    collectionVideo.src = `/api/videos/${encodeURIComponent(video.id)}`;
+    currentTitle.textContent = video.title;
+    currentDescription.textContent = video.description;
+
+    playlistItems.forEach(item => {
+        const active = Number(item.dataset.index) === index;
+        item.classList.toggle('bg-indigo-100', active);
+    });
+
+    prevBtn.disabled = index === 0;
+    nextBtn.disabled = index === collectionVideos.length - 1;
+
+    collectionVideo.play().catch(err => console.debug('Autoplay prevented:', err));
+}
+
+// Initialize collection player
+document.addEventListener('DOMContentLoaded', () => {
+    if (!collectionVideo || collectionVideos.length === 0) {
+        return;
+    }
+
+    // Advance to the next video when one finishes
+    collectionVideo.addEventListener('ended', () => playAt(currentIndex + 1));
+    prevBtn.addEventListener('click', () => playAt(currentIndex - 1));
+    nextBtn.addEventListener('click', () => playAt(currentIndex + 1));
+    playlistItems.forEach(item => {
+        item.addEventListener('click', () => playAt(Number(item.dataset.index)));
+    });
+
+    playAt(0);
+});
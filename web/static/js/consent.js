@@ -0,0 +1,39 @@
+// Participant consent fields shared by the record and upload pages
+
+document.addEventListener('DOMContentLoaded', function () {
+    const anonymous = document.getElementById('consentAnonymous');
+    const speaker = document.getElementById('consentSpeaker');
+    const minor = document.getElementById('consentMinor');
+    const guardianFields = document.getElementById('guardianFields');
+
+    if (!anonymous || !minor) {
+        return;
+    }
+
+    const syncFields = function () {
+        speaker.disabled = anonymous.checked;
+        speaker.required = !anonymous.checked;
+        if (anonymous.checked) {
+            speaker.value = '';
+        }
+
+        guardianFields.classList.toggle('hidden', !minor.checked);
+        document.getElementById('consentGuardianName').required = minor.checked;
+        document.getElementById('consentGuardianConsent').required = minor.checked;
+    };
+
+    anonymous.addEventListener('change', syncFields);
+    minor.addEventListener('change', syncFields);
+    syncFields();
+});
+
+// Append the consent fields to an upload's form data
+function appendConsent(formData) {
+    document.querySelectorAll('#consentFields [name]').forEach(function (field) {
+        if (field.type === 'checkbox') {
+            formData.append(field.name, field.checked ? 'true' : 'false');
+        } else if (!field.disabled) {
+            formData.append(field.name, field.value);
+        }
+    });
+}
@@ -0,0 +1,46 @@
+// Send the session's CSRF token with every same-origin request that changes state.
+// The server sets the token in a script-readable cookie at sign-in.
+
+(function () {
+    const SAFE_METHODS = ['GET', 'HEAD', 'OPTIONS', 'TRACE'];
+
+    function csrfToken() {
+        const match = document.cookie.match(/(?:^|;\s*)gooji_csrf=([^;]*)/);
+        return match ? decodeURIComponent(match[1]) : '';
+    }
+
+    function needsToken(method, url) {
+        if (SAFE_METHODS.includes(String(method || 'GET').toUpperCase())) {
+            return false;
+        }
+        return new URL(url, window.location.href).origin === window.location.origin;
+    }
+
+    const originalFetch = window.fetch;
+    window.fetch = function (input, init) {
+        init = init || {};
+        const url = input instanceof Request ? input.url : input;
+        const method = init.method || (input instanceof Request ? input.method : 'GET');
+        const token = csrfToken();
+        if (token && needsToken(method, url)) {
+            const headers = new Headers(init.headers || (input instanceof Request ? input.headers : undefined));
+            headers.set('X-CSRF-Token', token);
+            init = Object.assign({}, init, { headers: headers });
+        }
+        return originalFetch.call(this, input, init);
+    };
+
+    const originalOpen = XMLHttpRequest.prototype.open;
+    const originalSend = XMLHttpRequest.prototype.send;
+    XMLHttpRequest.prototype.open = function (method, url) {
+        this._csrfNeeded = needsToken(method, url);
+        return originalOpen.apply(this, arguments);
+    };
+    XMLHttpRequest.prototype.send = function () {
+        const token = csrfToken();
+        if (token && this._csrfNeeded) {
+            this.setRequestHeader('X-CSRF-Token', token);
+        }
+        return originalSend.apply(this, arguments);
+    };
+})();
@@ -0,0 +1,11 @@
+// Escape text for safe interpolation into HTML; metadata is stored raw.
+// Loaded before the page scripts that build markup from API responses.
+
+function escapeHTML(value) {
+    return String(value ?? '')
+        .replace(/&/g, '&amp;')
+        .replace(/</g, '&lt;')
+        .replace(/>/g, '&gt;')
+        .replace(/"/g, '&quot;')
+        .replace(/'/g, '&#39;');
+}
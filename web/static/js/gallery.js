@@ -14,6 +14,9 @@ const modalDescription = document.getElementById('modalDescription');
 const modalTags = document.getElementById('modalTags');
 const closeModal = document.getElementById('closeModal');
 
+// Placeholder for videos without a thumbnail
+const THUMBNAIL_PLACEHOLDER = 'data:image/svg+xml;base64,PHN2ZyB3aWR0aD0iMzIwIiBoZWlnaHQ9IjE4MCIgdmlld0JveD0iMCAwIDMyMCAxODAiIGZpbGw9Im5vbmUiIHhtbG5zPSJodHRwOi8vd3d3LnczLm9yZy8yMDAwL3N2ZyI+CjxyZWN0IHdpZHRoPSIzMjAiIGhlaWdodD0iMTgwIiBmaWxsPSIjRjNGNEY2Ii8+CjxwYXRoIGQ9Ik0xNjAgOTBDMTQzLjQzMSA5MCAxMzAgMTAzLjQzMSAxMzAgMTIwQzEzMCAxMzYuNTY5IDE0My40MzEgMTUwIDE2MCAxNTBDMTc2LjU2OSAxNTAgMTkwIDEzNi41NjkgMTkwIDEyMEMxOTAgMTAzLjQzMSAxNzYuNTY5IDkwIDE2MCA5MFoiIGZpbGw9IiM5Q0EzQUYiLz4KPHBhdGggZD0iTTE2MCAxMzBDMTU1LjU4MiAxMzAgMTUyIDEyNi40MTggMTUyIDEyMkMxNTIgMTE3LjU4MiAxNTUuNTgyIDExNCAxNjAgMTE0QzE2NC40MTggMTE0IDE2OCAxMTcuNTgyIDE2OCAxMjJDMTY4IDEyNi40MTggMTY0LjQxOCAxMzAgMTYwIDEzMFoiIGZpbGw9IndoaXRlIi8+Cjwvc3ZnPgo=';
+
 // State
 let currentPage = 1;
 let isLoading = false;
@@ -67,10 +70,9 @@ function createVideoCard(video) {
 
     card.innerHTML = `
         <div class="relative aspect-w-16 aspect-h-9 cursor-pointer overflow-hidden">
-            <img src="/api/thumbnails?id=${video.id}"
-                 alt="${video.title}"
-                 class="w-full h-full object-cover group-hover:scale-110 transition-transform duration-500"
-                 onerror="this.onerror=null; this.src='data:image/svg+xml;base64,PHN2ZyB3aWR0aD0iMzIwIiBoZWlnaHQ9IjE4MCIgdmlld0JveD0iMCAwIDMyMCAxODAiIGZpbGw9Im5vbmUiIHhtbG5zPSJodHRwOi8vd3d3LnczLm9yZy8yMDAwL3N2ZyI+CjxyZWN0IHdpZHRoPSIzMjAiIGhlaWdodD0iMTgwIiBmaWxsPSIjRjNGNEY2Ii8+CjxwYXRoIGQ9Ik0xNjAgOTBDMTQzLjQzMSA5MCAxMzAgMTAzLjQzMSAxMzAgMTIwQzEzMCAxMzYuNTY5IDE0My40MzEgMTUwIDE2MCAxNTBDMTc2LjU2OSAxNTAgMTkwIDEzNi41NjkgMTkwIDEyMEMxOTAgMTAzLjQzMSAxNzYuNTY5IDkwIDE2MCA5MFoiIGZpbGw9IiM5Q0EzQUYiLz4KPHBhdGggZD0iTTE2MCAxMzBDMTU1LjU4MiAxMzAgMTUyIDEyNi40MTggMTUyIDEyMkMxNTIgMTE3LjU4MiAxNTUuNTgyIDExNCAxNjAgMTE0QzE2NC40MTggMTE0IDE2OCAxMTcuNTgyIDE2OCAxMjJDMTY4IDEyNi40MTggMTY0LjQxOCAxMzAgMTYwIDEzMFoiIGZpbGw9IndoaXRlIi8+Cjwvc3ZnPgo='; this.alt='Video thumbnail not available';">
+            <img src="/api/thumbnails?id=${encodeURIComponent(video.id)}"
+                 alt="${escapeHTML(video.title)}"
+                 class="w-full h-full object-cover group-hover:scale-110 transition-transform duration-500">
 
             <!-- Play button overlay -->
             <div class="absolute inset-0 bg-black/20 group-hover:bg-black/30 transition-colors duration-300 flex items-center justify-center">
@@ -91,16 +93,16 @@ function createVideoCard(video) {
 
         <div class="p-6">
             <h3 class="font-bold text-gray-900 text-lg mb-2 line-clamp-2 group-hover:text-indigo-600 transition-colors duration-200">
-                ${video.title}
+                ${escapeHTML(video.title)}
             </h3>
             <p class="text-gray-600 text-sm mb-4 line-clamp-2 leading-relaxed">
-                ${video.description}
+                ${escapeHTML(video.description)}
             </p>
 
             <div class="flex flex-wrap gap-2 mb-4">
                 ${video.tags.map(tag => `
                     <span class="inline-flex items-center px-3 py-1 rounded-full text-xs font-medium bg-indigo-100 text-indigo-800 group-hover:bg-indigo-200 transition-colors duration-200">
-                        ${tag}
+                        ${escapeHTML(tag)}
                     </span>
                 `).join('')}
             </div>
@@ -119,7 +121,7 @@ function createVideoCard(video) {
                         </svg>
                         Video
                     </span>
-                    <button onclick="deleteVideo('${video.id}', '${video.title.replace(/'/g, "\\'")}')"
+                    <button type="button" data-action="delete"
                             class="flex items-center text-red-500 hover:text-red-700 hover:bg-red-50 p-1 rounded transition-colors duration-200"
                             title="Delete video">
                         <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
@@ -131,6 +133,18 @@ function createVideoCard(video) {
         </div>
     `;
 
+    // Show a placeholder when the thumbnail is missing; inline handlers are blocked by the CSP
+    const thumbnail = card.querySelector('img');
+    thumbnail.addEventListener('error', () => {
+        thumbnail.src = THUMBNAIL_PLACEHOLDER;
+        thumbnail.alt = 'Video thumbnail not available';
+    }, { once: true });
+
+    // Add click handler to delete the video
+    card.querySelector('[data-action="delete"]').addEventListener('click', () => {
+        deleteVideo(video.id, video.title);
+    });
+
     // Add click handler to open modal
     card.querySelector('.aspect-w-16').addEventListener('click', () => {
         openVideoModal(video);
@@ -166,12 +180,24 @@ function formatDate(dateString) {
 // Open video modal
 function openVideoModal(video) {
     modalTitle.textContent = video.title;
-    modalVideo.src = `/api/videos/${video.id}`;
+    modalVideo.src = `/api/videos/${encodeURIComponent(video.id)}`;
+
+    // Attach caption tracks served alongside the video
+    modalVideo.querySelectorAll('track').forEach(track => track.remove());
+    (video.captions || []).forEach((caption, index) => {
+        const track = document.createElement('track');
+        track.kind = 'captions';
+        track.srclang = caption.language;
+        track.label = caption.label || caption.language;
+        track.src = `/api/videos/${encodeURIComponent(video.id)}/captions/${encodeURIComponent(caption.language)}`;
+        track.default = index === 0;
+        modalVideo.appendChild(track);
+    });
     modalDescription.textContent = video.description;
 
     modalTags.innerHTML = video.tags.map(tag => `
         <span class="inline-flex items-center px-3 py-1 rounded-full text-sm font-medium bg-indigo-100 text-indigo-800">
-            ${tag}
+            ${escapeHTML(tag)}
         </span>
     `).join('');
 
@@ -250,7 +276,7 @@ async function deleteVideo(videoId, videoTitle) {
     }
 
     try {
-        const response = await fetch(`/api/videos/${videoId}`, {
+        const response = await fetch(`/api/videos/${encodeURIComponent(videoId)}`, {
             method: 'DELETE',
             headers: {
                 'Content-Type': 'application/json',
@@ -265,7 +291,7 @@ async function deleteVideo(videoId, videoTitle) {
         console.log('Video deleted:', result);
 
         // Remove the video card from the UI
-        const videoCard = document.querySelector(`[data-video-id="${videoId}"]`);
+        const videoCard = document.querySelector(`[data-video-id="${CSS.escape(videoId)}"]`);
         if (videoCard) {
             videoCard.remove();
         } else {
@@ -311,5 +337,54 @@ function showNotification(message, type = 'info') {
     }, 3000);
 }
 
+// Load collections
+async function loadCollections() {
+    const collectionSection = document.getElementById('collectionSection');
+    const collectionGrid = document.getElementById('collectionGrid');
+    if (!collectionSection || !collectionGrid) return;
+
+    try {
+        const response = await fetch('/api/collections');
+        if (!response.ok) {
+            throw new Error('Failed to load collections');
+        }
+
+        const collections = await response.json();
+        collectionGrid.innerHTML = '';
+        collections.forEach(collection => {
+            const card = document.createElement('a');
+            card.href = `/gallery/collections/${encodeURIComponent(collection.id)}`;
+            card.className = 'group block bg-white rounded-2xl shadow-lg hover:shadow-2xl transform hover:-translate-y-1 transition-all duration-300 overflow-hidden border border-gray-100';
+
+            if (collection.cover_image) {
+                const cover = document.createElement('img');
+                cover.src = `/api/thumbnails?id=${encodeURIComponent(collection.cover_image)}`;
+                cover.alt = '';
+                cover.className = 'w-full h-40 object-cover';
+                card.appendChild(cover);
+            }
+
+            const body = document.createElement('div');
+            body.className = 'p-4';
+            const title = document.createElement('h3');
+            title.className = 'font-bold text-gray-900 group-hover:text-indigo-600';
+            title.textContent = collection.title;
+            const count = document.createElement('p');
+            count.className = 'text-sm text-gray-500';
+            count.textContent = `${collection.video_ids.length} videos`;
+            body.appendChild(title);
+            body.appendChild(count);
+            card.appendChild(body);
+
+            collectionGrid.appendChild(card);
+        });
+
+        collectionSection.classList.toggle('hidden', collections.length === 0);
+    } catch (err) {
+        console.error('Error loading collections:', err);
+    }
+}
+
 // Initialize
+loadCollections();
 loadVideos(1);
@@ -125,6 +125,8 @@ uploadForm.addEventListener('submit', async (e) => {
     formData.append('title', document.getElementById('title').value);
     formData.append('description', document.getElementById('description').value);
     formData.append('tags', document.getElementById('tags').value);
+    appendConsent(formData);
+    appendAccess(formData);
 
     try {
         const response = await fetch('/api/videos', {
@@ -137,7 +139,7 @@ uploadForm.addEventListener('submit', async (e) => {
         }
 
         const result = await response.json();
-        alert('Video uploaded successfully!');
+        alert('Video uploaded successfully! It will appear in the gallery once a moderator has approved it.');
 
         // Reset form and recording
         uploadForm.reset();
@@ -169,14 +171,14 @@ async function loadRecordings() {
             videoCard.className = 'bg-gray-100 rounded-lg overflow-hidden';
             videoCard.innerHTML = `
                 <div class="aspect-w-16 aspect-h-9">
-                    <img src="/api/thumbnails?id=${video.id}" alt="${video.title}" class="w-full h-full object-cover">
+                    <img src="/api/thumbnails?id=${encodeURIComponent(video.id)}" alt="${escapeHTML(video.title)}" class="w-full h-full object-cover">
                 </div>
                 <div class="p-4">
-                    <h3 class="font-semibold text-gray-800">${video.title}</h3>
-                    <p class="text-sm text-gray-600">${video.description}</p>
+                    <h3 class="font-semibold text-gray-800">${escapeHTML(video.title)}</h3>
+                    <p class="text-sm text-gray-600">${escapeHTML(video.description)}</p>
                     <div class="mt-2 flex flex-wrap gap-2">
                         ${video.tags.map(tag => `
-                            <span class="text-xs bg-blue-100 text-blue-800 px-2 py-1 rounded">${tag}</span>
+                            <span class="text-xs bg-blue-100 text-blue-800 px-2 py-1 rounded">${escapeHTML(tag)}</span>
                         `).join('')}
                     </div>
                 </div>
@@ -0,0 +1,105 @@
+// Moderation queue: approve, reject or request changes on new recordings
+
+document.addEventListener('DOMContentLoaded', function () {
+    const stateSelect = document.getElementById('reviewState');
+    const reviewerName = document.getElementById('reviewerName');
+    const queue = document.getElementById('reviewQueue');
+    const message = document.getElementById('reviewMessage');
+
+    reviewerName.value = localStorage.getItem('reviewerName') || '';
+    reviewerName.addEventListener('change', function () {
+        localStorage.setItem('reviewerName', reviewerName.value.trim());
+    });
+
+    stateSelect.addEventListener('change', loadQueue);
+    queue.addEventListener('click', function (e) {
+        const button = e.target.closest('button[data-action]');
+        if (button) {
+            submitDecision(button.dataset.id, button.dataset.action);
+        }
+    });
+
+    loadQueue();
+
+    function showMessage(text) {
+        message.textContent = text;
+        message.classList.toggle('hidden', !text);
+    }
+
+    async function loadQueue() {
+        showMessage('');
+        queue.innerHTML = '';
+        try {
+            const response = await fetch('/api/review?state=' + encodeURIComponent(stateSelect.value));
+            if (response.status === 403) {
+                showMessage('Only moderators can review recordings.');
+                return;
+            }
+            if (!response.ok) {
+                throw new Error('Failed to load review queue');
+            }
+            const videos = await response.json();
+            if (videos.length === 0) {
+                showMessage('Nothing here right now.');
+                return;
+            }
+            queue.innerHTML = videos.map(renderVideo).join('');
+        } catch (err) {
+            console.error('Error loading review queue:', err);
+            showMessage('Could not load the review queue. Please try again.');
+        }
+    }
+
+    function renderVideo(video) {
+        const id = encodeURIComponent(video.id);
+        const review = video.review || {};
+        const consent = video.consent || {};
+        const history = (review.history || []).map(function (entry) {
+            return `<li>${escapeHTML(new Date(entry.at).toLocaleString())}: ${escapeHTML(entry.to)} by ${escapeHTML(entry.reviewer)}${entry.reason ? ' – ' + escapeHTML(entry.reason) : ''}</li>`;
+        }).join('');
+
+        return `
+            <div class="bg-white rounded-2xl shadow-lg border border-gray-100 p-6">
+                <div class="grid grid-cols-1 md:grid-cols-2 gap-6">
+                    <video controls preload="metadata" class="w-full rounded-xl bg-black" src="/api/videos/${id}"></video>
+                    <div class="space-y-3">
+                        <h3 class="text-xl font-semibold text-gray-900">${escapeHTML(video.title || 'Untitled')}</h3>
+                        <p class="text-gray-600">${escapeHTML(video.description)}</p>
+                        <p class="text-sm text-gray-500">Consent: ${escapeHTML(consent.scope || 'none recorded')}${consent.speaker ? ' · ' + escapeHTML(consent.speaker) : ''}</p>
+                        <textarea data-reason="${escapeHTML(video.id)}" rows="2" placeholder="Reason (required to reject or request changes)"
+                            class="w-full px-3 py-2 border border-gray-200 rounded-xl focus:outline-none focus:ring-2 focus:ring-emerald-500 text-gray-900"></textarea>
+                        <div class="flex flex-wrap gap-2">
+                            <button data-action="approve" data-id="${escapeHTML(video.id)}" class="px-4 py-2 bg-emerald-600 text-white rounded-lg hover:bg-emerald-700">Approve</button>
+                            <button data-action="request_changes" data-id="${escapeHTML(video.id)}" class="px-4 py-2 bg-amber-500 text-white rounded-lg hover:bg-amber-600">Request changes</button>
+                            <button data-action="reject" data-id="${escapeHTML(video.id)}" class="px-4 py-2 bg-red-600 text-white rounded-lg hover:bg-red-700">Reject</button>
+                        </div>
+                        <ul class="text-xs text-gray-500 space-y-1">${history}</ul>
+                    </div>
+                </div>
+            </div>
+        `;
+    }
+
+    async function submitDecision(id, action) {
+        const reason = Array.from(queue.querySelectorAll('textarea[data-reason]'))
+            .find(function (field) { return field.dataset.reason === id; });
+        try {
+            const response = await fetch('/api/videos/' + encodeURIComponent(id) + '/review', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    action: action,
+                    reason: reason ? reason.value : '',
+                    reviewer: reviewerName.value.trim()
+                })
+            });
+            if (!response.ok) {
+                throw new Error(await response.text());
+            }
+            loadQueue();
+        } catch (err) {
+            console.error('Error submitting review:', err);
+            alert('Could not save the decision. A reason and your name are required to reject or request changes.');
+        }
+    }
+});
@@ -137,7 +137,19 @@ document.addEventListener('DOMContentLoaded', function () {
         formData.append('category', document.getElementById('category').value);
         formData.append('tags', document.getElementById('tags').value);
         formData.append('language', document.getElementById('language').value);
-        formData.append('public', document.getElementById('public').checked);
+
+        // Optional translation, sent as language-tagged fields such as title[en]
+        const translationLanguage = document.getElementById('translationLanguage').value;
+        const translatedTitle = document.getElementById('translatedTitle').value.trim();
+        const translatedDescription = document.getElementById('translatedDescription').value.trim();
+        if (translatedTitle) {
+            formData.append(`title[${translationLanguage}]`, translatedTitle);
+        }
+        if (translatedDescription) {
+            formData.append(`description[${translationLanguage}]`, translatedDescription);
+        }
+        appendConsent(formData);
+        appendAccess(formData);
 
         // Show progress bar
         uploadProgress.classList.remove('hidden');